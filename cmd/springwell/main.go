@@ -26,6 +26,10 @@ func main() {
 			commands.TestCommand(),
 			commands.DoctorCommand(),
 			commands.GenerateCommand(),
+			commands.WorkflowCommand(),
+			commands.ApiCommand(),
+			commands.MockCommand(),
+			commands.AuthCommand(),
 			commands.InteractiveCommand(),
 		},
 		Flags: []cli.Flag{
@@ -47,10 +51,18 @@ func main() {
 			},
 		},
 		ExitErrHandler: func(c *cli.Context, err error) {
-			if err != nil {
+			if err == nil {
+				return
+			}
+			// Commands that already reported on their own exit with an empty message
+			if err.Error() != "" {
 				util.PrintError(err.Error())
-				os.Exit(1)
 			}
+			code := 1
+			if exitErr, ok := err.(cli.ExitCoder); ok && exitErr.ExitCode() != 0 {
+				code = exitErr.ExitCode()
+			}
+			os.Exit(code)
 		},
 		EnableBashCompletion: true,
 	}
@@ -0,0 +1,296 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/springwell/cli/pkg/config"
+	"github.com/springwell/cli/pkg/generator"
+	"github.com/springwell/cli/pkg/openapi"
+	"github.com/springwell/cli/pkg/util"
+	"github.com/urfave/cli/v2"
+)
+
+// ApiCommand returns the command to work with the project's API specifications
+func ApiCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "api",
+		Usage: "Work with the project's API specifications",
+		Subcommands: []*cli.Command{
+			ApiGenerateCommand(),
+			ApiExportCommand(),
+			ApiCollectionCommand(),
+			ApiDiffCommand(),
+			ApiAsyncAPICommand(),
+		},
+	}
+}
+
+// ApiGenerateCommand returns the command to generate controller interfaces and models
+// from the project's OpenAPI specification
+func ApiGenerateCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "generate",
+		Usage: "Generate controller interfaces, records and enums from the OpenAPI specification",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "spec",
+				Usage: "Path of the OpenAPI document (default: inputSpec of openapi-generator.yaml, or src/main/resources/openapi/api.yaml)",
+			},
+			&cli.StringFlag{
+				Name:  "api-package",
+				Usage: "Package of the controller interfaces (default: apiPackage of openapi-generator.yaml, or <package>.controller.api)",
+			},
+			&cli.StringFlag{
+				Name:  "model-package",
+				Usage: "Package of the records and enums (default: modelPackage of openapi-generator.yaml, or <package>.domain.dto)",
+			},
+			&cli.BoolFlag{
+				Name:  "reactive",
+				Usage: "Return Mono from the operations (default: reactive of openapi-generator.yaml)",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			// Check if the current directory is a Spring Boot project
+			if !util.IsSpringBootProject(".") {
+				return errors.New("current directory is not a Spring Boot project")
+			}
+
+			// Load config
+			cfg, err := config.LoadConfig(".")
+			if err != nil {
+				return err
+			}
+
+			gen := generator.NewApiGenerator(cfg, ".")
+			opts, err := gen.DefaultOptions()
+			if err != nil {
+				return err
+			}
+			if c.IsSet("spec") {
+				opts.Spec = c.String("spec")
+			}
+			if c.IsSet("api-package") {
+				opts.ApiPackage = c.String("api-package")
+			}
+			if c.IsSet("model-package") {
+				opts.ModelPackage = c.String("model-package")
+			}
+			if c.IsSet("reactive") {
+				opts.Reactive = c.Bool("reactive")
+			}
+
+			interfaces, models, err := gen.GenerateAPI(opts)
+			if err != nil {
+				return err
+			}
+
+			util.PrintSuccess("Successfully generated %d interface(s) and %d model(s) from %s", interfaces, models, opts.Spec)
+			return nil
+		},
+	}
+}
+
+// ApiExportCommand returns the command to describe the project's REST controllers in its
+// OpenAPI specification
+func ApiExportCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "export",
+		Usage: "Describe the REST controllers, their models and errors in the OpenAPI specification",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Usage:   "Path of the OpenAPI document to merge the description into",
+				Value:   "src/main/resources/openapi/api.yaml",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			// Check if the current directory is a Spring Boot project
+			if !util.IsSpringBootProject(".") {
+				return errors.New("current directory is not a Spring Boot project")
+			}
+
+			// Load config
+			cfg, err := config.LoadConfig(".")
+			if err != nil {
+				return err
+			}
+
+			gen := generator.NewApiGenerator(cfg, ".")
+			count, err := gen.ExportAPI(c.String("output"))
+			if err != nil {
+				return err
+			}
+
+			util.PrintSuccess("Successfully exported %d operation(s) to %s", count, c.String("output"))
+			return nil
+		},
+	}
+}
+
+// ApiCollectionCommand returns the command to export the project's endpoints as a request
+// collection for HTTP clients
+func ApiCollectionCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "collection",
+		Usage: "Export the endpoints as a Postman or Bruno collection, or an HTTP client file",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "format",
+				Usage: "Format of the collection (postman, bruno, http)",
+				Value: "postman",
+			},
+			&cli.StringFlag{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Usage:   "Path of the collection, a directory for bruno (default: http/<project>.postman_collection.json, http/bruno or http/api.http)",
+			},
+			&cli.StringFlag{
+				Name:  "spec",
+				Usage: "OpenAPI document to export instead of the REST controllers",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			// Check if the current directory is a Spring Boot project
+			if !util.IsSpringBootProject(".") {
+				return errors.New("current directory is not a Spring Boot project")
+			}
+
+			// Load config
+			cfg, err := config.LoadConfig(".")
+			if err != nil {
+				return err
+			}
+
+			gen := generator.NewCollectionGenerator(cfg, ".")
+			output, count, err := gen.GenerateCollection(generator.CollectionOptions{
+				Format: c.String("format"),
+				Output: c.String("output"),
+				Spec:   c.String("spec"),
+			})
+			if err != nil {
+				return err
+			}
+
+			util.PrintSuccess("Successfully exported %d request(s) to %s", count, output)
+			return nil
+		},
+	}
+}
+
+// ApiDiffCommand returns the command to report the changes between two versions of an
+// OpenAPI specification, failing on breaking changes
+func ApiDiffCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "diff",
+		Usage:     "Report the changes between two OpenAPI documents and fail on breaking changes",
+		ArgsUsage: "<old> <new> | --against <ref> [spec]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "against",
+				Usage: "Git commit, branch or tag to compare the specification with",
+			},
+			&cli.StringFlag{
+				Name:  "format",
+				Usage: "Output format (text, json, markdown)",
+				Value: "text",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			var oldDoc, newDoc *openapi.Document
+			var err error
+			if ref := c.String("against"); ref != "" {
+				if c.NArg() > 1 {
+					return errors.New("--against compares a single specification with its version at the ref")
+				}
+				spec := c.Args().First()
+				if spec == "" {
+					spec = "src/main/resources/openapi/api.yaml"
+				}
+				content, found, err := util.GitShow(".", ref, spec)
+				if err != nil {
+					return err
+				}
+				if !found {
+					util.PrintInfo("%s is not in %s, there is no previous version to compare with", spec, ref)
+					return nil
+				}
+				if oldDoc, err = openapi.Parse([]byte(content)); err != nil {
+					return fmt.Errorf("invalid OpenAPI document %s at %s: %v", spec, ref, err)
+				}
+				if newDoc, err = openapi.Load(spec); err != nil {
+					return err
+				}
+			} else {
+				if c.NArg() != 2 {
+					return errors.New("the old and new OpenAPI documents are required, or --against <ref>")
+				}
+				if oldDoc, err = openapi.Load(c.Args().Get(0)); err != nil {
+					return err
+				}
+				if newDoc, err = openapi.Load(c.Args().Get(1)); err != nil {
+					return err
+				}
+			}
+
+			changes := openapi.Diff(oldDoc, newDoc)
+			report, err := openapi.FormatChanges(changes, c.String("format"))
+			if err != nil {
+				return err
+			}
+			fmt.Fprint(os.Stdout, report)
+
+			if breaking := openapi.Breaking(changes); breaking > 0 {
+				// Keep stdout to the report, so JSON and Markdown output stay parseable
+				fmt.Fprintf(os.Stderr, "found %d breaking change(s)\n", breaking)
+				return cli.Exit("", 1)
+			}
+			return nil
+		},
+	}
+}
+
+// ApiAsyncAPICommand returns the command to generate an AsyncAPI document from the
+// project's consumers and producers
+func ApiAsyncAPICommand() *cli.Command {
+	return &cli.Command{
+		Name:  "asyncapi",
+		Usage: "Generate an AsyncAPI document from the consumers and producers in messaging/",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Usage:   "Path of the AsyncAPI document",
+				Value:   "src/main/resources/openapi/asyncapi.yaml",
+			},
+			&cli.StringFlag{
+				Name:  "spec-version",
+				Usage: "AsyncAPI version (3.0.0, 2.6.0)",
+				Value: "3.0.0",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			// Check if the current directory is a Spring Boot project
+			if !util.IsSpringBootProject(".") {
+				return errors.New("current directory is not a Spring Boot project")
+			}
+
+			// Load config
+			cfg, err := config.LoadConfig(".")
+			if err != nil {
+				return err
+			}
+
+			gen := generator.NewAsyncAPIGenerator(cfg, ".")
+			count, err := gen.GenerateAsyncAPI(c.String("output"), c.String("spec-version"))
+			if err != nil {
+				return err
+			}
+
+			util.PrintSuccess("Successfully generated %s describing %d consumer(s) and producer(s)", c.String("output"), count)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,101 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/springwell/cli/pkg/generator"
+	"github.com/springwell/cli/pkg/util"
+	"github.com/urfave/cli/v2"
+)
+
+// AuthCommand returns the command to work with the project's local identity provider
+func AuthCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "auth",
+		Usage: "Work with the project's local identity provider",
+		Subcommands: []*cli.Command{
+			AuthTokenCommand(),
+		},
+	}
+}
+
+// AuthTokenCommand returns the command to get a dev token for a test user of the dev realm
+func AuthTokenCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "token",
+		Usage: "Print an access token for a test user from the local Keycloak",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "user",
+				Aliases: []string{"u"},
+				Usage:   "Test user of the dev realm",
+				Value:   "alice",
+			},
+			&cli.StringFlag{
+				Name:  "password",
+				Usage: "Password of the user (default: the password in " + generator.KeycloakRealmFile + ")",
+			},
+			&cli.StringFlag{
+				Name:  "url",
+				Usage: "Base URL of Keycloak",
+				Value: generator.KeycloakURL,
+			},
+			&cli.BoolFlag{
+				Name:  "id-token",
+				Usage: "Print the ID token instead of the access token",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			// Check if the current directory is a Spring Boot project
+			if !util.IsSpringBootProject(".") {
+				return errors.New("current directory is not a Spring Boot project")
+			}
+
+			login, err := generator.NewKeycloakLogin(".", c.String("url"), c.String("user"), c.String("password"))
+			if err != nil {
+				return err
+			}
+
+			form := url.Values{
+				"grant_type": {"password"},
+				"client_id":  {login.ClientID},
+				"username":   {login.Username},
+				"password":   {login.Password},
+				"scope":      {"openid"},
+			}
+			client := &http.Client{Timeout: 10 * time.Second}
+			resp, err := client.PostForm(login.TokenURL, form)
+			if err != nil {
+				return fmt.Errorf("failed to reach Keycloak at %s, is it running? Start it with 'docker compose up -d keycloak': %w", c.String("url"), err)
+			}
+			defer resp.Body.Close()
+
+			var body struct {
+				AccessToken      string `json:"access_token"`
+				IDToken          string `json:"id_token"`
+				Error            string `json:"error"`
+				ErrorDescription string `json:"error_description"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				return fmt.Errorf("unexpected response from %s: %s", login.TokenURL, resp.Status)
+			}
+			if resp.StatusCode != http.StatusOK {
+				return fmt.Errorf("token request for %s refused by Keycloak: %s %s", login.Username, body.Error, body.ErrorDescription)
+			}
+
+			// Only the token goes to stdout, so it can be captured with $(springwell auth token)
+			token := body.AccessToken
+			if c.Bool("id-token") {
+				token = body.IDToken
+			}
+			fmt.Fprintln(os.Stdout, token)
+			return nil
+		},
+	}
+}
@@ -2,6 +2,9 @@ package commands
 
 import (
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/springwell/cli/pkg/config"
 	"github.com/springwell/cli/pkg/generator"
@@ -42,7 +45,7 @@ func GenerateEntityCommand() *cli.Command {
 			},
 			&cli.BoolFlag{
 				Name:  "dto",
-				Usage: "Generate DTO classes",
+				Usage: "Generate request/response DTO records",
 				Value: true,
 			},
 			&cli.BoolFlag{
@@ -60,6 +63,25 @@ func GenerateEntityCommand() *cli.Command {
 				Usage: "Skip controller generation",
 				Value: false,
 			},
+			&cli.BoolFlag{
+				Name:  "no-tests",
+				Usage: "Skip test generation",
+				Value: false,
+			},
+			&cli.BoolFlag{
+				Name:  "paginate",
+				Usage: "Page and sort the list endpoint",
+				Value: false,
+			},
+			&cli.BoolFlag{
+				Name:  "filter",
+				Usage: "Filter the list endpoint by the declared fields",
+				Value: false,
+			},
+			&cli.StringFlag{
+				Name:  "access",
+				Usage: "Roles allowed per operation (format: \"read:USER,write:ADMIN\", operations read, write, create, update, delete, roles joined with |)",
+			},
 		},
 		Action: func(c *cli.Context) error {
 			entityName := c.Args().First()
@@ -82,18 +104,21 @@ func GenerateEntityCommand() *cli.Command {
 			gen := generator.NewEntityGenerator(cfg, ".")
 
 			// Generate entity
-			err = gen.GenerateEntity(
-				entityName,
-				c.String("fields"),
-				c.String("relations"),
-				c.String("table"),
-				c.Bool("audit"),
-				c.Bool("lombok"),
-				c.Bool("dto"),
-				!c.Bool("no-repository"),
-				!c.Bool("no-service"),
-				!c.Bool("no-controller"),
-			)
+			err = gen.GenerateEntity(entityName, generator.EntityOptions{
+				Fields:     c.String("fields"),
+				Relations:  c.String("relations"),
+				TableName:  c.String("table"),
+				Audit:      c.Bool("audit"),
+				Lombok:     c.Bool("lombok"),
+				Dto:        c.Bool("dto"),
+				Repository: !c.Bool("no-repository"),
+				Service:    !c.Bool("no-service"),
+				Controller: !c.Bool("no-controller"),
+				Paginate:   c.Bool("paginate"),
+				Filter:     c.Bool("filter"),
+				Tests:      !c.Bool("no-tests"),
+				Access:     c.String("access"),
+			})
 
 			if err != nil {
 				return err
@@ -105,6 +130,440 @@ func GenerateEntityCommand() *cli.Command {
 	}
 }
 
+// GenerateIntegrationTestCommand returns the command to generate an integration test for an entity
+func GenerateIntegrationTestCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "it-test",
+		Usage: "Generate a Testcontainers integration test for an entity",
+		Action: func(c *cli.Context) error {
+			entityName := c.Args().First()
+			if entityName == "" {
+				return errors.New("entity name is required")
+			}
+
+			// Check if the current directory is a Spring Boot project
+			if !util.IsSpringBootProject(".") {
+				return errors.New("current directory is not a Spring Boot project")
+			}
+
+			// Load config
+			cfg, err := config.LoadConfig(".")
+			if err != nil {
+				return err
+			}
+
+			gen := generator.NewEntityGenerator(cfg, ".")
+			if err := gen.GenerateIntegrationTest(entityName); err != nil {
+				return err
+			}
+
+			util.PrintSuccess("Successfully generated %s integration test", entityName)
+			return nil
+		},
+	}
+}
+
+// GenerateContractCommand returns the command to generate consumer-driven contracts for an entity
+func GenerateContractCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "contract",
+		Usage: "Generate contracts for an entity's REST API and the provider test verifying them",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "format",
+				Usage: "Contract format (" + strings.Join(generator.ContractFormats, ", ") + ")",
+				Value: "spring",
+			},
+			&cli.StringFlag{
+				Name:  "consumer",
+				Usage: "Name of the consumer in the pact file (default: <artifactId>-consumer)",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			entityName := c.Args().First()
+			if entityName == "" {
+				return errors.New("entity name is required")
+			}
+
+			// Check if the current directory is a Spring Boot project
+			if !util.IsSpringBootProject(".") {
+				return errors.New("current directory is not a Spring Boot project")
+			}
+
+			// Load config
+			cfg, err := config.LoadConfig(".")
+			if err != nil {
+				return err
+			}
+
+			gen := generator.NewContractGenerator(cfg, ".")
+			count, err := gen.GenerateContract(entityName, generator.ContractOptions{
+				Format:   c.String("format"),
+				Consumer: c.String("consumer"),
+			})
+			if err != nil {
+				return err
+			}
+
+			util.PrintSuccess("Successfully generated %d %s contract(s) for %s", count, c.String("format"), entityName)
+			return nil
+		},
+	}
+}
+
+// GenerateWorkflowCommand returns the command to generate a Temporal workflow
+func GenerateWorkflowCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "workflow",
+		Usage: "Generate a Temporal workflow with its activities and worker registration",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "activities",
+				Aliases: []string{"a"},
+				Usage:   "Comma-separated activities (format: \"name[:ReturnType]\", default type void)",
+			},
+			&cli.StringFlag{
+				Name:  "signals",
+				Usage: "Comma-separated signals (format: \"name[:PayloadType]\", default type String)",
+			},
+			&cli.StringFlag{
+				Name:  "queries",
+				Usage: "Comma-separated queries (format: \"name[:ReturnType]\", default type String)",
+			},
+			&cli.StringFlag{
+				Name:  "task-queue",
+				Usage: "Task queue of the worker (default: derived from name)",
+			},
+			&cli.DurationFlag{
+				Name:  "start-to-close-timeout",
+				Usage: "Maximum duration of a single activity attempt",
+				Value: 5 * time.Minute,
+			},
+			&cli.IntFlag{
+				Name:  "max-attempts",
+				Usage: "Maximum attempts of an activity, 0 for unlimited",
+				Value: 3,
+			},
+			&cli.DurationFlag{
+				Name:  "initial-interval",
+				Usage: "Delay before the first activity retry",
+				Value: time.Second,
+			},
+			&cli.DurationFlag{
+				Name:  "max-interval",
+				Usage: "Maximum delay between activity retries",
+				Value: 10 * time.Second,
+			},
+			&cli.Float64Flag{
+				Name:  "backoff",
+				Usage: "Coefficient by which the retry delay grows",
+				Value: 2.0,
+			},
+			&cli.StringFlag{
+				Name:  "do-not-retry",
+				Usage: "Comma-separated exception types that are never retried",
+			},
+			&cli.BoolFlag{
+				Name:  "saga",
+				Usage: "Compensate completed activities when a later one fails",
+			},
+			&cli.BoolFlag{
+				Name:  "parallel-compensation",
+				Usage: "Run saga compensations in parallel instead of in reverse order",
+			},
+			&cli.BoolFlag{
+				Name:  "continue-with-error",
+				Usage: "Keep running saga compensations when one of them fails",
+			},
+			&cli.BoolFlag{
+				Name:  "no-tests",
+				Usage: "Skip test generation",
+				Value: false,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			workflowName := c.Args().First()
+			if workflowName == "" {
+				return errors.New("workflow name is required")
+			}
+
+			// Check if the current directory is a Spring Boot project
+			if !util.IsSpringBootProject(".") {
+				return errors.New("current directory is not a Spring Boot project")
+			}
+
+			// Load config
+			cfg, err := config.LoadConfig(".")
+			if err != nil {
+				return err
+			}
+
+			activities, err := generator.ParseWorkflowMethods(c.String("activities"), "void")
+			if err != nil {
+				return err
+			}
+			signals, err := generator.ParseWorkflowMethods(c.String("signals"), "String")
+			if err != nil {
+				return err
+			}
+			queries, err := generator.ParseWorkflowMethods(c.String("queries"), "String")
+			if err != nil {
+				return err
+			}
+
+			var doNotRetry []string
+			for _, exception := range strings.Split(c.String("do-not-retry"), ",") {
+				if exception = strings.TrimSpace(exception); exception != "" {
+					doNotRetry = append(doNotRetry, exception)
+				}
+			}
+
+			opts := generator.WorkflowOptions{
+				Activities:           activities,
+				Signals:              signals,
+				Queries:              queries,
+				TaskQueue:            c.String("task-queue"),
+				StartToCloseTimeout:  c.Duration("start-to-close-timeout"),
+				MaximumAttempts:      c.Int("max-attempts"),
+				InitialInterval:      c.Duration("initial-interval"),
+				MaximumInterval:      c.Duration("max-interval"),
+				BackoffCoefficient:   c.Float64("backoff"),
+				DoNotRetry:           doNotRetry,
+				Saga:                 c.Bool("saga"),
+				ParallelCompensation: c.Bool("parallel-compensation"),
+				ContinueWithError:    c.Bool("continue-with-error"),
+				Tests:                !c.Bool("no-tests"),
+			}
+
+			if !opts.Saga && (opts.ParallelCompensation || opts.ContinueWithError) {
+				util.PrintWarning("--parallel-compensation and --continue-with-error only apply with --saga")
+			}
+
+			gen := generator.NewWorkflowGenerator(cfg, ".")
+			if err := gen.GenerateWorkflow(workflowName, opts); err != nil {
+				return err
+			}
+
+			util.PrintSuccess("Successfully generated %s workflow", workflowName)
+			return nil
+		},
+	}
+}
+
+// GenerateConsumerCommand returns the command to generate an SQS or Kafka consumer
+func GenerateConsumerCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "consumer",
+		Usage: "Generate an SQS or Kafka consumer with a typed payload and a processor",
+		Flags: messagingFlags(),
+		Action: func(c *cli.Context) error {
+			return generateMessaging(c, "consumer")
+		},
+	}
+}
+
+// GenerateProducerCommand returns the command to generate an SQS or Kafka producer
+func GenerateProducerCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "producer",
+		Usage: "Generate an SQS or Kafka producer with a typed payload",
+		Flags: messagingFlags(),
+		Action: func(c *cli.Context) error {
+			return generateMessaging(c, "producer")
+		},
+	}
+}
+
+// GenerateEventCommand returns the command to generate a domain event published through the outbox
+func GenerateEventCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "event",
+		Usage: "Generate a domain event published through a transactional outbox",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "entity",
+				Usage:    "Entity the event is about",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:  "producer",
+				Usage: "Producer the outbox relay publishes the event with (default: the producer of the payload named after the event)",
+			},
+			&cli.StringFlag{
+				Name:  "on",
+				Usage: "Service operation publishing the event: create, update or delete (default: from the event name suffix)",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			eventName := c.Args().First()
+			if eventName == "" {
+				return errors.New("event name is required")
+			}
+
+			// Check if the current directory is a Spring Boot project
+			if !util.IsSpringBootProject(".") {
+				return errors.New("current directory is not a Spring Boot project")
+			}
+
+			// Load config
+			cfg, err := config.LoadConfig(".")
+			if err != nil {
+				return err
+			}
+
+			gen := generator.NewEventGenerator(cfg, ".")
+			if err := gen.GenerateEvent(eventName, generator.EventOptions{
+				Entity:   c.String("entity"),
+				Producer: c.String("producer"),
+				On:       c.String("on"),
+			}); err != nil {
+				return err
+			}
+
+			util.PrintSuccess("Successfully generated %s event", eventName)
+			return nil
+		},
+	}
+}
+
+// GenerateClientCommand returns the command to generate a typed HTTP client of another
+// service from its OpenAPI document
+func GenerateClientCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "client",
+		Usage: "Generate an HTTP client of another service from its OpenAPI specification",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "spec",
+				Usage:    "Path of the service's OpenAPI document",
+				Required: true,
+			},
+			&cli.BoolFlag{
+				Name:  "reactive",
+				Usage: "Call the service with WebClient and return Mono",
+			},
+			&cli.StringFlag{
+				Name:  "base-url",
+				Usage: "Base URL of the service (default: the first server of the document)",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			clientName := c.Args().First()
+			if clientName == "" {
+				return errors.New("client name is required")
+			}
+
+			// Check if the current directory is a Spring Boot project
+			if !util.IsSpringBootProject(".") {
+				return errors.New("current directory is not a Spring Boot project")
+			}
+
+			// Load config
+			cfg, err := config.LoadConfig(".")
+			if err != nil {
+				return err
+			}
+
+			gen := generator.NewClientGenerator(cfg, ".")
+			count, err := gen.GenerateClient(clientName, generator.ClientOptions{
+				Spec:     c.String("spec"),
+				Reactive: c.Bool("reactive"),
+				BaseURL:  c.String("base-url"),
+			})
+			if err != nil {
+				return err
+			}
+
+			util.PrintSuccess("Successfully generated %s client with %d operation(s)", clientName, count)
+			return nil
+		},
+	}
+}
+
+// messagingFlags returns the flags shared by the consumer and producer commands
+func messagingFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "broker",
+			Usage: "Message broker (sqs, kafka)",
+			Value: "sqs",
+		},
+		&cli.StringFlag{
+			Name:     "queue",
+			Aliases:  []string{"topic"},
+			Usage:    "Name of the queue, or of the topic with --broker kafka",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:     "payload",
+			Usage:    "Class of the message payload",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:  "format",
+			Usage: "Payload format with --broker kafka (json, avro)",
+			Value: "json",
+		},
+		&cli.StringFlag{
+			Name:    "fields",
+			Aliases: []string{"f"},
+			Usage:   "Payload field definitions (format: \"name:type\"), used when the payload does not exist yet",
+		},
+		&cli.IntFlag{
+			Name:  "max-receive-count",
+			Usage: "SQS deliveries before a message is moved to the dead-letter queue",
+			Value: 5,
+		},
+		&cli.IntFlag{
+			Name:  "visibility-timeout",
+			Usage: "Seconds a received SQS message stays hidden before it is delivered again",
+			Value: 30,
+		},
+	}
+}
+
+// generateMessaging generates a consumer or producer from the command line
+func generateMessaging(c *cli.Context, kind string) error {
+	name := c.Args().First()
+	if name == "" {
+		return fmt.Errorf("%s name is required", kind)
+	}
+
+	// Check if the current directory is a Spring Boot project
+	if !util.IsSpringBootProject(".") {
+		return errors.New("current directory is not a Spring Boot project")
+	}
+
+	// Load config
+	cfg, err := config.LoadConfig(".")
+	if err != nil {
+		return err
+	}
+
+	opts := generator.MessagingOptions{
+		Broker:            c.String("broker"),
+		Queue:             c.String("queue"),
+		Payload:           c.String("payload"),
+		Fields:            c.String("fields"),
+		Format:            c.String("format"),
+		MaxReceiveCount:   c.Int("max-receive-count"),
+		VisibilityTimeout: c.Int("visibility-timeout"),
+	}
+
+	gen := generator.NewMessagingGenerator(cfg, ".")
+	if kind == "consumer" {
+		err = gen.GenerateConsumer(name, opts)
+	} else {
+		err = gen.GenerateProducer(name, opts)
+	}
+	if err != nil {
+		return err
+	}
+
+	util.PrintSuccess("Successfully generated %s %s", name, kind)
+	return nil
+}
+
 // GenerateControllerCommand returns the command to generate a controller
 func GenerateControllerCommand() *cli.Command {
 	return &cli.Command{
@@ -185,6 +644,13 @@ func GenerateCommand() *cli.Command {
 		Usage:   "Generate code components",
 		Subcommands: []*cli.Command{
 			GenerateEntityCommand(),
+			GenerateIntegrationTestCommand(),
+			GenerateContractCommand(),
+			GenerateWorkflowCommand(),
+			GenerateConsumerCommand(),
+			GenerateProducerCommand(),
+			GenerateEventCommand(),
+			GenerateClientCommand(),
 			GenerateControllerCommand(),
 			GenerateServiceCommand(),
 			GenerateRepositoryCommand(),
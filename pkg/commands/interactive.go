@@ -7,8 +7,10 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/springwell/cli/pkg/config"
+	"github.com/springwell/cli/pkg/generator"
 	"github.com/springwell/cli/pkg/util"
 	"github.com/urfave/cli/v2"
 )
@@ -187,11 +189,17 @@ func handleGenerateProject(reader *bufio.Reader) error {
 	// Create the project
 	util.PrintInfo("\nCreating project %s with template %s...", name, template)
 
+	auth := "jwt"
 	if template == "aws-temporal-auth0" {
-		return createAwsTemporalAuth0Project(name, packageName, projectDir, db)
+		if err := createAwsTemporalAuth0Project(name, packageName, projectDir, db); err != nil {
+			return err
+		}
+		auth = "auth0"
+	} else if err := createSpringBootProject(name, packageName, projectDir, db, auth, "swagger,actuator"); err != nil {
+		return err
 	}
 
-	return createSpringBootProject(name, packageName, projectDir, db, "jwt", "swagger,actuator")
+	return generator.AddSecurity(cfg, projectDir, auth)
 }
 
 // handleGenerateComponents handles the "Generate components" option
@@ -256,7 +264,7 @@ func handleGenerateComponents(reader *bufio.Reader) error {
 	case "5":
 		return generateDTO(componentName, cfg.Project.Package)
 	case "6":
-		return generateTemporalWorkflow(componentName, cfg.Project.Package)
+		return generateTemporalWorkflow(reader, cfg, componentName)
 	case "7":
 		return generateTemporalActivity(componentName, cfg.Project.Package)
 	default:
@@ -794,113 +802,35 @@ public class ${className}DTO {
 	return nil
 }
 
-// generateTemporalWorkflow creates new Temporal workflow files
-func generateTemporalWorkflow(name string, packageName string) error {
-	// Capitalize first letter for class name
-	className := util.ToCamelCase(name)
-
-	// Create workflow directories if they don't exist
-	workflowDir := filepath.Join("src/main/java", strings.ReplaceAll(packageName, ".", "/"), "temporal/workflow")
-	if err := util.CreateDirectory(workflowDir); err != nil {
-		return err
-	}
-
-	workflowImplDir := filepath.Join("src/main/java", strings.ReplaceAll(packageName, ".", "/"), "temporal/workflow/impl")
-	if err := util.CreateDirectory(workflowImplDir); err != nil {
+// generateTemporalWorkflow prompts for the activities of a workflow and generates it
+// with the default activity and retry options
+func generateTemporalWorkflow(reader *bufio.Reader, cfg *config.Config, name string) error {
+	fmt.Print("Enter activities (comma-separated, format: name[:ReturnType]): ")
+	definitions, err := reader.ReadString('\n')
+	if err != nil {
 		return err
 	}
 
-	// Workflow interface template
-	workflowTemplate := `package ${package}.temporal.workflow;
-
-import io.temporal.workflow.WorkflowInterface;
-import io.temporal.workflow.WorkflowMethod;
-
-/**
- * ${className} workflow interface for Temporal.
- */
-@WorkflowInterface
-public interface ${className}Workflow {
-
-    /**
-     * Main workflow method.
-     * 
-     * @param input The input for the workflow
-     * @return The result of the workflow execution
-     */
-    @WorkflowMethod
-    String execute(String input);
-}
-`
-
-	// Workflow implementation template
-	workflowImplTemplate := `package ${package}.temporal.workflow.impl;
-
-import ${package}.temporal.workflow.${className}Workflow;
-import ${package}.temporal.activity.${className}Activity;
-
-import io.temporal.activity.ActivityOptions;
-import io.temporal.common.RetryOptions;
-import io.temporal.workflow.Workflow;
-
-import java.time.Duration;
-
-/**
- * Implementation of the ${className}Workflow interface.
- */
-public class ${className}WorkflowImpl implements ${className}Workflow {
-
-    private final ActivityOptions activityOptions = ActivityOptions.newBuilder()
-            .setScheduleToCloseTimeout(Duration.ofSeconds(10))
-            .setRetryOptions(RetryOptions.newBuilder()
-                    .setMaximumAttempts(3)
-                    .build())
-            .build();
-
-    private final ${className}Activity activity = Workflow.newActivityStub(
-            ${className}Activity.class, activityOptions);
-
-    @Override
-    public String execute(String input) {
-        // Log the start of the workflow
-        Workflow.getLogger(${className}WorkflowImpl.class)
-                .info("${className} workflow execution started with input: " + input);
-
-        // Execute an activity as part of this workflow
-        String result = activity.execute(input);
-
-        // Log the completion of the workflow
-        Workflow.getLogger(${className}WorkflowImpl.class)
-                .info("${className} workflow execution completed with result: " + result);
-
-        return result;
-    }
-}
-`
-
-	// Replace placeholders
-	workflowTemplate = strings.ReplaceAll(workflowTemplate, "${package}", packageName)
-	workflowTemplate = strings.ReplaceAll(workflowTemplate, "${className}", className)
-
-	workflowImplTemplate = strings.ReplaceAll(workflowImplTemplate, "${package}", packageName)
-	workflowImplTemplate = strings.ReplaceAll(workflowImplTemplate, "${className}", className)
-
-	// Write to files
-	workflowFile := filepath.Join(workflowDir, className+"Workflow.java")
-	if err := util.WriteFile(workflowFile, workflowTemplate); err != nil {
+	activities, err := generator.ParseWorkflowMethods(strings.TrimSpace(definitions), "void")
+	if err != nil {
 		return err
 	}
 
-	workflowImplFile := filepath.Join(workflowImplDir, className+"WorkflowImpl.java")
-	if err := util.WriteFile(workflowImplFile, workflowImplTemplate); err != nil {
+	gen := generator.NewWorkflowGenerator(cfg, ".")
+	if err := gen.GenerateWorkflow(name, generator.WorkflowOptions{
+		Activities:          activities,
+		StartToCloseTimeout: 5 * time.Minute,
+		MaximumAttempts:     3,
+		InitialInterval:     time.Second,
+		MaximumInterval:     10 * time.Second,
+		BackoffCoefficient:  2.0,
+		Tests:               true,
+	}); err != nil {
 		return err
 	}
 
-	util.PrintSuccess("Generated workflow interface: %s", workflowFile)
-	util.PrintSuccess("Generated workflow implementation: %s", workflowImplFile)
-
-	// Also generate the corresponding activity
-	return generateTemporalActivity(name, packageName)
+	util.PrintSuccess("Successfully generated %s workflow", name)
+	return nil
 }
 
 // generateTemporalActivity creates new Temporal activity files
@@ -0,0 +1,147 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	"github.com/springwell/cli/pkg/mock"
+	"github.com/springwell/cli/pkg/openapi"
+	"github.com/springwell/cli/pkg/util"
+	"github.com/urfave/cli/v2"
+)
+
+// MockCommand returns the command to serve a mock of the project's OpenAPI specification
+func MockCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "mock",
+		Usage:     "Serve a mock server answering every operation of an OpenAPI specification",
+		ArgsUsage: "[spec]",
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:    "port",
+				Aliases: []string{"p"},
+				Usage:   "Port to listen on",
+				Value:   4010,
+			},
+			&cli.StringFlag{
+				Name:  "host",
+				Usage: "Address to listen on, 0.0.0.0 to accept other machines and containers",
+				Value: "localhost",
+			},
+			&cli.DurationFlag{
+				Name:  "latency",
+				Usage: "Delay of every response, such as 200ms",
+			},
+			&cli.DurationFlag{
+				Name:  "jitter",
+				Usage: "Upper bound of a random delay added to the latency",
+			},
+			&cli.Float64Flag{
+				Name:  "error-rate",
+				Usage: "Fraction of the requests answered with an error, from 0 to 1",
+			},
+			&cli.IntFlag{
+				Name:  "error-status",
+				Usage: "Status of the injected errors",
+				Value: http.StatusInternalServerError,
+			},
+			&cli.StringFlag{
+				Name:  "record",
+				Usage: "File the calls are appended to as JSON lines",
+			},
+			&cli.BoolFlag{
+				Name:  "no-validate",
+				Usage: "Answer requests that do not match the specification instead of rejecting them",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.NArg() > 1 {
+				return errors.New("only one OpenAPI document can be mocked")
+			}
+			spec := c.Args().First()
+			if spec == "" {
+				spec = "src/main/resources/openapi/api.yaml"
+			}
+			doc, err := openapi.Load(spec)
+			if err != nil {
+				return err
+			}
+
+			opts := mock.Options{
+				Latency:     c.Duration("latency"),
+				Jitter:      c.Duration("jitter"),
+				ErrorRate:   c.Float64("error-rate"),
+				ErrorStatus: c.Int("error-status"),
+				NoValidate:  c.Bool("no-validate"),
+				OnCall:      printCall,
+			}
+			if path := c.String("record"); path != "" {
+				record, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+				if err != nil {
+					return err
+				}
+				defer record.Close()
+				opts.Record = record
+			}
+			server, err := mock.NewServer(doc, opts)
+			if err != nil {
+				return err
+			}
+
+			address := net.JoinHostPort(c.String("host"), strconv.Itoa(c.Int("port")))
+			listener, err := net.Listen("tcp", address)
+			if err != nil {
+				return err
+			}
+			util.PrintSuccess("Mocking %s %s at http://%s%s", doc.Info.Title, doc.Info.Version, address, server.BasePath())
+			for _, operation := range server.Operations() {
+				fmt.Printf("  %-7s %s\n", operation.Method, operation.Path)
+			}
+			util.PrintInfo("Recorded calls: http://%s%s/calls", address, mock.ControlPath)
+
+			httpServer := &http.Server{Handler: server}
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			go func() {
+				<-ctx.Done()
+				httpServer.Shutdown(context.Background())
+			}()
+			if err := httpServer.Serve(listener); err != nil && err != http.ErrServerClosed {
+				return err
+			}
+			util.PrintInfo("Mock server stopped")
+			return nil
+		},
+	}
+}
+
+// printCall prints a call the mock server answered on one line
+func printCall(call mock.Call) {
+	target := call.Path
+	if call.Query != "" {
+		target += "?" + call.Query
+	}
+	line := fmt.Sprintf("%s %s → %d", call.Method, target, call.Status)
+	if call.Operation != "" {
+		line += " " + call.Operation
+	}
+	if call.Injected {
+		line += " (injected)"
+	}
+	line += fmt.Sprintf(" %dms", call.DurationMs)
+	if call.Status >= 400 {
+		util.PrintWarning("%s", line)
+		for _, violation := range call.Violations {
+			fmt.Printf("    %s %s\n", violation.Location, violation.Message)
+		}
+		return
+	}
+	util.PrintInfo("%s", line)
+}
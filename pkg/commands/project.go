@@ -6,9 +6,11 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/springwell/cli/pkg/config"
+	"github.com/springwell/cli/pkg/generator"
 	"github.com/springwell/cli/pkg/util"
 	"github.com/urfave/cli/v2"
 )
@@ -31,17 +33,17 @@ func NewProjectCommand() *cli.Command {
 			},
 			&cli.StringFlag{
 				Name:  "auth",
-				Usage: "Authentication type (jwt, oauth2, basic, auth0)",
+				Usage: "Authentication type (jwt, oauth2, basic, auth0, none)",
 				Value: "jwt",
 			},
 			&cli.StringFlag{
 				Name:  "cloud",
-				Usage: "Cloud provider integration (aws, azure, gcp)",
-				Value: "aws",
+				Usage: "Cloud provider for blob storage, queues and secrets (aws, azure, gcp, none)",
+				Value: config.CloudNone,
 			},
 			&cli.StringFlag{
 				Name:  "features",
-				Usage: "Comma-separated list of features to include",
+				Usage: "Comma-separated list of features to include (e.g. testcontainers, kafka)",
 				Value: "swagger,actuator",
 			},
 			&cli.StringFlag{
@@ -56,6 +58,23 @@ func NewProjectCommand() *cli.Command {
 				return errors.New("project name is required")
 			}
 
+			auth := c.String("auth")
+			if auth != "none" && !slices.Contains(generator.AuthModes, auth) {
+				return fmt.Errorf("unsupported auth mode %q, use %s or none", auth, strings.Join(generator.AuthModes, ", "))
+			}
+
+			cloud := c.String("cloud")
+			if cloud != config.CloudNone && !slices.Contains(config.CloudProviders, cloud) {
+				return fmt.Errorf("unsupported cloud provider %q, use %s or %s", cloud, strings.Join(config.CloudProviders, ", "), config.CloudNone)
+			}
+			// The aws-temporal-auth0 template is built on AWS
+			if c.String("template") == "aws-temporal-auth0" {
+				if c.IsSet("cloud") && cloud != config.CloudAWS {
+					return fmt.Errorf("the aws-temporal-auth0 template only supports --cloud aws")
+				}
+				cloud = config.CloudAWS
+			}
+
 			// Create project directory
 			projectDir := filepath.Join(".", projectName)
 			if err := util.CreateDirectory(projectDir); err != nil {
@@ -71,6 +90,8 @@ func NewProjectCommand() *cli.Command {
 			// Set up configuration
 			cfg := config.GetDefaultConfig()
 			cfg.Project.Package = packageName
+			cfg.Project.Database = c.String("db")
+			cfg.Cloud.Provider = cloud
 
 			// Save configuration
 			if err := config.SaveConfig(cfg, projectDir); err != nil {
@@ -81,11 +102,57 @@ func NewProjectCommand() *cli.Command {
 			template := c.String("template")
 			if template == "aws-temporal-auth0" {
 				// Create project with AWS, Temporal, and Auth0 integration
-				return createAwsTemporalAuth0Project(projectName, packageName, projectDir, c.String("db"))
+				if err := createAwsTemporalAuth0Project(projectName, packageName, projectDir, c.String("db")); err != nil {
+					return err
+				}
+			} else {
+				// Use Spring Initializr to create the base project
+				if err := createSpringBootProject(projectName, packageName, projectDir, c.String("db"), auth, c.String("features")); err != nil {
+					return err
+				}
+			}
+
+			// Configure the filter chain for the chosen authentication, the
+			// aws-temporal-auth0 template always authenticates with Auth0
+			if template == "aws-temporal-auth0" {
+				auth = "auth0"
+			}
+			if auth != "none" {
+				util.PrintInfo("Configuring %s authentication...", auth)
+				if err := generator.AddSecurity(cfg, projectDir, auth); err != nil {
+					return err
+				}
+			}
+
+			// Add blob storage, queue and secret abstractions for the cloud provider
+			if cloud != config.CloudNone {
+				util.PrintInfo("Adding %s cloud integration...", cloud)
+				if err := generator.AddCloud(cfg, projectDir, cloud); err != nil {
+					return err
+				}
 			}
 
-			// Use Spring Initializr to create the base project
-			return createSpringBootProject(projectName, packageName, projectDir, c.String("db"), c.String("auth"), c.String("features"))
+			// Wire Testcontainers for the chosen database
+			if hasFeature(c.String("features"), "testcontainers") {
+				if c.String("db") == "h2" {
+					util.PrintWarning("Skipping Testcontainers, H2 runs in memory")
+				} else {
+					util.PrintInfo("Adding Testcontainers for %s...", c.String("db"))
+					if err := generator.AddTestcontainers(cfg, projectDir, c.String("db")); err != nil {
+						return err
+					}
+				}
+			}
+
+			// Add a Kafka broker and Spring Kafka configuration
+			if hasFeature(c.String("features"), "kafka") {
+				util.PrintInfo("Adding Kafka...")
+				if err := generator.AddKafka(cfg, projectDir, false); err != nil {
+					return err
+				}
+			}
+
+			return nil
 		},
 	}
 }
@@ -249,13 +316,41 @@ func DoctorCommand() *cli.Command {
 				return errors.New("current directory is not a Spring Boot project")
 			}
 
-			// TODO: Implement project health checks
+			// Load config
+			cfg, err := config.LoadConfig(".")
+			if err != nil {
+				return err
+			}
+
+			// Workflow changes without a version gate break executions in flight
+			issues, err := generator.NewWorkflowGenerator(cfg, ".").CheckWorkflowVersions()
+			if err != nil {
+				return err
+			}
+
+			for _, issue := range issues {
+				util.PrintWarning("%s", issue)
+			}
+			if len(issues) > 0 {
+				return fmt.Errorf("found %d issue(s)", len(issues))
+			}
+
 			util.PrintSuccess("Project looks healthy!")
 			return nil
 		},
 	}
 }
 
+// hasFeature reports whether a comma-separated feature list includes a feature
+func hasFeature(features, feature string) bool {
+	for _, f := range strings.Split(features, ",") {
+		if strings.TrimSpace(f) == feature {
+			return true
+		}
+	}
+	return false
+}
+
 // createSpringBootProject creates a new Spring Boot project using Spring Initializr
 func createSpringBootProject(name, packageName, projectDir, db, auth, features string) error {
 	// Build list of dependencies
@@ -278,7 +373,7 @@ func createSpringBootProject(name, packageName, projectDir, db, auth, features s
 
 	// Add auth dependency
 	switch auth {
-	case "jwt":
+	case "jwt", "auth0":
 		dependencies = append(dependencies, "security", "oauth2-resource-server")
 	case "oauth2":
 		dependencies = append(dependencies, "security", "oauth2-client")
@@ -286,6 +381,13 @@ func createSpringBootProject(name, packageName, projectDir, db, auth, features s
 		dependencies = append(dependencies, "security")
 	}
 
+	if hasFeature(features, "actuator") {
+		dependencies = append(dependencies, "actuator")
+	}
+	if hasFeature(features, "kafka") {
+		dependencies = append(dependencies, "kafka")
+	}
+
 	// Create command
 	url := "https://start.spring.io/starter.zip"
 	url += "?name=" + name
@@ -710,6 +812,26 @@ jobs:
       
     - name: Run tests
       run: ./gradlew test
+
+  api-compatibility:
+    if: github.event_name == 'pull_request'
+    runs-on: ubuntu-latest
+
+    steps:
+    - uses: actions/checkout@v3
+      with:
+        fetch-depth: 0
+
+    - name: Set up Go
+      uses: actions/setup-go@v4
+      with:
+        go-version: '1.21'
+
+    - name: Install SpringWell
+      run: go install github.com/springwell/cli/cmd/springwell@latest
+
+    - name: Check the API for breaking changes
+      run: springwell api diff --against origin/${{ github.base_ref }} --format markdown >> $GITHUB_STEP_SUMMARY
 `
 
 	if err := util.WriteFile(filepath.Join(projectDir, ".github/workflows/ci.yml"), ciYamlContent); err != nil {
@@ -1006,7 +1128,7 @@ components:
 	}
 
 	// NEW: Create OpenAPI generator configuration
-	openApiGeneratorContent := `# Configuration for OpenAPI Generator
+	openApiGeneratorContent := `# Configuration for OpenAPI Generator, also read by springwell api generate
 generatorName: spring
 inputSpec: src/main/resources/openapi/api.yaml
 outputDir: build/generated/openapi
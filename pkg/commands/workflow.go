@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"errors"
+
+	"github.com/springwell/cli/pkg/config"
+	"github.com/springwell/cli/pkg/generator"
+	"github.com/springwell/cli/pkg/util"
+	"github.com/urfave/cli/v2"
+)
+
+// WorkflowCommand returns the command to maintain Temporal workflows
+func WorkflowCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "workflow",
+		Usage: "Maintain Temporal workflows",
+		Subcommands: []*cli.Command{
+			WorkflowVersionCommand(),
+		},
+	}
+}
+
+// WorkflowVersionCommand returns the command to gate workflow changes with Workflow.getVersion
+func WorkflowVersionCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "version",
+		Usage: "Wrap the changes to a workflow since the last commit in Workflow.getVersion",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "change-id",
+				Usage:    "Change ID passed to Workflow.getVersion",
+				Required: true,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			workflowName := c.Args().First()
+			if workflowName == "" {
+				return errors.New("workflow name is required")
+			}
+
+			// Check if the current directory is a Spring Boot project
+			if !util.IsSpringBootProject(".") {
+				return errors.New("current directory is not a Spring Boot project")
+			}
+
+			// Load config
+			cfg, err := config.LoadConfig(".")
+			if err != nil {
+				return err
+			}
+
+			gen := generator.NewWorkflowGenerator(cfg, ".")
+			if err := gen.VersionWorkflow(workflowName, c.String("change-id")); err != nil {
+				return err
+			}
+
+			util.PrintSuccess("Successfully versioned %s workflow", workflowName)
+			return nil
+		},
+	}
+}
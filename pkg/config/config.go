@@ -1,6 +1,7 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 
@@ -12,6 +13,7 @@ type Config struct {
 	Project struct {
 		Package           string `mapstructure:"package"`
 		DefaultsDirectory string `mapstructure:"defaultsDirectory"`
+		Database          string `mapstructure:"database"`
 	} `mapstructure:"project"`
 
 	Code struct {
@@ -27,14 +29,46 @@ type Config struct {
 		Directory string `mapstructure:"directory"`
 	} `mapstructure:"templates"`
 
+	Cloud struct {
+		Provider string `mapstructure:"provider"`
+	} `mapstructure:"cloud"`
+
 	AWS struct {
 		Region          string   `mapstructure:"region"`
 		DefaultServices []string `mapstructure:"defaultServices"`
 	} `mapstructure:"aws"`
 
+	Errors struct {
+		Style string `mapstructure:"style"`
+	} `mapstructure:"errors"`
+
 	Plugins []string `mapstructure:"plugins"`
 }
 
+// Styles of the errors returned by generated controllers
+const (
+	// ErrorStyleResponseStatus throws ResponseStatusException from controllers
+	ErrorStyleResponseStatus = "response-status"
+	// ErrorStyleProblemDetails throws typed domain exceptions answered as RFC 9457
+	// problem details by a global exception handler
+	ErrorStyleProblemDetails = "problem-details"
+)
+
+// Cloud providers whose blob storage, queues and secrets generated code uses
+const (
+	// CloudAWS uses S3, SQS and Secrets Manager
+	CloudAWS = "aws"
+	// CloudAzure uses Blob Storage, Service Bus and Key Vault
+	CloudAzure = "azure"
+	// CloudGCP uses Cloud Storage, Pub/Sub and Secret Manager
+	CloudGCP = "gcp"
+	// CloudNone generates no cloud integration
+	CloudNone = "none"
+)
+
+// CloudProviders lists the supported cloud providers
+var CloudProviders = []string{CloudAWS, CloudAzure, CloudGCP}
+
 // LoadConfig loads the configuration from the .springwell.yml file
 func LoadConfig(projectDir string) (*Config, error) {
 	v := viper.New()
@@ -49,8 +83,10 @@ func LoadConfig(projectDir string) (*Config, error) {
 	v.SetDefault("code.lombok", true)
 	v.SetDefault("code.standardizeFields", true)
 	v.SetDefault("templates.directory", ".springwell/templates")
+	v.SetDefault("cloud.provider", CloudNone)
 	v.SetDefault("aws.region", "us-east-1")
 	v.SetDefault("aws.defaultServices", []string{"s3", "secretsManager"})
+	v.SetDefault("errors.style", ErrorStyleResponseStatus)
 
 	// Try to read the config file
 	if err := v.ReadInConfig(); err != nil {
@@ -65,6 +101,18 @@ func LoadConfig(projectDir string) (*Config, error) {
 		return nil, err
 	}
 
+	switch config.Errors.Style {
+	case ErrorStyleResponseStatus, ErrorStyleProblemDetails:
+	default:
+		return nil, fmt.Errorf("unsupported errors.style %q in .springwell.yml, use %s or %s", config.Errors.Style, ErrorStyleResponseStatus, ErrorStyleProblemDetails)
+	}
+
+	switch config.Cloud.Provider {
+	case CloudAWS, CloudAzure, CloudGCP, CloudNone:
+	default:
+		return nil, fmt.Errorf("unsupported cloud.provider %q in .springwell.yml, use %s, %s, %s or %s", config.Cloud.Provider, CloudAWS, CloudAzure, CloudGCP, CloudNone)
+	}
+
 	return &config, nil
 }
 
@@ -81,9 +129,13 @@ func GetDefaultConfig() *Config {
 
 	config.Templates.Directory = ".springwell/templates"
 
+	config.Cloud.Provider = CloudNone
+
 	config.AWS.Region = "us-east-1"
 	config.AWS.DefaultServices = []string{"s3", "secretsManager"}
 
+	config.Errors.Style = ErrorStyleResponseStatus
+
 	return config
 }
 
@@ -96,7 +148,9 @@ func SaveConfig(config *Config, projectDir string) error {
 	v.Set("project", config.Project)
 	v.Set("code", config.Code)
 	v.Set("templates", config.Templates)
+	v.Set("cloud", config.Cloud)
 	v.Set("aws", config.AWS)
+	v.Set("errors", config.Errors)
 	v.Set("plugins", config.Plugins)
 
 	// Create the directory if it doesn't exist
@@ -0,0 +1,861 @@
+package generator
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/springwell/cli/pkg/config"
+	"github.com/springwell/cli/pkg/openapi"
+	"github.com/springwell/cli/pkg/util"
+)
+
+var (
+	openAPIVersionPattern = regexp.MustCompile(`(?m)^openapi:\s*["']?3\.0\.\d+["']?\s*$`)
+	javaNumberPattern     = regexp.MustCompile(`^-?\d+(?:\.\d+)?`)
+	filterChainPattern    = regexp.MustCompile(`\bSecurityFilterChain\s+\w+\s*\(`)
+	publicPathsPattern    = regexp.MustCompile(`PUBLIC_PATHS\s*=\s*\{([^}]*)\}`)
+	permitAllPattern      = regexp.MustCompile(`requestMatchers\(([^)]*)\)\s*\.permitAll\(\)`)
+)
+
+// errorResponses names the shared responses describing the errors of the endpoints
+var errorResponses = map[int][2]string{
+	400: {"BadRequest", "The request is invalid"},
+	401: {"Unauthorized", "The request is not authenticated"},
+	403: {"Forbidden", "The authenticated user may not perform the operation"},
+	404: {"NotFound", "The resource does not exist"},
+}
+
+// apiSecurity describes how Spring Security protects the project's endpoints
+type apiSecurity struct {
+	name        string        // Name of the security scheme
+	scheme      *util.YAMLMap // Security scheme authenticating the requests
+	publicPaths []string      // Ant patterns of the paths anyone may call
+}
+
+// apiExporter builds OpenAPI schemas from the project's Java types
+type apiExporter struct {
+	javaDir string
+	sources map[string]string // Paths of the project's Java sources by class name
+	schemas *util.YAMLMap
+}
+
+// ExportAPI describes the project's REST controllers, their request and response types and
+// the errors they respond with as OpenAPI 3.1, and merges the description into the document
+// at specPath. Paths, operations and schemas already in the document keep their content. It
+// returns the number of operations described.
+func (g *ApiGenerator) ExportAPI(specPath string) (int, error) {
+	specFile := filepath.Join(g.ProjectDir, specPath)
+	doc, _, count, err := g.describeAPI(specFile)
+	if err != nil {
+		return 0, err
+	}
+	fragment, err := util.EncodeYAML(doc)
+	if err != nil {
+		return 0, err
+	}
+	if err := util.MergeYAML(specFile, fragment); err != nil {
+		return 0, err
+	}
+
+	// The exported schemas use 3.1 keywords, such as numeric exclusive bounds
+	content, err := os.ReadFile(specFile)
+	if err != nil {
+		return 0, err
+	}
+	if openAPIVersionPattern.Match(content) {
+		util.PrintInfo("Upgrading %s to OpenAPI 3.1.0", specPath)
+		if strings.Contains(string(content), "nullable:") {
+			util.PrintWarning("%s uses nullable, which OpenAPI 3.1 replaces with a null type, such as type: [string, \"null\"]", specPath)
+		}
+		content = openAPIVersionPattern.ReplaceAll(content, []byte("openapi: 3.1.0"))
+		if err := util.WriteFile(specFile, string(content)); err != nil {
+			return 0, err
+		}
+	}
+	return count, nil
+}
+
+// describeAPI describes the project's REST controllers as an OpenAPI 3.1 document. Its paths
+// are relative to the server path of the document at specFile when it exists, or to /api
+// when every endpoint is under it. It returns the document, the server path and the number of
+// operations described.
+func (g *ApiGenerator) describeAPI(specFile string) (*util.YAMLMap, string, int, error) {
+	javaDir := filepath.Join(g.ProjectDir, "src/main/java", strings.ReplaceAll(g.Config.Project.Package, ".", "/"))
+	endpoints, err := scanControllers(javaDir)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	if len(endpoints) == 0 {
+		return nil, "", 0, fmt.Errorf("no REST controllers found in controller/, generate them with `springwell generate entity`")
+	}
+
+	basePath := ""
+	hasServers := false
+	if fileExists(specFile) {
+		existing, err := openapi.Load(specFile)
+		if err != nil {
+			return nil, "", 0, err
+		}
+		if len(existing.Servers) > 0 {
+			hasServers = true
+			basePath = (&apiBuilder{doc: existing}).basePath()
+		}
+	}
+	if !hasServers && commonPrefix(endpoints) == "/api" {
+		basePath = "/api"
+	}
+
+	e := &apiExporter{
+		javaDir: javaDir,
+		sources: map[string]string{},
+		schemas: util.NewYAMLMap(),
+	}
+	err = filepath.Walk(filepath.Join(g.ProjectDir, "src/main/java"), func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() && strings.HasSuffix(path, ".java") {
+			e.sources[strings.TrimSuffix(info.Name(), ".java")] = path
+		}
+		return err
+	})
+	if err != nil {
+		return nil, "", 0, err
+	}
+
+	security, err := g.projectSecurity(e.sources)
+	if err != nil {
+		return nil, "", 0, err
+	}
+
+	paths := util.NewYAMLMap()
+	usedErrors := map[int]bool{}
+	secured := false
+	for _, endpoint := range endpoints {
+		authenticated := security != nil && (endpoint.Authorized || !security.public(endpoint.Path))
+		if authenticated {
+			endpoint.Errors = append(endpoint.Errors, 401)
+			if endpoint.Authorized {
+				endpoint.Errors = append(endpoint.Errors, 403)
+			}
+			sort.Ints(endpoint.Errors)
+		}
+		path := endpoint.Path
+		if basePath != "" {
+			if path != basePath && !strings.HasPrefix(path, basePath+"/") {
+				util.PrintWarning("Skipping %s %s, which is outside the server path %s", endpoint.Method, path, basePath)
+				continue
+			}
+			path = strings.TrimPrefix(path, basePath)
+			if path == "" {
+				path = "/"
+			}
+		}
+		operation, err := e.operation(endpoint)
+		if err != nil {
+			return nil, "", 0, fmt.Errorf("%s.%s: %v", endpoint.Controller, endpoint.Name, err)
+		}
+		if authenticated {
+			operation.Set("security", []interface{}{util.NewYAMLMap().Set(security.name, []string{})})
+			secured = true
+		}
+		for _, code := range endpoint.Errors {
+			usedErrors[code] = true
+		}
+		item, ok := paths.Get(path)
+		if !ok {
+			item = util.NewYAMLMap()
+			paths.Set(path, item)
+		}
+		item.(*util.YAMLMap).Set(strings.ToLower(endpoint.Method), operation)
+	}
+
+	// Problem details are written as application/problem+json, other errors as the error
+	// body of Spring Boot
+	errorSchema, errorType, errorContent := "ErrorResponse", "application/json", errorResponseSchema()
+	if g.Config.Errors.Style == config.ErrorStyleProblemDetails {
+		errorSchema, errorType, errorContent = "ProblemDetail", "application/problem+json", problemDetailSchema()
+	}
+	responses := util.NewYAMLMap()
+	for _, code := range []int{400, 401, 403, 404} {
+		if !usedErrors[code] {
+			continue
+		}
+		responses.Set(errorResponses[code][0], util.NewYAMLMap().
+			Set("description", errorResponses[code][1]).
+			Set("content", util.NewYAMLMap().Set(errorType, util.NewYAMLMap().
+				Set("schema", util.NewYAMLMap().Set("$ref", "#/components/schemas/"+errorSchema)))))
+	}
+	if responses.Len() > 0 {
+		e.schemas.Set(errorSchema, errorContent)
+	}
+
+	title, version := projectInfo(g.ProjectDir)
+	doc := util.NewYAMLMap()
+	doc.Set("openapi", "3.1.0")
+	doc.Set("info", util.NewYAMLMap().
+		Set("title", title+" API").
+		Set("version", version).
+		Set("description", "REST API of "+title))
+	if !hasServers && basePath != "" {
+		doc.Set("servers", []interface{}{util.NewYAMLMap().Set("url", basePath)})
+	}
+	doc.Set("paths", paths)
+	components := util.NewYAMLMap().Set("schemas", e.schemas)
+	if responses.Len() > 0 {
+		components.Set("responses", responses)
+	}
+	if secured {
+		components.Set("securitySchemes", util.NewYAMLMap().Set(security.name, security.scheme))
+	}
+	doc.Set("components", components)
+
+	return doc, basePath, len(endpoints), nil
+}
+
+// projectSecurity describes the project's Spring Security configuration from its
+// SecurityFilterChain, or returns nil when the project does not use Spring Security. Without a
+// filter chain, Spring Boot authenticates every request with HTTP Basic.
+func (g *ApiGenerator) projectSecurity(sources map[string]string) (*apiSecurity, error) {
+	chain := ""
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		content, err := os.ReadFile(sources[name])
+		if err != nil {
+			return nil, err
+		}
+		if filterChainPattern.Match(content) {
+			chain = string(content)
+			break
+		}
+	}
+	if chain == "" && !util.HasMavenDependency(filepath.Join(g.ProjectDir, "pom.xml"), "org.springframework.boot", "spring-boot-starter-security") {
+		return nil, nil
+	}
+
+	security := &apiSecurity{
+		name:   "basicAuth",
+		scheme: util.NewYAMLMap().Set("type", "http").Set("scheme", "basic"),
+	}
+	switch {
+	case strings.Contains(chain, ".oauth2ResourceServer("):
+		security.name = "bearerAuth"
+		security.scheme = util.NewYAMLMap().Set("type", "http").Set("scheme", "bearer").Set("bearerFormat", "JWT")
+	case strings.Contains(chain, ".oauth2Login("):
+		properties, err := applicationProperties(g.ProjectDir)
+		if err != nil {
+			return nil, err
+		}
+		issuer := properties["oauth2.issuer-uri"]
+		if issuer == "" {
+			for key, value := range properties {
+				if strings.HasPrefix(key, "spring.security.oauth2.client.provider.") && strings.HasSuffix(key, ".issuer-uri") {
+					issuer = value
+				}
+			}
+		}
+		if issuer = resolvePlaceholders(issuer, properties); issuer != "" {
+			security.name = "openIdConnect"
+			security.scheme = util.NewYAMLMap().
+				Set("type", "openIdConnect").
+				Set("openIdConnectUrl", strings.TrimSuffix(issuer, "/")+"/.well-known/openid-configuration")
+		} else {
+			// The browser keeps the login in the session
+			security.name = "sessionCookie"
+			security.scheme = util.NewYAMLMap().Set("type", "apiKey").Set("in", "cookie").Set("name", "JSESSIONID")
+		}
+	}
+
+	var matchers []string
+	if match := publicPathsPattern.FindStringSubmatch(chain); match != nil {
+		matchers = append(matchers, match[1])
+	}
+	for _, match := range permitAllPattern.FindAllStringSubmatch(chain, -1) {
+		matchers = append(matchers, match[1])
+	}
+	for _, matcher := range matchers {
+		for _, path := range javaStringPattern.FindAllStringSubmatch(matcher, -1) {
+			security.publicPaths = append(security.publicPaths, path[1])
+		}
+	}
+	return security, nil
+}
+
+// public reports whether anyone may call a path without authenticating
+func (s *apiSecurity) public(requestPath string) bool {
+	for _, pattern := range s.publicPaths {
+		if prefix, ok := strings.CutSuffix(pattern, "/**"); ok {
+			if requestPath == prefix || strings.HasPrefix(requestPath, prefix+"/") {
+				return true
+			}
+		} else if matched, _ := path.Match(pattern, requestPath); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// operation describes an endpoint
+func (e *apiExporter) operation(endpoint RestEndpoint) (*util.YAMLMap, error) {
+	operation := util.NewYAMLMap()
+	tag := endpoint.Entity
+	if tag == "" {
+		tag = strings.TrimSuffix(endpoint.Controller, "Controller")
+	}
+	operation.Set("tags", []string{tag})
+	if endpoint.Summary != "" {
+		operation.Set("summary", endpoint.Summary)
+	}
+	operation.Set("operationId", endpoint.Name)
+
+	var parameters []interface{}
+	for _, param := range endpoint.Params {
+		schema, err := e.schema(param.Type)
+		if err != nil {
+			return nil, err
+		}
+		if param.Default != "" {
+			schema.Set("default", parameterDefault(param.Type, param.Default))
+		}
+		parameter := util.NewYAMLMap().Set("name", param.Name).Set("in", param.In)
+		if param.Description != "" {
+			parameter.Set("description", param.Description)
+		}
+		if param.Required {
+			parameter.Set("required", true)
+		}
+		parameters = append(parameters, parameter.Set("schema", schema))
+	}
+	if endpoint.Filter != "" {
+		filterParams, err := e.filterParameters(endpoint.Filter)
+		if err != nil {
+			return nil, err
+		}
+		parameters = append(parameters, filterParams...)
+	}
+	if endpoint.Pageable {
+		parameters = append(parameters, pageParameters(endpoint)...)
+	}
+	if len(parameters) > 0 {
+		operation.Set("parameters", parameters)
+	}
+
+	if endpoint.Body != "" {
+		schema, err := e.schema(endpoint.Body)
+		if err != nil {
+			return nil, err
+		}
+		operation.Set("requestBody", util.NewYAMLMap().
+			Set("required", true).
+			Set("content", jsonContent(schema)))
+	}
+
+	responses := util.NewYAMLMap()
+	success := util.NewYAMLMap().Set("description", http.StatusText(endpoint.Status))
+	if endpoint.Response != "Void" && endpoint.Response != "void" && endpoint.Status != 204 {
+		schema, err := e.schema(endpoint.Response)
+		if err != nil {
+			return nil, err
+		}
+		success.Set("content", jsonContent(schema))
+	}
+	responses.Set(strconv.Itoa(endpoint.Status), success)
+	for _, code := range endpoint.Errors {
+		responses.Set(strconv.Itoa(code), util.NewYAMLMap().Set("$ref", "#/components/responses/"+errorResponses[code][0]))
+	}
+	operation.Set("responses", responses)
+	return operation, nil
+}
+
+// schema returns the JSON Schema of a Java type, adding the schemas of the project's classes
+// it uses to the components
+func (e *apiExporter) schema(javaType string) (*util.YAMLMap, error) {
+	javaType = strings.TrimSpace(javaType)
+	if strings.HasSuffix(javaType, "[]") {
+		if javaType == "byte[]" {
+			return util.NewYAMLMap().Set("type", "string").Set("format", "byte"), nil
+		}
+		items, err := e.schema(strings.TrimSuffix(javaType, "[]"))
+		if err != nil {
+			return nil, err
+		}
+		return util.NewYAMLMap().Set("type", "array").Set("items", items), nil
+	}
+	if match := javaGenericPattern.FindStringSubmatch(javaType); match != nil {
+		arguments := splitTypeArguments(match[2])
+		switch match[1] {
+		case "List", "Collection", "Set":
+			items, err := e.schema(arguments[0])
+			if err != nil {
+				return nil, err
+			}
+			schema := util.NewYAMLMap().Set("type", "array").Set("items", items)
+			if match[1] == "Set" {
+				schema.Set("uniqueItems", true)
+			}
+			return schema, nil
+		case "Map":
+			values, err := e.schema(arguments[len(arguments)-1])
+			if err != nil {
+				return nil, err
+			}
+			return util.NewYAMLMap().Set("type", "object").Set("additionalProperties", values), nil
+		case "Optional":
+			return e.schema(arguments[0])
+		case "PageResponse":
+			return e.pageSchema(arguments[0])
+		}
+		return util.NewYAMLMap().Set("type", "object"), nil
+	}
+
+	javaType = javaType[strings.LastIndex(javaType, ".")+1:]
+	switch javaType {
+	case "Object":
+		return util.NewYAMLMap(), nil
+	case "char", "Character":
+		return util.NewYAMLMap().Set("type", "string").Set("maxLength", 1), nil
+	case "Date":
+		return util.NewYAMLMap().Set("type", "string").Set("format", "date-time"), nil
+	}
+	if schemaType, ok := javaSchemaTypes[javaType]; ok {
+		schema := util.NewYAMLMap().Set("type", schemaType[0])
+		if schemaType[1] != "" {
+			schema.Set("format", schemaType[1])
+		}
+		return schema, nil
+	}
+
+	ref := util.NewYAMLMap().Set("$ref", "#/components/schemas/"+javaType)
+	if _, ok := e.schemas.Get(javaType); ok {
+		return ref, nil
+	}
+	path, ok := e.sources[javaType]
+	if !ok {
+		return util.NewYAMLMap().Set("type", "object"), nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	source := string(content)
+	schema := util.NewYAMLMap()
+	// Reserve the name before resolving the properties, which may reference the type
+	e.schemas.Set(javaType, schema)
+
+	switch {
+	case regexp.MustCompile(`\benum\s+` + javaType + `\b`).MatchString(source):
+		schema.Set("type", "string").Set("enum", enumConstants(source, javaType))
+	case regexp.MustCompile(`\brecord\s+` + javaType + `\b`).MatchString(source):
+		if err := e.recordSchema(schema, source, javaType); err != nil {
+			return nil, err
+		}
+	case strings.Contains(source, "@Entity"):
+		if err := e.entitySchema(schema, path); err != nil {
+			return nil, err
+		}
+	default:
+		schema.Set("type", "object")
+	}
+	return ref, nil
+}
+
+// recordSchema describes a record, with the Bean Validation constraints of its components
+func (e *apiExporter) recordSchema(schema *util.YAMLMap, source, name string) error {
+	components, err := recordComponentDeclarations(source, name)
+	if err != nil {
+		return err
+	}
+	// Responses of an entity require what the entity requires
+	var entity *ParsedEntity
+	if entityName := strings.TrimSuffix(name, "Response"); entityName != name {
+		if entity, err = ParseEntity(filepath.Join(e.javaDir, "domain/entity", entityName+".java")); err != nil {
+			entity = nil
+		}
+	}
+
+	schema.Set("type", "object")
+	declaration := strings.Index(source, "record "+name)
+	declaration = strings.LastIndex(source[:declaration], "\n") + 1
+	if description := javadocSummary(precedingJavadoc(source[:declaration])); description != "" {
+		schema.Set("description", description+".")
+	}
+	properties := util.NewYAMLMap()
+	var required []string
+	for _, component := range components {
+		property, err := e.schema(component.Type)
+		if err != nil {
+			return err
+		}
+		name := component.Name
+		if args := annotationArgs(component.Annotations, "JsonProperty"); args != nil && args["value"] != "" {
+			name = args["value"]
+		}
+		if applyConstraints(property, component.Annotations, component.Type) || isPrimitive(component.Type) {
+			required = append(required, name)
+		} else if entity != nil && entityRequires(entity, component.Name) {
+			required = append(required, name)
+		}
+		properties.Set(name, property)
+	}
+	schema.Set("properties", properties)
+	if len(required) > 0 {
+		schema.Set("required", required)
+	}
+	return nil
+}
+
+// entitySchema describes an entity serialized as JSON, with its relations as nested objects
+func (e *apiExporter) entitySchema(schema *util.YAMLMap, path string) error {
+	entity, err := ParseEntity(path)
+	if err != nil {
+		return err
+	}
+	schema.Set("type", "object")
+	schema.Set("description", entity.Name+" entity.")
+	properties := util.NewYAMLMap()
+	properties.Set("id", util.NewYAMLMap().Set("type", "integer").Set("format", "int64").Set("readOnly", true))
+	required := []string{}
+	for _, field := range entity.Fields {
+		property, err := e.schema(field["type"])
+		if err != nil {
+			return err
+		}
+		properties.Set(field["name"], property)
+		if entityRequires(entity, field["name"]) {
+			required = append(required, field["name"])
+		}
+	}
+	for _, relation := range entity.Relations {
+		javaType := relation["entity"]
+		if relation["type"] == "oneToMany" || relation["type"] == "manyToMany" {
+			javaType = "List<" + javaType + ">"
+		}
+		property, err := e.schema(javaType)
+		if err != nil {
+			return err
+		}
+		properties.Set(relation["field"], property)
+	}
+	if entity.Audit {
+		for _, name := range []string{"createdAt", "updatedAt"} {
+			properties.Set(name, util.NewYAMLMap().Set("type", "string").Set("format", "date-time").Set("readOnly", true))
+		}
+	}
+	schema.Set("properties", properties)
+	if len(required) > 0 {
+		schema.Set("required", required)
+	}
+	return nil
+}
+
+// filterParameters describes the components of a filter record as optional query parameters
+func (e *apiExporter) filterParameters(filter string) ([]interface{}, error) {
+	path, ok := e.sources[filter]
+	if !ok {
+		return nil, nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	components, err := recordComponentDeclarations(string(content), filter)
+	if err != nil {
+		return nil, err
+	}
+	names := map[string]bool{}
+	for _, component := range components {
+		names[component.Name] = true
+	}
+
+	var parameters []interface{}
+	for _, component := range components {
+		schema, err := e.schema(component.Type)
+		if err != nil {
+			return nil, err
+		}
+		var description string
+		switch {
+		case strings.HasSuffix(component.Name, "Min") && names[strings.TrimSuffix(component.Name, "Min")]:
+			description = "Lowest " + strings.TrimSuffix(component.Name, "Min") + " to match, inclusive"
+		case strings.HasSuffix(component.Name, "Max") && names[strings.TrimSuffix(component.Name, "Max")]:
+			description = "Highest " + strings.TrimSuffix(component.Name, "Max") + " to match, inclusive"
+		case filterOperation(map[string]string{"type": component.Type}) == "like":
+			description = "Text the " + component.Name + " contains, ignoring case"
+		default:
+			description = "Exact " + component.Name + " to match"
+		}
+		parameters = append(parameters, util.NewYAMLMap().
+			Set("name", component.Name).
+			Set("in", "query").
+			Set("description", description).
+			Set("schema", schema))
+	}
+	return parameters, nil
+}
+
+// pageSchema describes a PageResponse of a type
+func (e *apiExporter) pageSchema(javaType string) (*util.YAMLMap, error) {
+	items, err := e.schema(javaType)
+	if err != nil {
+		return nil, err
+	}
+	name := javaType[strings.LastIndex(javaType, ".")+1:] + "Page"
+	ref := util.NewYAMLMap().Set("$ref", "#/components/schemas/"+name)
+	if _, ok := e.schemas.Get(name); ok {
+		return ref, nil
+	}
+	integer := func(format string) *util.YAMLMap {
+		return util.NewYAMLMap().Set("type", "integer").Set("format", format)
+	}
+	e.schemas.Set(name, util.NewYAMLMap().
+		Set("type", "object").
+		Set("description", "A page of "+javaType+" results.").
+		Set("properties", util.NewYAMLMap().
+			Set("content", util.NewYAMLMap().Set("type", "array").Set("items", items)).
+			Set("page", integer("int32").Set("description", "Zero-based index of the page")).
+			Set("size", integer("int32").Set("description", "Maximum number of results in a page")).
+			Set("totalElements", integer("int64")).
+			Set("totalPages", integer("int32")).
+			Set("last", util.NewYAMLMap().Set("type", "boolean").Set("description", "Whether this is the last page"))).
+		Set("required", []string{"content", "page", "size", "totalElements", "totalPages", "last"}))
+	return ref, nil
+}
+
+// pageParameters describes the page, size and sort parameters of a pageable endpoint
+func pageParameters(endpoint RestEndpoint) []interface{} {
+	sortDescription := "Sort order, as property or property,desc; repeat to sort by several properties"
+	if len(endpoint.Sortable) > 0 {
+		sortDescription += ". Sortable properties: " + strings.Join(endpoint.Sortable, ", ")
+	}
+	sortItems := util.NewYAMLMap().Set("type", "string")
+	if len(endpoint.Sortable) > 0 {
+		var patterns []string
+		for _, property := range endpoint.Sortable {
+			patterns = append(patterns, regexp.QuoteMeta(property))
+		}
+		sortItems.Set("pattern", "^("+strings.Join(patterns, "|")+")(,(asc|desc|ASC|DESC))?$")
+	}
+	sort := util.NewYAMLMap().Set("type", "array").Set("items", sortItems)
+	if endpoint.PageSort != "" {
+		sort.Set("default", []string{endpoint.PageSort + ",asc"})
+	}
+	return []interface{}{
+		util.NewYAMLMap().Set("name", "page").Set("in", "query").
+			Set("description", "Zero-based index of the page").
+			Set("schema", util.NewYAMLMap().Set("type", "integer").Set("minimum", 0).Set("default", 0)),
+		util.NewYAMLMap().Set("name", "size").Set("in", "query").
+			Set("description", "Maximum number of results in the page").
+			Set("schema", util.NewYAMLMap().Set("type", "integer").Set("minimum", 1).Set("default", endpoint.PageSize)),
+		util.NewYAMLMap().Set("name", "sort").Set("in", "query").
+			Set("description", sortDescription).
+			Set("schema", sort),
+	}
+}
+
+// applyConstraints adds the Bean Validation constraints among annotations to a schema, and
+// reports whether they make the value required
+func applyConstraints(schema *util.YAMLMap, annotations, javaType string) bool {
+	isArray := strings.HasSuffix(javaType, "[]") || strings.HasPrefix(javaType, "List<") || strings.HasPrefix(javaType, "Set<") || strings.HasPrefix(javaType, "Collection<")
+	minKey, maxKey := "minLength", "maxLength"
+	if isArray {
+		minKey, maxKey = "minItems", "maxItems"
+	}
+	required := false
+	if annotationArgs(annotations, "NotNull") != nil {
+		required = true
+	}
+	if annotationArgs(annotations, "NotBlank") != nil || annotationArgs(annotations, "NotEmpty") != nil {
+		required = true
+		schema.Set(minKey, 1)
+	}
+	if args := annotationArgs(annotations, "Size"); args != nil {
+		if value, err := strconv.Atoi(args["min"]); err == nil {
+			schema.Set(minKey, value)
+		}
+		if value, err := strconv.Atoi(args["max"]); err == nil {
+			schema.Set(maxKey, value)
+		}
+	}
+	if args := annotationArgs(annotations, "Min"); args != nil {
+		if value, ok := javaNumber(args["value"]); ok {
+			schema.Set("minimum", value)
+		}
+	}
+	if args := annotationArgs(annotations, "Max"); args != nil {
+		if value, ok := javaNumber(args["value"]); ok {
+			schema.Set("maximum", value)
+		}
+	}
+	for annotation, key := range map[string]string{"DecimalMin": "minimum", "DecimalMax": "maximum"} {
+		args := annotationArgs(annotations, annotation)
+		if args == nil {
+			continue
+		}
+		if value, ok := javaNumber(args["value"]); ok {
+			if args["inclusive"] == "false" {
+				key = "exclusive" + capitalize(key)
+			}
+			schema.Set(key, value)
+		}
+	}
+	switch {
+	case annotationArgs(annotations, "Positive") != nil:
+		schema.Set("exclusiveMinimum", 0)
+	case annotationArgs(annotations, "PositiveOrZero") != nil:
+		schema.Set("minimum", 0)
+	case annotationArgs(annotations, "Negative") != nil:
+		schema.Set("exclusiveMaximum", 0)
+	case annotationArgs(annotations, "NegativeOrZero") != nil:
+		schema.Set("maximum", 0)
+	}
+	if annotationArgs(annotations, "Email") != nil {
+		schema.Set("format", "email")
+	}
+	if args := annotationArgs(annotations, "Pattern"); args != nil && args["regexp"] != "" {
+		schema.Set("pattern", args["regexp"])
+	}
+	return required
+}
+
+// entityRequires reports whether an entity property can never be null
+func entityRequires(entity *ParsedEntity, property string) bool {
+	if property == "id" || (entity.Audit && property == "createdAt") {
+		return true
+	}
+	for _, field := range entity.Fields {
+		if field["name"] == property {
+			return field["nullable"] != "true"
+		}
+	}
+	return false
+}
+
+// errorResponseSchema describes the error body Spring Boot writes for failed requests
+func errorResponseSchema() *util.YAMLMap {
+	text := func(description string) *util.YAMLMap {
+		return util.NewYAMLMap().Set("type", "string").Set("description", description)
+	}
+	return util.NewYAMLMap().
+		Set("type", "object").
+		Set("description", "Error body written by Spring Boot for failed requests.").
+		Set("properties", util.NewYAMLMap().
+			Set("timestamp", util.NewYAMLMap().Set("type", "string").Set("format", "date-time")).
+			Set("status", util.NewYAMLMap().Set("type", "integer").Set("format", "int32").Set("description", "HTTP status code")).
+			Set("error", text("HTTP status text")).
+			Set("message", text("Reason of the error, when server.error.include-message is enabled")).
+			Set("path", text("Path of the request"))).
+		Set("required", []string{"timestamp", "status", "error", "path"})
+}
+
+// problemDetailSchema describes the RFC 9457 problem details the exception handler answers
+// failed requests with
+func problemDetailSchema() *util.YAMLMap {
+	text := func(description string) *util.YAMLMap {
+		return util.NewYAMLMap().Set("type", "string").Set("description", description)
+	}
+	uri := func(description string) *util.YAMLMap {
+		return text(description).Set("format", "uri")
+	}
+	violation := util.NewYAMLMap().
+		Set("type", "object").
+		Set("properties", util.NewYAMLMap().
+			Set("field", text("Path of the invalid field")).
+			Set("message", text("Why the value is invalid"))).
+		Set("required", []string{"field", "message"})
+	return util.NewYAMLMap().
+		Set("type", "object").
+		Set("description", "Problem details of a failed request, as defined by RFC 9457.").
+		Set("properties", util.NewYAMLMap().
+			Set("type", uri("URI identifying the problem type")).
+			Set("title", text("Short summary of the problem type")).
+			Set("status", util.NewYAMLMap().Set("type", "integer").Set("format", "int32").Set("description", "HTTP status code")).
+			Set("detail", text("Explanation of this occurrence of the problem")).
+			Set("instance", uri("URI of the request")).
+			Set("traceId", text("Trace ID of the request, to find it in the logs")).
+			Set("violations", util.NewYAMLMap().
+				Set("type", "array").
+				Set("description", "Invalid values of a request that failed validation").
+				Set("items", violation))).
+		Set("required", []string{"type", "title", "status"})
+}
+
+// jsonContent returns a content map with a JSON media type of the schema
+func jsonContent(schema *util.YAMLMap) *util.YAMLMap {
+	return util.NewYAMLMap().Set("application/json", util.NewYAMLMap().Set("schema", schema))
+}
+
+// enumConstants returns the constant names of a Java enum
+func enumConstants(source, name string) []string {
+	start := regexp.MustCompile(`\benum\s+` + name + `\b[^{]*\{`).FindStringIndex(source)
+	if start == nil {
+		return nil
+	}
+	body := source[start[1]:]
+	if end := strings.IndexAny(body, ";}"); end >= 0 {
+		body = body[:end]
+	}
+	var constants []string
+	for _, constant := range splitTypeArguments(body) {
+		if name := regexp.MustCompile(`^\s*(?:@\w+\s*)*([A-Z][A-Z0-9_]*)`).FindStringSubmatch(constant); name != nil {
+			constants = append(constants, name[1])
+		}
+	}
+	return constants
+}
+
+// commonPrefix returns the first path segment shared by all endpoints, such as /api
+func commonPrefix(endpoints []RestEndpoint) string {
+	prefix := ""
+	for i, endpoint := range endpoints {
+		segments := strings.SplitN(strings.TrimPrefix(endpoint.Path, "/"), "/", 2)
+		if len(segments) < 2 {
+			return ""
+		}
+		if i == 0 {
+			prefix = "/" + segments[0]
+		} else if prefix != "/"+segments[0] {
+			return ""
+		}
+	}
+	return prefix
+}
+
+// javaNumber parses the number of a Java literal, such as 1, 1L or "0.5"
+func javaNumber(literal string) (interface{}, bool) {
+	match := javaNumberPattern.FindString(strings.Trim(literal, `"`))
+	if match == "" {
+		return nil, false
+	}
+	if value, err := strconv.ParseInt(match, 10, 64); err == nil {
+		return value, true
+	}
+	value, err := strconv.ParseFloat(match, 64)
+	return value, err == nil
+}
+
+// parameterDefault converts the default value of a parameter to the type of its schema
+func parameterDefault(javaType, value string) interface{} {
+	switch javaSchemaTypes[javaType][0] {
+	case "integer":
+		if number, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return number
+		}
+	case "number":
+		if number, err := strconv.ParseFloat(value, 64); err == nil {
+			return number
+		}
+	case "boolean":
+		if flag, err := strconv.ParseBool(value); err == nil {
+			return flag
+		}
+	}
+	return value
+}
@@ -0,0 +1,112 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/springwell/cli/pkg/config"
+	"github.com/springwell/cli/pkg/util"
+)
+
+const securedController = `package com.example.controller;
+
+@RestController
+@RequestMapping("/api/reports")
+public class ReportController {
+
+    /**
+     * Get the number of reports.
+     */
+    @GetMapping("/count")
+    public ResponseEntity<Long> countReports() {
+        return ResponseEntity.ok(0L);
+    }
+
+    /**
+     * Delete every report.
+     */
+    @DeleteMapping
+    @PreAuthorize("hasRole('ADMIN')")
+    public ResponseEntity<Void> deleteReports() {
+        return ResponseEntity.noContent().build();
+    }
+
+    /**
+     * Get the status of the reports.
+     */
+    @GetMapping("/status")
+    public ResponseEntity<String> status() {
+        return ResponseEntity.ok("UP");
+    }
+}
+`
+
+const securityConfig = `package com.example.config;
+
+@Configuration
+public class SecurityConfig {
+
+    static final String[] PUBLIC_PATHS = {
+        "/actuator/health/**",
+        "/api/reports/status"
+    };
+
+    @Bean
+    public SecurityFilterChain securityFilterChain(HttpSecurity http) throws Exception {
+        http
+            .authorizeHttpRequests(authorize -> authorize
+                .requestMatchers(PUBLIC_PATHS).permitAll()
+                .anyRequest().authenticated())
+            .oauth2ResourceServer(oauth2 -> oauth2.jwt(Customizer.withDefaults()));
+        return http.build();
+    }
+}
+`
+
+func TestExportAPIDescribesSecuredOperations(t *testing.T) {
+	projectDir := t.TempDir()
+	javaDir := filepath.Join(projectDir, "src/main/java/com/example")
+	for path, content := range map[string]string{
+		"controller/ReportController.java": securedController,
+		"config/SecurityConfig.java":       securityConfig,
+	} {
+		path = filepath.Join(javaDir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := config.GetDefaultConfig()
+	cfg.Project.Package = "com.example"
+	cfg.Errors.Style = config.ErrorStyleProblemDetails
+
+	doc, _, _, err := NewApiGenerator(cfg, projectDir).describeAPI(filepath.Join(projectDir, "api.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := util.EncodeYAML(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"/reports/count:\n    get:",
+		"\"401\":\n          $ref: '#/components/responses/Unauthorized'\n      security:\n        - bearerAuth: []",
+		"\"401\":\n          $ref: '#/components/responses/Unauthorized'\n        \"403\":\n          $ref: '#/components/responses/Forbidden'\n      security:\n        - bearerAuth: []",
+		"Unauthorized:\n      description: The request is not authenticated\n      content:\n        application/problem+json:\n          schema:\n            $ref: '#/components/schemas/ProblemDetail'",
+		"securitySchemes:\n    bearerAuth:\n      type: http\n      scheme: bearer",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("exported API does not contain %q:\n%s", want, content)
+		}
+	}
+
+	// Anyone may call the public status endpoint
+	status := content[strings.Index(content, "/reports/status:"):strings.Index(content, "components:")]
+	if strings.Contains(status, "security:") || strings.Contains(status, "\"401\"") {
+		t.Errorf("public operation is described as secured:\n%s", status)
+	}
+}
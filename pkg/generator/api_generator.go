@@ -0,0 +1,1359 @@
+package generator
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/springwell/cli/pkg/config"
+	"github.com/springwell/cli/pkg/openapi"
+	"github.com/springwell/cli/pkg/util"
+	"gopkg.in/yaml.v3"
+)
+
+// apiGeneratedMarker starts every file written by `api generate`, so regeneration only
+// replaces or removes files it wrote itself
+const apiGeneratedMarker = "// Generated by springwell api generate"
+
+var (
+	javaClassNamePattern    = regexp.MustCompile(`^[A-Z][A-Za-z0-9]*$`)
+	javaVariableNamePattern = regexp.MustCompile(`^[a-z][A-Za-z0-9]*$`)
+	nonIdentifierPattern    = regexp.MustCompile(`[^A-Za-z0-9]+`)
+	versionSegmentPattern   = regexp.MustCompile(`^v\d+$`)
+)
+
+// javaReservedWords cannot be used as identifiers
+var javaReservedWords = map[string]bool{
+	"abstract": true, "assert": true, "boolean": true, "break": true, "byte": true, "case": true,
+	"catch": true, "char": true, "class": true, "const": true, "continue": true, "default": true,
+	"do": true, "double": true, "else": true, "enum": true, "extends": true, "final": true,
+	"finally": true, "float": true, "for": true, "goto": true, "if": true, "implements": true,
+	"import": true, "instanceof": true, "int": true, "interface": true, "long": true, "native": true,
+	"new": true, "package": true, "private": true, "protected": true, "public": true, "return": true,
+	"short": true, "static": true, "strictfp": true, "super": true, "switch": true, "synchronized": true,
+	"this": true, "throw": true, "throws": true, "transient": true, "try": true, "void": true,
+	"volatile": true, "while": true, "true": true, "false": true, "null": true, "record": true,
+	"var": true, "yield": true,
+}
+
+// ApiOptions holds the options of `api generate`
+type ApiOptions struct {
+	Spec         string // Path of the OpenAPI document
+	ApiPackage   string // Package of the controller interfaces
+	ModelPackage string // Package of the records and enums
+	Reactive     bool   // Whether operations return Mono
+}
+
+// ApiGenerator generates Spring controller interfaces and models from an OpenAPI document
+type ApiGenerator struct {
+	Config     *config.Config
+	ProjectDir string
+}
+
+// NewApiGenerator creates a new ApiGenerator
+func NewApiGenerator(config *config.Config, projectDir string) *ApiGenerator {
+	return &ApiGenerator{
+		Config:     config,
+		ProjectDir: projectDir,
+	}
+}
+
+// DefaultOptions returns the options configured in openapi-generator.yaml, as written by
+// `new`, falling back to the project's api.yaml and packages
+func (g *ApiGenerator) DefaultOptions() (ApiOptions, error) {
+	opts := ApiOptions{
+		Spec:         "src/main/resources/openapi/api.yaml",
+		ApiPackage:   g.Config.Project.Package + ".controller.api",
+		ModelPackage: g.Config.Project.Package + ".domain.dto",
+	}
+	content, err := os.ReadFile(filepath.Join(g.ProjectDir, "openapi-generator.yaml"))
+	if os.IsNotExist(err) {
+		return opts, nil
+	}
+	if err != nil {
+		return opts, err
+	}
+
+	var generatorConfig struct {
+		InputSpec            string                 `yaml:"inputSpec"`
+		ApiPackage           string                 `yaml:"apiPackage"`
+		ModelPackage         string                 `yaml:"modelPackage"`
+		AdditionalProperties map[string]interface{} `yaml:"additionalProperties"`
+	}
+	if err := yaml.Unmarshal(content, &generatorConfig); err != nil {
+		return opts, fmt.Errorf("invalid openapi-generator.yaml: %v", err)
+	}
+	if generatorConfig.InputSpec != "" {
+		opts.Spec = generatorConfig.InputSpec
+	}
+	if generatorConfig.ApiPackage != "" {
+		opts.ApiPackage = generatorConfig.ApiPackage
+	}
+	if generatorConfig.ModelPackage != "" {
+		opts.ModelPackage = generatorConfig.ModelPackage
+	}
+	opts.Reactive = generatorConfig.AdditionalProperties["reactive"] == true
+	return opts, nil
+}
+
+// apiField is a record component or an operation parameter
+type apiField struct {
+	Name        string // Java name
+	Declaration string // Annotations, type and name
+	Description string
+}
+
+// apiModel is a record or enum generated from a schema
+type apiModel struct {
+	Name        string
+	Schema      *openapi.Schema
+	Enum        bool
+	ValueType   string      // Java type of the enum values
+	Constants   [][2]string // Enum constant names and Java literals
+	Fields      []apiField
+	Imports     *javaImportSet
+	Description string
+	Deprecated  bool
+}
+
+// apiOperation is a method of a controller interface
+type apiOperation struct {
+	Name        string
+	Method      string
+	Path        string
+	Summary     string
+	Doc         []string
+	Mapping     string
+	MappingArgs string
+	ReturnType  string
+	Params      []string
+	Deprecated  bool
+}
+
+// apiInterface is a controller interface grouping the operations of a tag
+type apiInterface struct {
+	Name        string
+	Tag         string
+	Description string
+	Operations  []*apiOperation
+	Imports     *javaImportSet
+	methodNames map[string]bool
+}
+
+// javaImportSet collects the imports of a Java file, leaving out classes of its own package
+type javaImportSet struct {
+	pkg     string
+	imports map[string]bool
+}
+
+func newJavaImportSet(pkg string) *javaImportSet {
+	return &javaImportSet{pkg: pkg, imports: map[string]bool{}}
+}
+
+func (s *javaImportSet) add(imports ...string) {
+	for _, imp := range imports {
+		if imp[:strings.LastIndex(imp, ".")] != s.pkg {
+			s.imports[imp] = true
+		}
+	}
+}
+
+// groups returns the sorted imports in two groups, as ordered in the repo's templates:
+// project and library imports first, then java and jakarta imports
+func (s *javaImportSet) groups() ([]string, []string) {
+	var libraries, java []string
+	for imp := range s.imports {
+		if strings.HasPrefix(imp, "java.") || strings.HasPrefix(imp, "jakarta.") {
+			java = append(java, imp)
+		} else {
+			libraries = append(libraries, imp)
+		}
+	}
+	sort.Strings(libraries)
+	sort.Strings(java)
+	return libraries, java
+}
+
+// apiBuilder maps the schemas and operations of a document to Java types
+type apiBuilder struct {
+	doc        *openapi.Document
+	opts       ApiOptions
+	models     []*apiModel
+	components map[string]*apiModel
+	classNames map[string]bool
+	paramEnums []*apiModel
+	warned     map[string]bool
+
+	// client names the @HttpExchange interface holding every operation when generating an
+	// HTTP client rather than controller interfaces
+	client string
+}
+
+// GenerateAPI writes a controller interface per tag and a record or enum per schema of
+// the OpenAPI document, replacing the files of previous runs. It returns the number of
+// interfaces and models written.
+func (g *ApiGenerator) GenerateAPI(opts ApiOptions) (int, int, error) {
+	doc, err := openapi.Load(filepath.Join(g.ProjectDir, opts.Spec))
+	if err != nil {
+		return 0, 0, err
+	}
+	b := &apiBuilder{
+		doc:        doc,
+		opts:       opts,
+		components: map[string]*apiModel{},
+		classNames: map[string]bool{},
+		warned:     map[string]bool{},
+	}
+
+	// Component schemas are generated even when no operation uses them
+	for _, name := range doc.Components.Schemas.Keys {
+		if _, _, err := b.componentType(name); err != nil {
+			return 0, 0, err
+		}
+	}
+	interfaces, err := b.interfaces()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if err := g.ensureValidation(); err != nil {
+		return 0, 0, err
+	}
+
+	apiDir := g.packageDir(opts.ApiPackage)
+	modelDir := g.packageDir(opts.ModelPackage)
+	w := g.newGeneratedWriter(apiGeneratedMarker, "api generate", opts.Spec)
+	write := w.write
+	if err := w.writeModels(b, modelDir); err != nil {
+		return 0, 0, err
+	}
+
+	for _, api := range interfaces {
+		imports, javaImports := api.Imports.groups()
+		if err := write("api/api.tmpl", apiDir, api.Name, map[string]interface{}{
+			"package":     opts.ApiPackage,
+			"imports":     imports,
+			"javaImports": javaImports,
+			"name":        api.Name,
+			"title":       doc.Info.Title,
+			"tag":         api.Tag,
+			"doc":         javadocLines(api.Description),
+			"operations":  api.Operations,
+		}); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	if len(b.paramEnums) > 0 {
+		imports := newJavaImportSet(opts.ApiPackage)
+		imports.add("org.springframework.context.annotation.Bean",
+			"org.springframework.context.annotation.Configuration",
+			"org.springframework.core.convert.converter.Converter")
+		var converters []map[string]string
+		for _, model := range b.paramEnums {
+			imports.add(opts.ModelPackage + "." + model.Name)
+			converters = append(converters, map[string]string{
+				"name":      model.Name,
+				"method":    util.ToJavaVariableName(model.Name) + "Converter",
+				"valueType": model.ValueType,
+			})
+		}
+		libraries, javaImports := imports.groups()
+		if err := write("api/enum_converters.tmpl", apiDir, "ApiEnumConverters", map[string]interface{}{
+			"package":     opts.ApiPackage,
+			"imports":     libraries,
+			"javaImports": javaImports,
+			"converters":  converters,
+		}); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	// Remove the files of schemas and tags that are no longer in the document
+	if err := w.removeStale(apiDir, modelDir); err != nil {
+		return 0, 0, err
+	}
+
+	// Interfaces without an implementation have no endpoints yet
+	for _, api := range interfaces {
+		implemented, err := sourcesContain(filepath.Join(g.ProjectDir, "src/main/java"), "implements "+api.Name)
+		if err != nil {
+			return 0, 0, err
+		}
+		if !implemented {
+			util.PrintInfo("Implement %s in a @RestController to serve its operations", api.Name)
+		}
+	}
+	return len(interfaces), len(b.models), nil
+}
+
+// ensureValidation adds Bean Validation, which the generated models and interfaces use
+func (g *ApiGenerator) ensureValidation() error {
+	pomPath := filepath.Join(g.ProjectDir, "pom.xml")
+	if !fileExists(pomPath) {
+		return nil
+	}
+	added, err := util.AddMavenDependency(pomPath, util.MavenDependency{
+		GroupID:    "org.springframework.boot",
+		ArtifactID: "spring-boot-starter-validation",
+	})
+	if err != nil {
+		return err
+	}
+	if added {
+		util.PrintInfo("Added Spring Boot validation dependency to pom.xml")
+	}
+	return nil
+}
+
+// packageDir returns the source directory of a package
+func (g *ApiGenerator) packageDir(pkg string) string {
+	return filepath.Join(g.ProjectDir, "src/main/java", strings.ReplaceAll(pkg, ".", "/"))
+}
+
+// generatedWriter writes the Java files generated from an OpenAPI document, starting them
+// with a marker so later runs only replace or remove files they wrote themselves
+type generatedWriter struct {
+	g       *ApiGenerator
+	marker  string
+	command string
+	spec    string
+	written map[string]bool
+}
+
+func (g *ApiGenerator) newGeneratedWriter(marker, command, spec string) *generatedWriter {
+	return &generatedWriter{g: g, marker: marker, command: command, spec: spec, written: map[string]bool{}}
+}
+
+// write renders a template to a Java file, unless the file exists without the marker
+func (w *generatedWriter) write(templatePath, dir, name string, data map[string]interface{}) error {
+	path := filepath.Join(dir, name+".java")
+	w.written[path] = true
+	if fileExists(path) && !hasMarker(path, w.marker) {
+		util.PrintWarning("Skipping %s, which exists and was not generated by %s", name+".java", w.command)
+		return nil
+	}
+	data["header"] = w.marker + " from " + filepath.ToSlash(w.spec) + ", do not edit."
+	return renderTemplate(w.g.Config, w.g.ProjectDir, templatePath, path, data)
+}
+
+// writeModels writes the records and enums of the schemas to a package directory
+func (w *generatedWriter) writeModels(b *apiBuilder, dir string) error {
+	for _, model := range b.models {
+		imports, javaImports := model.Imports.groups()
+		data := map[string]interface{}{
+			"package":     b.opts.ModelPackage,
+			"imports":     imports,
+			"javaImports": javaImports,
+			"name":        model.Name,
+			"doc":         javadocLines(model.Description),
+			"deprecated":  model.Deprecated,
+		}
+		templatePath := "api/model.tmpl"
+		if model.Enum {
+			templatePath = "api/enum.tmpl"
+			data["valueType"] = model.ValueType
+			data["constants"] = model.Constants
+			data["variable"] = util.ToJavaVariableName(model.Name)
+		} else {
+			data["fields"] = model.Fields
+		}
+		if err := w.write(templatePath, dir, model.Name, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeStale removes the files with the marker that this run did not write, as their
+// schemas or operations are no longer in the document
+func (w *generatedWriter) removeStale(dirs ...string) error {
+	for _, dir := range dirs {
+		paths, err := filepath.Glob(filepath.Join(dir, "*.java"))
+		if err != nil {
+			return err
+		}
+		for _, path := range paths {
+			if !w.written[path] && hasMarker(path, w.marker) {
+				util.PrintInfo("Removing %s, which is no longer in %s", filepath.Base(path), w.spec)
+				if err := os.Remove(path); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// hasMarker reports whether a file starts with a generation marker
+func hasMarker(path, marker string) bool {
+	content, err := os.ReadFile(path)
+	return err == nil && strings.HasPrefix(string(content), marker)
+}
+
+// interfaces groups the operations of the document into controller interfaces
+func (b *apiBuilder) interfaces() ([]*apiInterface, error) {
+	var interfaces []*apiInterface
+	byName := map[string]*apiInterface{}
+	basePath := b.basePath()
+	if b.client != "" {
+		// The base URL of the client includes the server path
+		basePath = ""
+	}
+
+	for _, path := range b.doc.Paths.Keys {
+		item := b.doc.Paths.Values[path]
+		for _, method := range openapi.Methods {
+			op := item.Operation(method)
+			if op == nil {
+				continue
+			}
+			tag := operationTag(path, op)
+			name := apiClassName(tag) + "Api"
+			if b.client != "" {
+				name = b.client
+			}
+			api, ok := byName[name]
+			if !ok {
+				api = &apiInterface{
+					Name:        name,
+					Tag:         tag,
+					Imports:     newJavaImportSet(b.opts.ApiPackage),
+					methodNames: map[string]bool{},
+				}
+				for _, t := range b.doc.Tags {
+					if t.Name == tag {
+						api.Description = t.Description
+					}
+				}
+				if b.client != "" {
+					api.Imports.add("org.springframework.web.bind.annotation.*",
+						"org.springframework.web.service.annotation.*")
+				} else {
+					api.Imports.add("org.springframework.http.ResponseEntity",
+						"org.springframework.validation.annotation.Validated",
+						"org.springframework.web.bind.annotation.*")
+				}
+				byName[name] = api
+				interfaces = append(interfaces, api)
+			}
+
+			operation, err := b.operation(api, basePath+path, method, item, op)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %v", strings.ToUpper(method), path, err)
+			}
+			api.Operations = append(api.Operations, operation)
+		}
+	}
+	return interfaces, nil
+}
+
+// basePath returns the path of the first server URL, which prefixes every operation
+func (b *apiBuilder) basePath() string {
+	if len(b.doc.Servers) == 0 {
+		return ""
+	}
+	u, err := url.Parse(b.doc.Servers[0].URL)
+	if err != nil || strings.Contains(u.Path, "{") {
+		return ""
+	}
+	return strings.TrimSuffix(u.Path, "/")
+}
+
+// operation builds the interface method of an operation
+func (b *apiBuilder) operation(api *apiInterface, path, method string, item *openapi.PathItem, op *openapi.Operation) (*apiOperation, error) {
+	name := operationMethodName(path, method, op)
+	for i := 2; api.methodNames[name]; i++ {
+		name = operationMethodName(path, method, op) + strconv.Itoa(i)
+	}
+	api.methodNames[name] = true
+
+	operation := &apiOperation{
+		Name:       name,
+		Method:     strings.ToUpper(method),
+		Path:       path,
+		Summary:    strings.TrimSpace(op.Summary),
+		Mapping:    capitalize(method) + "Mapping",
+		Deprecated: op.Deprecated,
+	}
+	consumes, produces := "consumes", "produces"
+	if b.client != "" {
+		operation.Mapping = capitalize(method) + "Exchange"
+		consumes, produces = "contentType", "accept"
+	}
+	if method == "head" || method == "options" {
+		operation.Mapping = "RequestMapping"
+		if b.client != "" {
+			operation.Mapping = "HttpExchange"
+		}
+	}
+	mappingArgs := []string{"value = " + javaString(path)}
+	switch operation.Mapping {
+	case "RequestMapping":
+		mappingArgs = append(mappingArgs, "method = RequestMethod."+strings.ToUpper(method))
+	case "HttpExchange":
+		mappingArgs = append(mappingArgs, "method = "+javaString(strings.ToUpper(method)))
+	}
+
+	doc := javadocLines(op.Description)
+	var paramDocs []string
+	usedNames := map[string]bool{}
+	paramName := func(name string) string {
+		javaName := javaVariableName(name)
+		for i := 2; usedNames[javaName]; i++ {
+			javaName = javaVariableName(name) + strconv.Itoa(i)
+		}
+		usedNames[javaName] = true
+		return javaName
+	}
+
+	params, err := b.doc.OperationParameters(item, op)
+	if err != nil {
+		return nil, err
+	}
+	for _, param := range params {
+		declaration, javaName, err := b.parameter(api, name, param, paramName)
+		if err != nil {
+			return nil, err
+		}
+		if declaration == "" {
+			continue
+		}
+		operation.Params = append(operation.Params, declaration)
+		paramDocs = append(paramDocs, "@param "+javaName+" "+docText(param.Description, param.Name+" "+param.In+" parameter"))
+	}
+
+	body, err := b.doc.ResolveRequestBody(op.RequestBody)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil && body.Content.Len() > 0 {
+		declarations, contentType, err := b.requestBody(api, name, body, paramName)
+		if err != nil {
+			return nil, err
+		}
+		mappingArgs = append(mappingArgs, consumes+" = "+javaString(contentType))
+		for _, declaration := range declarations {
+			operation.Params = append(operation.Params, declaration.Declaration)
+			paramDocs = append(paramDocs, "@param "+declaration.Name+" "+declaration.Description)
+		}
+	}
+
+	returnType, accept, err := b.responseType(api, name, op)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		mappingArgs = append(mappingArgs, produces+" = "+javaString(accept))
+	}
+	switch {
+	case b.client != "" && b.opts.Reactive:
+		// Clients return the body, failing with WebClientResponseException on errors
+		api.Imports.add("reactor.core.publisher.Mono")
+		returnType = "Mono<" + returnType + ">"
+	case b.client != "":
+		if returnType == "Void" {
+			returnType = "void"
+		}
+	case b.opts.Reactive:
+		api.Imports.add("reactor.core.publisher.Mono")
+		returnType = "Mono<ResponseEntity<" + returnType + ">>"
+	default:
+		returnType = "ResponseEntity<" + returnType + ">"
+	}
+	operation.ReturnType = returnType
+	operation.MappingArgs = strings.Join(mappingArgs, ", ")
+
+	returnDocs, err := b.responseDocs(op)
+	if err != nil {
+		return nil, err
+	}
+	if len(paramDocs)+len(returnDocs) > 0 && len(doc) > 0 {
+		doc = append(doc, "")
+	}
+	doc = append(doc, paramDocs...)
+	operation.Doc = append(doc, returnDocs...)
+	if len(operation.Doc) > 0 {
+		operation.Doc = append([]string{""}, operation.Doc...)
+	}
+	return operation, nil
+}
+
+// parameter returns the declaration of a path, query, header or cookie parameter
+func (b *apiBuilder) parameter(api *apiInterface, operationName string, param *openapi.Parameter, paramName func(string) string) (string, string, error) {
+	annotations := map[string]string{
+		"path":   "PathVariable",
+		"query":  "RequestParam",
+		"header": "RequestHeader",
+		"cookie": "CookieValue",
+	}
+	annotation, ok := annotations[param.In]
+	if !ok {
+		return "", "", fmt.Errorf("unsupported parameter location %q of %s", param.In, param.Name)
+	}
+	schema := param.Schema
+	if schema == nil {
+		schema = &openapi.Schema{Type: openapi.SchemaType{"string"}}
+	}
+	javaType, _, err := b.javaType(schema, operationName+apiClassName(param.Name), api.Imports)
+	if err != nil {
+		return "", "", err
+	}
+	b.registerParamEnum(schema)
+
+	resolved, err := b.doc.ResolveSchema(schema)
+	if err != nil {
+		return "", "", err
+	}
+	args := []string{javaString(param.Name)}
+	if param.In != "path" {
+		args = []string{"value = " + javaString(param.Name)}
+		if !param.Required {
+			args = append(args, "required = false")
+		}
+		if defaultValue := scalarString(resolved.Default); defaultValue != "" {
+			args = append(args, "defaultValue = "+javaString(defaultValue))
+		}
+	}
+	constraints := ""
+	if b.client == "" {
+		constraints = b.constraints(resolved, false, false, api.Imports)
+	}
+	javaName := paramName(param.Name)
+	return "@" + annotation + "(" + strings.Join(args, ", ") + ") " + isoFormat(javaType, api.Imports) + constraints + javaType + " " + javaName, javaName, nil
+}
+
+// isoFormat returns the @DateTimeFormat annotation binding a temporal parameter, or the
+// elements of a list of them, as ISO 8601 rather than in the locale's style, followed by
+// a space
+func isoFormat(javaType string, imports *javaImportSet) string {
+	element := strings.TrimSuffix(strings.TrimPrefix(javaType, "List<"), ">")
+	format := dateTimeFormat(map[string]string{"type": element})
+	if format != "" {
+		imports.add("org.springframework.format.annotation.DateTimeFormat")
+	}
+	return format
+}
+
+// registerParamEnum records enums used as parameters, which need a converter from their
+// values to their constants
+func (b *apiBuilder) registerParamEnum(schema *openapi.Schema) {
+	resolved, err := b.doc.ResolveSchema(schema)
+	if err == nil && resolved.TypeName() == "array" && resolved.Items != nil {
+		resolved, err = b.doc.ResolveSchema(resolved.Items)
+	}
+	if err != nil {
+		return
+	}
+	for _, model := range b.models {
+		if model.Schema != resolved || !model.Enum {
+			continue
+		}
+		for _, existing := range b.paramEnums {
+			if existing == model {
+				return
+			}
+		}
+		b.paramEnums = append(b.paramEnums, model)
+	}
+}
+
+// requestBody returns the parameters of a request body and the content type it consumes
+func (b *apiBuilder) requestBody(api *apiInterface, operationName string, body *openapi.RequestBody, paramName func(string) string) ([]apiField, string, error) {
+	contentType, media := openapi.JSONContent(body.Content)
+	if media != nil {
+		schema := media.Schema
+		if schema == nil {
+			schema = &openapi.Schema{}
+		}
+		javaType, cascade, err := b.javaType(schema, operationName+"Request", api.Imports)
+		if err != nil {
+			return nil, "", err
+		}
+		name := "body"
+		for _, model := range b.models {
+			if model.Name == javaType && !model.Enum {
+				name = util.ToJavaVariableName(javaType)
+			}
+		}
+		name = paramName(name)
+		args := ""
+		if !body.Required {
+			args = "(required = false)"
+		}
+		declaration := "@RequestBody" + args + " " + javaType + " " + name
+		if cascade && b.client == "" {
+			api.Imports.add("jakarta.validation.Valid")
+			declaration = "@Valid " + declaration
+		}
+		return []apiField{{Name: name, Declaration: declaration, Description: docText(body.Description, "the request body")}}, contentType, nil
+	}
+
+	contentType = body.Content.Keys[0]
+	media = body.Content.Values[contentType]
+	if strings.HasPrefix(contentType, "multipart/") || contentType == "application/x-www-form-urlencoded" {
+		return b.formParts(api, operationName, contentType, media, paramName)
+	}
+
+	// Other content types are bound as raw text or bytes
+	javaType := "byte[]"
+	if strings.HasPrefix(contentType, "text/") {
+		javaType = "String"
+	}
+	name := paramName("body")
+	return []apiField{{Name: name, Declaration: "@RequestBody " + javaType + " " + name, Description: docText(body.Description, "the request body")}}, contentType, nil
+}
+
+// formParts returns a parameter per property of a form or multipart request body
+func (b *apiBuilder) formParts(api *apiInterface, operationName, contentType string, media *openapi.MediaType, paramName func(string) string) ([]apiField, string, error) {
+	if media == nil || media.Schema == nil {
+		return nil, contentType, nil
+	}
+	schema, err := b.doc.ResolveSchema(media.Schema)
+	if err != nil {
+		return nil, "", err
+	}
+	multipart := strings.HasPrefix(contentType, "multipart/")
+	var fields []apiField
+	for _, property := range schema.Properties.Keys {
+		propertySchema := schema.Properties.Values[property]
+		resolved, err := b.doc.ResolveSchema(propertySchema)
+		if err != nil {
+			return nil, "", err
+		}
+		annotation := "RequestParam"
+		var javaType string
+		switch {
+		case multipart && isBinarySchema(resolved):
+			annotation = "RequestPart"
+			javaType = b.filePartType(api.Imports)
+		case multipart && resolved.TypeName() == "array" && resolved.Items != nil && isBinarySchema(resolved.Items):
+			annotation = "RequestPart"
+			api.Imports.add("java.util.List")
+			javaType = "List<" + b.filePartType(api.Imports) + ">"
+		default:
+			if multipart {
+				annotation = "RequestPart"
+			}
+			javaType, _, err = b.javaType(propertySchema, operationName+apiClassName(property), api.Imports)
+			if err != nil {
+				return nil, "", err
+			}
+		}
+		args := "value = " + javaString(property)
+		if !schema.IsRequired(property) {
+			args += ", required = false"
+		}
+		name := paramName(property)
+		constraints := ""
+		if b.client == "" {
+			constraints = b.constraints(resolved, false, false, api.Imports)
+		}
+		fields = append(fields, apiField{
+			Name:        name,
+			Declaration: "@" + annotation + "(" + args + ") " + isoFormat(javaType, api.Imports) + constraints + javaType + " " + name,
+			Description: docText(resolved.Description, property+" part of the request"),
+		})
+	}
+	return fields, contentType, nil
+}
+
+// filePartType returns the type of an uploaded file in a multipart request
+func (b *apiBuilder) filePartType(imports *javaImportSet) string {
+	if b.client != "" {
+		imports.add("org.springframework.core.io.Resource")
+		return "Resource"
+	}
+	if b.opts.Reactive {
+		imports.add("org.springframework.http.codec.multipart.FilePart")
+		return "FilePart"
+	}
+	imports.add("org.springframework.web.multipart.MultipartFile")
+	return "MultipartFile"
+}
+
+// responseType returns the body type of the successful response of an operation and the
+// content type it produces
+func (b *apiBuilder) responseType(api *apiInterface, operationName string, op *openapi.Operation) (string, string, error) {
+	_, response, err := b.doc.SuccessResponse(op)
+	if err != nil || response == nil || response.Content.Len() == 0 {
+		return "Void", "", err
+	}
+	contentType, media := openapi.JSONContent(response.Content)
+	if media == nil {
+		contentType = response.Content.Keys[0]
+		if strings.HasPrefix(contentType, "text/") {
+			return "String", contentType, nil
+		}
+		api.Imports.add("org.springframework.core.io.Resource")
+		return "Resource", contentType, nil
+	}
+	if media.Schema == nil {
+		return "Object", contentType, nil
+	}
+	javaType, _, err := b.javaType(media.Schema, operationName+"Response", api.Imports)
+	return javaType, contentType, err
+}
+
+// responseDocs returns the @return lines describing the responses of an operation
+func (b *apiBuilder) responseDocs(op *openapi.Operation) ([]string, error) {
+	var lines []string
+	for i, code := range op.Responses.Keys {
+		response, err := b.doc.ResolveResponse(op.Responses.Values[code])
+		if err != nil {
+			return nil, err
+		}
+		description := docText(strings.SplitN(strings.TrimSpace(response.Description), "\n", 2)[0], "Response")
+		status := "(status code " + code + ")"
+		if code == "default" {
+			status = "(default response)"
+		}
+		if i == 0 {
+			lines = append(lines, "@return "+description+" "+status)
+		} else {
+			lines = append(lines, "        or "+description+" "+status)
+		}
+	}
+	return lines, nil
+}
+
+// javaType returns the Java type of a schema, generating models for the objects and
+// enums it contains, and whether values of the type hold records to validate
+func (b *apiBuilder) javaType(schema *openapi.Schema, context string, imports *javaImportSet) (string, bool, error) {
+	if name := openapi.SchemaName(schema); name != "" {
+		if schema.Ref != "#/components/schemas/"+name {
+			if _, err := b.doc.ResolveSchema(schema); err != nil {
+				return "", false, err
+			}
+		}
+		className, cascade, err := b.componentType(name)
+		if err != nil {
+			return "", false, err
+		}
+		if model, ok := b.components[name]; ok {
+			imports.add(b.opts.ModelPackage + "." + model.Name)
+			return className, cascade, nil
+		}
+		javaType, cascade, err := b.javaType(b.doc.Components.Schemas.Values[name], name, imports)
+		return javaType, cascade, err
+	}
+
+	if schema.Boolean != nil {
+		return "Object", false, nil
+	}
+	if len(schema.Enum) > 0 || isObjectSchema(schema) {
+		model, err := b.inlineModel(context, schema)
+		if err != nil {
+			return "", false, err
+		}
+		imports.add(b.opts.ModelPackage + "." + model.Name)
+		return model.Name, !model.Enum, nil
+	}
+	if len(schema.OneOf) > 0 || len(schema.AnyOf) > 0 {
+		b.warn(context, "oneOf and anyOf are not supported, %s is generated as Object", context)
+		return "Object", false, nil
+	}
+
+	switch schema.TypeName() {
+	case "string":
+		switch schema.Format {
+		case "date":
+			imports.add("java.time.LocalDate")
+			return "LocalDate", false, nil
+		case "date-time":
+			imports.add("java.time.OffsetDateTime")
+			return "OffsetDateTime", false, nil
+		case "time":
+			imports.add("java.time.LocalTime")
+			return "LocalTime", false, nil
+		case "uuid":
+			imports.add("java.util.UUID")
+			return "UUID", false, nil
+		case "uri":
+			imports.add("java.net.URI")
+			return "URI", false, nil
+		case "byte", "binary":
+			return "byte[]", false, nil
+		}
+		return "String", false, nil
+	case "integer":
+		if schema.Format == "int64" {
+			return "Long", false, nil
+		}
+		return "Integer", false, nil
+	case "number":
+		switch schema.Format {
+		case "float":
+			return "Float", false, nil
+		case "double":
+			return "Double", false, nil
+		}
+		imports.add("java.math.BigDecimal")
+		return "BigDecimal", false, nil
+	case "boolean":
+		return "Boolean", false, nil
+	case "array":
+		items := schema.Items
+		if items == nil {
+			items = &openapi.Schema{}
+		}
+		itemType, cascade, err := b.javaType(items, context+"Item", imports)
+		if err != nil {
+			return "", false, err
+		}
+		// Constraints on the items apply to each element
+		if resolved, err := b.doc.ResolveSchema(items); err == nil && !cascade {
+			itemType = b.constraints(resolved, false, false, imports) + itemType
+		}
+		if schema.UniqueItems {
+			imports.add("java.util.Set")
+			return "Set<" + itemType + ">", cascade, nil
+		}
+		imports.add("java.util.List")
+		return "List<" + itemType + ">", cascade, nil
+	case "object":
+		imports.add("java.util.Map")
+		values := schema.AdditionalProperties
+		if values == nil || values.Boolean != nil {
+			return "Map<String, Object>", false, nil
+		}
+		valueType, cascade, err := b.javaType(values, context+"Value", imports)
+		if err != nil {
+			return "", false, err
+		}
+		return "Map<String, " + valueType + ">", cascade, nil
+	}
+	return "Object", false, nil
+}
+
+// componentType returns the class of a component schema, generating its model if it is an
+// object or enum; other components are aliases of the type they describe
+func (b *apiBuilder) componentType(name string) (string, bool, error) {
+	if model, ok := b.components[name]; ok {
+		return model.Name, !model.Enum, nil
+	}
+	schema, ok := b.doc.Components.Schemas.Get(name)
+	if !ok {
+		return "", false, fmt.Errorf("schema #/components/schemas/%s not found", name)
+	}
+	if schema.Ref != "" {
+		target := openapi.SchemaName(schema)
+		if target == name {
+			return "", false, fmt.Errorf("schema %s refers to itself", name)
+		}
+		return b.componentType(target)
+	}
+	if len(schema.Enum) == 0 && !isObjectSchema(schema) {
+		imports := newJavaImportSet(b.opts.ModelPackage)
+		javaType, cascade, err := b.javaType(schema, name, imports)
+		return javaType, cascade, err
+	}
+
+	model := &apiModel{Name: b.uniqueClassName(apiClassName(name)), Schema: schema}
+	b.components[name] = model
+	if err := b.buildModel(model); err != nil {
+		return "", false, fmt.Errorf("schema %s: %v", name, err)
+	}
+	return model.Name, !model.Enum, nil
+}
+
+// inlineModel generates the model of an object or enum schema declared in place
+func (b *apiBuilder) inlineModel(context string, schema *openapi.Schema) (*apiModel, error) {
+	for _, model := range b.models {
+		if model.Schema == schema {
+			return model, nil
+		}
+	}
+	model := &apiModel{Name: b.uniqueClassName(apiClassName(context)), Schema: schema}
+	if err := b.buildModel(model); err != nil {
+		return nil, fmt.Errorf("%s: %v", context, err)
+	}
+	return model, nil
+}
+
+// buildModel fills in the fields or constants of a model
+func (b *apiBuilder) buildModel(model *apiModel) error {
+	schema := model.Schema
+	model.Imports = newJavaImportSet(b.opts.ModelPackage)
+	model.Description = strings.TrimSpace(schema.Description)
+	if model.Description == "" {
+		model.Description = strings.TrimSpace(schema.Title)
+	}
+	model.Deprecated = schema.Deprecated
+	b.models = append(b.models, model)
+
+	if len(schema.Enum) > 0 {
+		return b.buildEnum(model)
+	}
+
+	model.Imports.add("com.fasterxml.jackson.annotation.JsonProperty")
+	properties, required, err := b.objectProperties(schema, 0)
+	if err != nil {
+		return err
+	}
+	usedNames := map[string]bool{}
+	var paramDocs []string
+	for _, property := range properties.Keys {
+		propertySchema := properties.Values[property]
+		javaType, cascade, err := b.javaType(propertySchema, model.Name+apiClassName(property), model.Imports)
+		if err != nil {
+			return err
+		}
+		resolved, err := b.doc.ResolveSchema(propertySchema)
+		if err != nil {
+			return err
+		}
+		name := javaVariableName(property)
+		for i := 2; usedNames[name]; i++ {
+			name = javaVariableName(property) + strconv.Itoa(i)
+		}
+		usedNames[name] = true
+
+		isRequired := required[property] && !resolved.ReadOnly && !propertySchema.IsNullable() && !resolved.IsNullable()
+		annotations := "@JsonProperty(" + javaString(property) + ") " + b.constraints(resolved, isRequired, cascade, model.Imports)
+		if resolved.Deprecated || propertySchema.Deprecated {
+			annotations = "@Deprecated " + annotations
+		}
+		description := strings.TrimSpace(propertySchema.Description)
+		if description == "" {
+			description = strings.TrimSpace(resolved.Description)
+		}
+		model.Fields = append(model.Fields, apiField{Name: name, Declaration: annotations + javaType + " " + name, Description: description})
+		if description != "" {
+			paramDocs = append(paramDocs, "@param "+name+" "+strings.Join(javadocLines(description), " "))
+		}
+	}
+	if len(properties.Keys) == 0 {
+		model.Imports = newJavaImportSet(b.opts.ModelPackage)
+	}
+	if len(paramDocs) > 0 {
+		if model.Description != "" {
+			model.Description += "\n\n"
+		}
+		model.Description += strings.Join(paramDocs, "\n")
+	}
+	return nil
+}
+
+// objectProperties returns the properties of an object schema and the required ones,
+// merging the schemas it is composed of with allOf
+func (b *apiBuilder) objectProperties(schema *openapi.Schema, depth int) (openapi.OrderedMap[*openapi.Schema], map[string]bool, error) {
+	properties := openapi.OrderedMap[*openapi.Schema]{Values: map[string]*openapi.Schema{}}
+	required := map[string]bool{}
+	if depth > 16 {
+		return properties, required, fmt.Errorf("allOf nested too deeply")
+	}
+	merge := func(s *openapi.Schema) error {
+		resolved, err := b.doc.ResolveSchema(s)
+		if err != nil {
+			return err
+		}
+		if len(resolved.OneOf) > 0 || len(resolved.AnyOf) > 0 {
+			b.warn(fmt.Sprintf("%p", resolved), "oneOf and anyOf inside allOf are not supported, their properties are left out")
+		}
+		parts, partRequired, err := b.objectProperties(resolved, depth+1)
+		if err != nil {
+			return err
+		}
+		for _, name := range parts.Keys {
+			if _, ok := properties.Values[name]; !ok {
+				properties.Keys = append(properties.Keys, name)
+			}
+			properties.Values[name] = parts.Values[name]
+		}
+		for name := range partRequired {
+			required[name] = true
+		}
+		return nil
+	}
+	for _, part := range schema.AllOf {
+		if err := merge(part); err != nil {
+			return properties, required, err
+		}
+	}
+	for _, name := range schema.Properties.Keys {
+		if _, ok := properties.Values[name]; !ok {
+			properties.Keys = append(properties.Keys, name)
+		}
+		properties.Values[name] = schema.Properties.Values[name]
+	}
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+	return properties, required, nil
+}
+
+// buildEnum fills in the constants of an enum model
+func (b *apiBuilder) buildEnum(model *apiModel) error {
+	model.Enum = true
+	model.Imports.add("com.fasterxml.jackson.annotation.JsonCreator", "com.fasterxml.jackson.annotation.JsonValue")
+	model.ValueType = "String"
+	if model.Schema.TypeName() == "integer" {
+		model.ValueType = "Integer"
+	}
+	used := map[string]bool{}
+	for _, value := range model.Schema.Enum {
+		if value == nil {
+			continue
+		}
+		text := fmt.Sprint(value)
+		literal := javaString(text)
+		if model.ValueType == "Integer" {
+			if _, err := strconv.Atoi(text); err != nil {
+				return fmt.Errorf("enum value %q is not an integer", text)
+			}
+			literal = text
+		}
+		name := enumConstantName(text)
+		for i := 2; used[name]; i++ {
+			name = enumConstantName(text) + "_" + strconv.Itoa(i)
+		}
+		used[name] = true
+		model.Constants = append(model.Constants, [2]string{name, literal})
+	}
+	if len(model.Constants) == 0 {
+		return fmt.Errorf("enum has no values")
+	}
+	return nil
+}
+
+// constraints returns the Bean Validation annotations of a value, each followed by a space
+func (b *apiBuilder) constraints(schema *openapi.Schema, required, cascade bool, imports *javaImportSet) string {
+	var annotations []string
+	add := func(annotation, class string) {
+		annotations = append(annotations, annotation)
+		imports.add(class)
+	}
+	if required {
+		add("@NotNull", "jakarta.validation.constraints.NotNull")
+	}
+	if cascade {
+		add("@Valid", "jakarta.validation.Valid")
+	}
+
+	switch schema.TypeName() {
+	case "string":
+		if schema.Format == "email" {
+			add("@Email", "jakarta.validation.constraints.Email")
+		}
+		if size := sizeArgs(schema.MinLength, schema.MaxLength); size != "" {
+			add("@Size("+size+")", "jakarta.validation.constraints.Size")
+		}
+		if schema.Pattern != "" {
+			add("@Pattern(regexp = "+javaString(schema.Pattern)+")", "jakarta.validation.constraints.Pattern")
+		}
+	case "integer":
+		if minimum, exclusive := schema.MinimumBound(); minimum != nil {
+			value := int64(*minimum)
+			if exclusive {
+				value++
+			}
+			add(fmt.Sprintf("@Min(%dL)", value), "jakarta.validation.constraints.Min")
+		}
+		if maximum, exclusive := schema.MaximumBound(); maximum != nil {
+			value := int64(*maximum)
+			if exclusive {
+				value--
+			}
+			add(fmt.Sprintf("@Max(%dL)", value), "jakarta.validation.constraints.Max")
+		}
+	case "number":
+		if minimum, exclusive := schema.MinimumBound(); minimum != nil {
+			add(decimalBound("DecimalMin", *minimum, exclusive), "jakarta.validation.constraints.DecimalMin")
+		}
+		if maximum, exclusive := schema.MaximumBound(); maximum != nil {
+			add(decimalBound("DecimalMax", *maximum, exclusive), "jakarta.validation.constraints.DecimalMax")
+		}
+	case "array":
+		if size := sizeArgs(schema.MinItems, schema.MaxItems); size != "" {
+			add("@Size("+size+")", "jakarta.validation.constraints.Size")
+		}
+	}
+	if len(annotations) == 0 {
+		return ""
+	}
+	return strings.Join(annotations, " ") + " "
+}
+
+// uniqueClassName returns name, or name with a number if a model already has it
+func (b *apiBuilder) uniqueClassName(name string) string {
+	unique := name
+	for i := 2; b.classNames[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	b.classNames[unique] = true
+	return unique
+}
+
+// warn prints a warning once per key
+func (b *apiBuilder) warn(key, format string, args ...interface{}) {
+	if !b.warned[key] {
+		b.warned[key] = true
+		util.PrintWarning(format, args...)
+	}
+}
+
+// isObjectSchema reports whether a schema describes an object with declared properties
+func isObjectSchema(schema *openapi.Schema) bool {
+	return schema.Properties.Len() > 0 || len(schema.AllOf) > 0
+}
+
+// isBinarySchema reports whether a schema describes file content
+func isBinarySchema(schema *openapi.Schema) bool {
+	return schema.TypeName() == "string" && (schema.Format == "binary" || schema.Format == "byte")
+}
+
+// operationTag returns the tag grouping an operation: its first tag, or the first path
+// segment that is neither a version nor a parameter
+func operationTag(path string, op *openapi.Operation) string {
+	if len(op.Tags) > 0 {
+		return op.Tags[0]
+	}
+	for _, segment := range strings.Split(path, "/") {
+		if segment != "" && !strings.HasPrefix(segment, "{") && !versionSegmentPattern.MatchString(segment) && segment != "api" {
+			return segment
+		}
+	}
+	return "default"
+}
+
+// operationMethodName returns the Java method name of an operation: its operationId, or
+// the method and path, such as getUsersById for GET /users/{id}
+func operationMethodName(path, method string, op *openapi.Operation) string {
+	if op.OperationID != "" {
+		return javaVariableName(op.OperationID)
+	}
+	name := method
+	for _, segment := range strings.Split(path, "/") {
+		switch {
+		case segment == "" || versionSegmentPattern.MatchString(segment) || segment == "api":
+		case strings.HasPrefix(segment, "{"):
+			name += "By" + apiClassName(strings.Trim(segment, "{}"))
+		default:
+			name += apiClassName(segment)
+		}
+	}
+	return name
+}
+
+// apiClassName converts a schema, tag or property name to a Java class name, keeping
+// names that already are one
+func apiClassName(name string) string {
+	if javaClassNamePattern.MatchString(name) {
+		return name
+	}
+	className := util.ToJavaClassName(nonIdentifierPattern.ReplaceAllString(name, " "))
+	if className == "" || className[0] < 'A' || className[0] > 'Z' {
+		className = "Model" + className
+	}
+	return className
+}
+
+// javaVariableName converts a property or parameter name to a Java variable name
+func javaVariableName(name string) string {
+	variable := name
+	if !javaVariableNamePattern.MatchString(name) {
+		variable = util.ToJavaVariableName(nonIdentifierPattern.ReplaceAllString(name, " "))
+	}
+	if variable == "" || (variable[0] >= '0' && variable[0] <= '9') {
+		variable = "value" + capitalize(variable)
+	}
+	if javaReservedWords[variable] {
+		variable += "Value"
+	}
+	return variable
+}
+
+// enumConstantName converts an enum value to a constant name, such as IN_PROGRESS for
+// in-progress or inProgress
+func enumConstantName(value string) string {
+	name := strings.ToUpper(util.ToDatabaseTableName(nonIdentifierPattern.ReplaceAllString(value, " ")))
+	if name == "" {
+		return "EMPTY"
+	}
+	if name[0] >= '0' && name[0] <= '9' {
+		name = "VALUE_" + name
+	}
+	return name
+}
+
+// sizeArgs returns the arguments of a @Size annotation, or "" without bounds
+func sizeArgs(minimum, maximum *int) string {
+	var args []string
+	if minimum != nil && *minimum > 0 {
+		args = append(args, fmt.Sprintf("min = %d", *minimum))
+	}
+	if maximum != nil {
+		args = append(args, fmt.Sprintf("max = %d", *maximum))
+	}
+	return strings.Join(args, ", ")
+}
+
+// decimalBound returns a @DecimalMin or @DecimalMax annotation
+func decimalBound(annotation string, value float64, exclusive bool) string {
+	bound := javaString(strconv.FormatFloat(value, 'f', -1, 64))
+	if exclusive {
+		return "@" + annotation + "(value = " + bound + ", inclusive = false)"
+	}
+	return "@" + annotation + "(" + bound + ")"
+}
+
+// scalarString returns a scalar value as text, or "" for other values
+func scalarString(value interface{}) string {
+	switch value.(type) {
+	case string, int, int64, float64, bool:
+		return fmt.Sprint(value)
+	}
+	return ""
+}
+
+// javaString returns a Java string literal
+func javaString(value string) string {
+	var b strings.Builder
+	b.WriteString(`"`)
+	for _, r := range value {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteString(`\` + string(r))
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r < 0x20:
+			fmt.Fprintf(&b, `\u%04x`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteString(`"`)
+	return b.String()
+}
+
+// javadocLines splits a description into Javadoc lines, escaping comment terminators
+func javadocLines(text string) []string {
+	text = strings.TrimSpace(strings.ReplaceAll(text, "*/", "*&#47;"))
+	if text == "" {
+		return nil
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return lines
+}
+
+// docText returns a single-line description for a Javadoc tag, or fallback if it is empty
+func docText(description, fallback string) string {
+	lines := javadocLines(description)
+	if len(lines) == 0 {
+		return fallback
+	}
+	return strings.Join(lines, " ")
+}
@@ -0,0 +1,84 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/springwell/cli/pkg/config"
+)
+
+const dateParamSpec = `openapi: 3.0.3
+info:
+  title: Launches
+  version: 1.0.0
+paths:
+  /launches:
+    get:
+      tags: [launches]
+      operationId: listLaunches
+      parameters:
+        - name: launch
+          in: query
+          required: true
+          schema:
+            type: string
+            format: date
+        - name: after
+          in: query
+          schema:
+            type: string
+            format: date-time
+      responses:
+        "200":
+          description: The launches
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  type: string
+`
+
+// writeSpec writes an OpenAPI document to a new project directory and returns the directory
+func writeSpec(t *testing.T, spec string) string {
+	t.Helper()
+	projectDir := t.TempDir()
+	path := filepath.Join(projectDir, "src/main/resources/openapi/api.yaml")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(spec), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return projectDir
+}
+
+func TestGenerateAPIBindsDateParametersAsISO(t *testing.T) {
+	projectDir := writeSpec(t, dateParamSpec)
+	cfg := config.GetDefaultConfig()
+	g := NewApiGenerator(cfg, projectDir)
+	opts, err := g.DefaultOptions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := g.GenerateAPI(opts); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(g.packageDir(opts.ApiPackage), "LaunchesApi.java"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	source := string(content)
+	for _, want := range []string{
+		"import org.springframework.format.annotation.DateTimeFormat;",
+		`@RequestParam(value = "launch") @DateTimeFormat(iso = DateTimeFormat.ISO.DATE) LocalDate launch`,
+		`@RequestParam(value = "after", required = false) @DateTimeFormat(iso = DateTimeFormat.ISO.DATE_TIME) OffsetDateTime after`,
+	} {
+		if !strings.Contains(source, want) {
+			t.Errorf("LaunchesApi.java does not contain %q:\n%s", want, source)
+		}
+	}
+}
@@ -0,0 +1,711 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/springwell/cli/pkg/config"
+	"github.com/springwell/cli/pkg/util"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// Schema format of Avro payloads in AsyncAPI messages
+	avroSchemaFormat = "application/vnd.apache.avro;version=1.9.0"
+	// Versions of the AsyncAPI Kafka and SQS bindings
+	kafkaBindingVersion = "0.5.0"
+	sqsBindingVersion   = "0.2.0"
+)
+
+var (
+	kafkaTopicPropertyPattern = regexp.MustCompile(`"\$\{kafka\.topics\.([\w.-]+?)\.name(?::[^}]*)?\}"`)
+	kafkaListenerTopicPattern = regexp.MustCompile(`@KafkaListener\([^)]*?topics\s*=\s*"([^"$]+)"`)
+	sqsQueuePropertyPattern   = regexp.MustCompile(`"\$\{aws\.sqs\.queues\.([\w.-]+?)\.url(?::[^}]*)?\}"`)
+	payloadImportPattern      = regexp.MustCompile(`import\s+[\w.]+\.messaging\.payload\.(\w+);`)
+	javaGenericPattern        = regexp.MustCompile(`^([\w.]+)<(.+)>$`)
+)
+
+// javaSchemaTypes maps Java types to JSON Schema types and formats
+var javaSchemaTypes = map[string][2]string{
+	"String":         {"string", ""},
+	"UUID":           {"string", "uuid"},
+	"int":            {"integer", "int32"},
+	"Integer":        {"integer", "int32"},
+	"long":           {"integer", "int64"},
+	"Long":           {"integer", "int64"},
+	"short":          {"integer", "int32"},
+	"Short":          {"integer", "int32"},
+	"float":          {"number", "float"},
+	"Float":          {"number", "float"},
+	"double":         {"number", "double"},
+	"Double":         {"number", "double"},
+	"BigDecimal":     {"number", ""},
+	"BigInteger":     {"integer", ""},
+	"boolean":        {"boolean", ""},
+	"Boolean":        {"boolean", ""},
+	"LocalDate":      {"string", "date"},
+	"LocalTime":      {"string", "time"},
+	"LocalDateTime":  {"string", "date-time"},
+	"Instant":        {"string", "date-time"},
+	"OffsetDateTime": {"string", "date-time"},
+	"ZonedDateTime":  {"string", "date-time"},
+}
+
+// MessagingEndpoint is a consumer or producer found in the messaging package
+type MessagingEndpoint struct {
+	Class       string // Name of the consumer or producer class
+	Action      string // send or receive
+	Broker      string // sqs or kafka
+	Destination string // Name of the queue or topic
+	Key         string // Queue or topic key in the application configuration
+	Payload     string // Name of the payload class
+	Avro        bool   // Whether the payload is serialized with Avro
+}
+
+// AsyncAPIGenerator generates an AsyncAPI document from the project's consumers and producers
+type AsyncAPIGenerator struct {
+	Config     *config.Config
+	ProjectDir string
+}
+
+// NewAsyncAPIGenerator creates a new AsyncAPIGenerator
+func NewAsyncAPIGenerator(config *config.Config, projectDir string) *AsyncAPIGenerator {
+	return &AsyncAPIGenerator{
+		Config:     config,
+		ProjectDir: projectDir,
+	}
+}
+
+// ScanMessaging finds the consumers and producers in the messaging package, as written
+// by `generate consumer` and `generate producer`
+func (g *AsyncAPIGenerator) ScanMessaging() ([]MessagingEndpoint, error) {
+	properties, err := applicationProperties(g.ProjectDir)
+	if err != nil {
+		return nil, err
+	}
+	paths, err := filepath.Glob(filepath.Join(basePackageDir(g.Config, g.ProjectDir), "messaging", "*.java"))
+	if err != nil {
+		return nil, err
+	}
+
+	var endpoints []MessagingEndpoint
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		source := string(content)
+		endpoint := MessagingEndpoint{
+			Class: strings.TrimSuffix(filepath.Base(path), ".java"),
+			Avro:  strings.Contains(source, "KafkaAvro"),
+		}
+
+		switch {
+		case kafkaTopicPropertyPattern.MatchString(source):
+			endpoint.Broker = "kafka"
+			endpoint.Key = kafkaTopicPropertyPattern.FindStringSubmatch(source)[1]
+			endpoint.Destination = endpoint.Key
+			if name, ok := properties["kafka.topics."+endpoint.Key+".name"]; ok {
+				endpoint.Destination = resolvePlaceholders(name, properties)
+			}
+		case kafkaListenerTopicPattern.MatchString(source):
+			endpoint.Broker = "kafka"
+			endpoint.Destination = kafkaListenerTopicPattern.FindStringSubmatch(source)[1]
+		case sqsQueuePropertyPattern.MatchString(source):
+			endpoint.Broker = "sqs"
+			endpoint.Key = sqsQueuePropertyPattern.FindStringSubmatch(source)[1]
+			endpoint.Destination = endpoint.Key
+		default:
+			// Processors and other helpers do not talk to a broker
+			continue
+		}
+
+		switch {
+		case strings.Contains(source, "@KafkaListener") || strings.Contains(source, ".receiveMessage("):
+			endpoint.Action = "receive"
+		case strings.Contains(source, "KafkaTemplate") || strings.Contains(source, ".sendMessage("):
+			endpoint.Action = "send"
+		default:
+			continue
+		}
+
+		match := payloadImportPattern.FindStringSubmatch(source)
+		if match == nil {
+			util.PrintWarning("Skipping %s, its payload is not a class in messaging.payload", endpoint.Class)
+			continue
+		}
+		endpoint.Payload = match[1]
+		endpoints = append(endpoints, endpoint)
+	}
+	return endpoints, nil
+}
+
+// GenerateAsyncAPI writes an AsyncAPI document describing the channels, messages and
+// broker bindings of the project's consumers and producers. The spec version is 3.0.0
+// or 2.6.0. It returns the number of consumers and producers described.
+func (g *AsyncAPIGenerator) GenerateAsyncAPI(outputPath, specVersion string) (int, error) {
+	switch specVersion {
+	case "", "3", "3.0", "3.0.0":
+		specVersion = "3.0.0"
+	case "2", "2.6", "2.6.0":
+		specVersion = "2.6.0"
+	default:
+		return 0, fmt.Errorf("unsupported AsyncAPI version: %s, use 3.0.0 or 2.6.0", specVersion)
+	}
+
+	endpoints, err := g.ScanMessaging()
+	if err != nil {
+		return 0, err
+	}
+	if len(endpoints) == 0 {
+		return 0, fmt.Errorf("no consumers or producers found in messaging/, generate them with `springwell generate consumer` or `springwell generate producer`")
+	}
+	properties, err := applicationProperties(g.ProjectDir)
+	if err != nil {
+		return 0, err
+	}
+	// Generated configuration falls back to the artifactId when spring.application.name is not set
+	if _, ok := properties["spring.application.name"]; !ok {
+		if project, err := util.ReadMavenProject(filepath.Join(g.ProjectDir, "pom.xml")); err == nil && project.ArtifactID != "" {
+			properties["spring.application.name"] = project.ArtifactID
+		}
+	}
+
+	doc := util.NewYAMLMap()
+	doc.Set("asyncapi", specVersion)
+	doc.Set("info", g.asyncAPIInfo())
+	doc.Set("defaultContentType", "application/json")
+	doc.Set("servers", g.asyncAPIServers(endpoints, properties, specVersion))
+
+	components := util.NewYAMLMap()
+	messages, schemas, err := g.asyncAPIMessages(endpoints, specVersion)
+	if err != nil {
+		return 0, err
+	}
+	if specVersion == "3.0.0" {
+		channels, operations := asyncAPIChannels(endpoints, properties)
+		doc.Set("channels", channels)
+		doc.Set("operations", operations)
+	} else {
+		doc.Set("channels", asyncAPIChannelsV2(endpoints, properties))
+	}
+	components.Set("messages", messages)
+	if schemas.Len() > 0 {
+		components.Set("schemas", schemas)
+	}
+	doc.Set("components", components)
+
+	content, err := util.EncodeYAML(doc)
+	if err != nil {
+		return 0, err
+	}
+	header := "# Generated by `springwell api asyncapi` from the consumers and producers in messaging/\n"
+	if err := util.WriteFile(outputPath, header+content); err != nil {
+		return 0, err
+	}
+	return len(endpoints), nil
+}
+
+// asyncAPIInfo describes the application from its pom.xml
+func (g *AsyncAPIGenerator) asyncAPIInfo() *util.YAMLMap {
+	title, version := projectInfo(g.ProjectDir)
+	info := util.NewYAMLMap()
+	info.Set("title", title+" messaging")
+	info.Set("version", version)
+	info.Set("description", "Messages sent and received by "+title)
+	return info
+}
+
+// projectInfo returns the name and version of the project from its pom.xml, falling back
+// to the name of its directory
+func projectInfo(projectDir string) (string, string) {
+	project, err := util.ReadMavenProject(filepath.Join(projectDir, "pom.xml"))
+	if err != nil {
+		project = util.MavenProject{}
+	}
+	title := project.Name
+	if title == "" {
+		title = project.ArtifactID
+	}
+	if title == "" {
+		if abs, err := filepath.Abs(projectDir); err == nil {
+			title = filepath.Base(abs)
+		}
+	}
+	version := project.Version
+	if version == "" {
+		version = "1.0.0"
+	}
+	return title, version
+}
+
+// asyncAPIServers describes the brokers the endpoints connect to
+func (g *AsyncAPIGenerator) asyncAPIServers(endpoints []MessagingEndpoint, properties map[string]string, specVersion string) *util.YAMLMap {
+	servers := util.NewYAMLMap()
+	for _, endpoint := range endpoints {
+		if _, ok := servers.Get(endpoint.Broker); ok {
+			continue
+		}
+		server := util.NewYAMLMap()
+		if endpoint.Broker == "kafka" {
+			host := resolvePlaceholders(properties["spring.kafka.bootstrap-servers"], properties)
+			if host == "" {
+				host = "localhost:9092"
+			}
+			if specVersion == "3.0.0" {
+				server.Set("host", host)
+			} else {
+				server.Set("url", host)
+			}
+			server.Set("protocol", "kafka")
+			server.Set("description", "Kafka broker, set with spring.kafka.bootstrap-servers")
+		} else {
+			region := resolvePlaceholders(properties["aws.region"], properties)
+			if region == "" {
+				region = g.Config.AWS.Region
+			}
+			if region == "" {
+				region = "us-east-1"
+			}
+			host := "sqs." + region + ".amazonaws.com"
+			if specVersion == "3.0.0" {
+				server.Set("host", host)
+			} else {
+				server.Set("url", "https://"+host)
+			}
+			server.Set("protocol", "sqs")
+			server.Set("description", "Amazon SQS in "+region)
+		}
+		servers.Set(endpoint.Broker, server)
+	}
+	return servers
+}
+
+// asyncAPIMessages returns the message and payload schema components of the endpoints
+func (g *AsyncAPIGenerator) asyncAPIMessages(endpoints []MessagingEndpoint, specVersion string) (*util.YAMLMap, *util.YAMLMap, error) {
+	messages := util.NewYAMLMap()
+	schemas := util.NewYAMLMap()
+	for _, endpoint := range endpoints {
+		if existing, ok := messages.Get(endpoint.Payload); ok {
+			// A payload sent to Kafka and SQS keeps its Kafka key binding
+			if endpoint.Broker == "kafka" {
+				existing.(*util.YAMLMap).Set("bindings", kafkaMessageBinding(endpoint))
+			}
+			continue
+		}
+
+		message := util.NewYAMLMap()
+		message.Set("name", endpoint.Payload)
+		if endpoint.Avro {
+			schema, err := g.avroSchema(endpoint.Payload)
+			if err != nil {
+				return nil, nil, err
+			}
+			message.Set("contentType", "application/vnd.apache.avro")
+			if specVersion == "3.0.0" {
+				message.Set("payload", util.NewYAMLMap().Set("schemaFormat", avroSchemaFormat).Set("schema", schema))
+			} else {
+				message.Set("schemaFormat", avroSchemaFormat)
+				message.Set("payload", schema)
+			}
+		} else {
+			ref, err := g.payloadSchema(endpoint.Payload, schemas)
+			if err != nil {
+				return nil, nil, err
+			}
+			message.Set("contentType", "application/json")
+			message.Set("payload", ref)
+		}
+		if endpoint.Broker == "kafka" {
+			message.Set("bindings", kafkaMessageBinding(endpoint))
+		}
+		messages.Set(endpoint.Payload, message)
+	}
+	return messages, schemas, nil
+}
+
+// asyncAPIChannels returns the AsyncAPI 3 channels and operations of the endpoints
+func asyncAPIChannels(endpoints []MessagingEndpoint, properties map[string]string) (*util.YAMLMap, *util.YAMLMap) {
+	channels := util.NewYAMLMap()
+	operations := util.NewYAMLMap()
+	for _, endpoint := range endpoints {
+		id := channelID(endpoint, endpoints)
+		value, ok := channels.Get(id)
+		if !ok {
+			channel := util.NewYAMLMap()
+			channel.Set("address", endpoint.Destination)
+			channel.Set("servers", []interface{}{util.NewYAMLMap().Set("$ref", "#/servers/"+endpoint.Broker)})
+			channel.Set("messages", util.NewYAMLMap())
+			channel.Set("bindings", channelBindings(endpoint, properties))
+			channels.Set(id, channel)
+			value = channel
+		}
+		channelMessages, _ := value.(*util.YAMLMap).Get("messages")
+		channelMessages.(*util.YAMLMap).Set(endpoint.Payload,
+			util.NewYAMLMap().Set("$ref", "#/components/messages/"+endpoint.Payload))
+
+		operation := util.NewYAMLMap()
+		operation.Set("action", endpoint.Action)
+		operation.Set("channel", util.NewYAMLMap().Set("$ref", "#/channels/"+id))
+		operation.Set("summary", operationSummary(endpoint))
+		operation.Set("messages", []interface{}{
+			util.NewYAMLMap().Set("$ref", "#/channels/"+id+"/messages/"+endpoint.Payload),
+		})
+		if bindings := operationBindings(endpoint, properties); bindings != nil {
+			operation.Set("bindings", bindings)
+		}
+		operations.Set(util.ToJavaVariableName(endpoint.Class), operation)
+	}
+	return channels, operations
+}
+
+// asyncAPIChannelsV2 returns the AsyncAPI 2 channels of the endpoints. Operations are
+// described from the point of view of other applications: the application receives
+// the messages they publish, and they subscribe to the messages it sends.
+func asyncAPIChannelsV2(endpoints []MessagingEndpoint, properties map[string]string) *util.YAMLMap {
+	channels := util.NewYAMLMap()
+	for _, endpoint := range endpoints {
+		// AsyncAPI 2 names channels after their address, a queue and a topic sharing a
+		// name are one channel on both servers
+		value, ok := channels.Get(endpoint.Destination)
+		if !ok {
+			channel := util.NewYAMLMap()
+			channel.Set("servers", []string{})
+			channel.Set("bindings", util.NewYAMLMap())
+			channels.Set(endpoint.Destination, channel)
+			value = channel
+		}
+		channel := value.(*util.YAMLMap)
+		servers, _ := channel.Get("servers")
+		if !containsString(servers.([]string), endpoint.Broker) {
+			channel.Set("servers", append(servers.([]string), endpoint.Broker))
+			bindings, _ := channel.Get("bindings")
+			binding, _ := channelBindings(endpoint, properties).Get(endpoint.Broker)
+			bindings.(*util.YAMLMap).Set(endpoint.Broker, binding)
+		}
+
+		operationName := "subscribe"
+		if endpoint.Action == "receive" {
+			operationName = "publish"
+		}
+		ref := util.NewYAMLMap().Set("$ref", "#/components/messages/"+endpoint.Payload)
+		if existing, ok := channel.Get(operationName); ok {
+			// A second consumer or producer on the channel adds its message to the operation
+			operation := existing.(*util.YAMLMap)
+			message, _ := operation.Get("message")
+			refs := []interface{}{message}
+			if oneOf, ok := message.(*util.YAMLMap).Get("oneOf"); ok {
+				refs = oneOf.([]interface{})
+			}
+			if !containsMessageRef(refs, endpoint.Payload) {
+				operation.Set("message", util.NewYAMLMap().Set("oneOf", append(refs, ref)))
+			}
+			continue
+		}
+
+		operation := util.NewYAMLMap()
+		operation.Set("operationId", util.ToJavaVariableName(endpoint.Class))
+		operation.Set("summary", operationSummary(endpoint))
+		if bindings := operationBindings(endpoint, properties); bindings != nil {
+			operation.Set("bindings", bindings)
+		}
+		operation.Set("message", ref)
+		channel.Set(operationName, operation)
+	}
+	return channels
+}
+
+// containsString reports whether a list includes a string
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// containsMessageRef reports whether a list of message references includes a payload
+func containsMessageRef(refs []interface{}, payload string) bool {
+	for _, ref := range refs {
+		if value, _ := ref.(*util.YAMLMap).Get("$ref"); value == "#/components/messages/"+payload {
+			return true
+		}
+	}
+	return false
+}
+
+// channelID names the channel of an endpoint after its queue or topic, prefixed with the
+// broker when a queue and a topic share the name
+func channelID(endpoint MessagingEndpoint, endpoints []MessagingEndpoint) string {
+	for _, other := range endpoints {
+		if other.Destination == endpoint.Destination && other.Broker != endpoint.Broker {
+			return endpoint.Broker + "-" + endpoint.Destination
+		}
+	}
+	return endpoint.Destination
+}
+
+// operationSummary describes what an endpoint does
+func operationSummary(endpoint MessagingEndpoint) string {
+	kind := "SQS queue"
+	if endpoint.Broker == "kafka" {
+		kind = "Kafka topic"
+	}
+	if endpoint.Action == "receive" {
+		return fmt.Sprintf("%s receives %s from the %s %s", endpoint.Class, endpoint.Payload, endpoint.Destination, kind)
+	}
+	return fmt.Sprintf("%s sends %s to the %s %s", endpoint.Class, endpoint.Payload, endpoint.Destination, kind)
+}
+
+// channelBindings returns the Kafka topic or SQS queue binding of a channel, with the
+// dead-letter queue or topic failed messages end up in
+func channelBindings(endpoint MessagingEndpoint, properties map[string]string) *util.YAMLMap {
+	if endpoint.Broker == "kafka" {
+		binding := util.NewYAMLMap()
+		binding.Set("topic", endpoint.Destination)
+		binding.Set("bindingVersion", kafkaBindingVersion)
+		return util.NewYAMLMap().Set("kafka", binding)
+	}
+
+	prefix := "aws.sqs.queues." + endpoint.Key + "."
+	deadLetterQueue := endpoint.Destination + "-dlq"
+	if url := resolvePlaceholders(properties[prefix+"dead-letter-queue-url"], properties); url != "" {
+		deadLetterQueue = url[strings.LastIndex(url, "/")+1:]
+	}
+
+	queue := util.NewYAMLMap()
+	queue.Set("name", endpoint.Destination)
+	queue.Set("fifoQueue", strings.HasSuffix(endpoint.Destination, ".fifo"))
+	if timeout, err := strconv.Atoi(properties[prefix+"visibility-timeout-seconds"]); err == nil {
+		queue.Set("visibilityTimeout", timeout)
+	}
+	if wait, err := strconv.Atoi(properties[prefix+"wait-time-seconds"]); err == nil {
+		queue.Set("receiveMessageWaitTime", wait)
+	}
+	redrivePolicy := util.NewYAMLMap().Set("deadLetterQueue", util.NewYAMLMap().Set("name", deadLetterQueue))
+	if count, err := strconv.Atoi(properties[prefix+"max-receive-count"]); err == nil {
+		redrivePolicy.Set("maxReceiveCount", count)
+	}
+	queue.Set("redrivePolicy", redrivePolicy)
+
+	binding := util.NewYAMLMap()
+	binding.Set("queue", queue)
+	binding.Set("deadLetterQueue", util.NewYAMLMap().Set("name", deadLetterQueue))
+	binding.Set("bindingVersion", sqsBindingVersion)
+	return util.NewYAMLMap().Set("sqs", binding)
+}
+
+// operationBindings returns the consumer group of a Kafka consumer, omitted when the
+// group is not known until runtime
+func operationBindings(endpoint MessagingEndpoint, properties map[string]string) *util.YAMLMap {
+	if endpoint.Broker != "kafka" || endpoint.Action != "receive" {
+		return nil
+	}
+	groupID := resolvePlaceholders(properties["spring.kafka.consumer.group-id"], properties)
+	if groupID == "" || strings.Contains(groupID, "${") {
+		return nil
+	}
+	binding := util.NewYAMLMap()
+	binding.Set("groupId", util.NewYAMLMap().Set("type", "string").Set("enum", []string{groupID}))
+	binding.Set("bindingVersion", kafkaBindingVersion)
+	return util.NewYAMLMap().Set("kafka", binding)
+}
+
+// kafkaMessageBinding returns the key of the records, and where Avro records carry the
+// ID of their schema
+func kafkaMessageBinding(endpoint MessagingEndpoint) *util.YAMLMap {
+	binding := util.NewYAMLMap()
+	binding.Set("key", util.NewYAMLMap().Set("type", "string"))
+	if endpoint.Avro {
+		binding.Set("schemaIdLocation", "payload")
+		binding.Set("schemaIdPayloadEncoding", "confluent")
+		binding.Set("schemaLookupStrategy", "TopicNameStrategy")
+	}
+	binding.Set("bindingVersion", kafkaBindingVersion)
+	return util.NewYAMLMap().Set("kafka", binding)
+}
+
+// avroSchema reads the Avro schema of a payload from src/main/avro
+func (g *AsyncAPIGenerator) avroSchema(payload string) (*yaml.Node, error) {
+	path := filepath.Join(g.ProjectDir, "src/main/avro", payload+".avsc")
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Avro schema of %s not found at %s", payload, filepath.Join("src/main/avro", payload+".avsc"))
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil || len(doc.Content) == 0 {
+		return nil, fmt.Errorf("invalid Avro schema %s: %v", path, err)
+	}
+	schema := doc.Content[0]
+	blockStyle(schema)
+	return schema, nil
+}
+
+// blockStyle drops the JSON flow style of a parsed node, so it is encoded like the rest
+// of the document
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+// payloadSchema adds the JSON Schema of a payload record, and of the payload classes it
+// references, to the schemas and returns a reference to it
+func (g *AsyncAPIGenerator) payloadSchema(payload string, schemas *util.YAMLMap) (*util.YAMLMap, error) {
+	ref := util.NewYAMLMap().Set("$ref", "#/components/schemas/"+payload)
+	if _, ok := schemas.Get(payload); ok {
+		return ref, nil
+	}
+	path := filepath.Join(basePackageDir(g.Config, g.ProjectDir), "messaging/payload", payload+".java")
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("payload %s not found at %s", payload, path)
+	}
+	components, err := recordComponents(string(content), payload)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := util.NewYAMLMap()
+	schema.Set("type", "object")
+	// Reserve the name before resolving the components, which may reference the payload
+	schemas.Set(payload, schema)
+	properties := util.NewYAMLMap()
+	var required []string
+	for _, component := range components {
+		property, err := g.javaTypeSchema(component[0], schemas)
+		if err != nil {
+			return nil, err
+		}
+		properties.Set(component[1], property)
+		if isPrimitive(component[0]) {
+			required = append(required, component[1])
+		}
+	}
+	schema.Set("properties", properties)
+	if len(required) > 0 {
+		schema.Set("required", required)
+	}
+	return ref, nil
+}
+
+// javaTypeSchema returns the JSON Schema of a Java type
+func (g *AsyncAPIGenerator) javaTypeSchema(javaType string, schemas *util.YAMLMap) (*util.YAMLMap, error) {
+	javaType = strings.TrimSpace(javaType)
+	if strings.HasSuffix(javaType, "[]") {
+		items, err := g.javaTypeSchema(strings.TrimSuffix(javaType, "[]"), schemas)
+		if err != nil {
+			return nil, err
+		}
+		return util.NewYAMLMap().Set("type", "array").Set("items", items), nil
+	}
+	if match := javaGenericPattern.FindStringSubmatch(javaType); match != nil {
+		arguments := splitTypeArguments(match[2])
+		switch match[1] {
+		case "List", "Set", "Collection":
+			items, err := g.javaTypeSchema(arguments[0], schemas)
+			if err != nil {
+				return nil, err
+			}
+			return util.NewYAMLMap().Set("type", "array").Set("items", items), nil
+		case "Map":
+			values, err := g.javaTypeSchema(arguments[len(arguments)-1], schemas)
+			if err != nil {
+				return nil, err
+			}
+			return util.NewYAMLMap().Set("type", "object").Set("additionalProperties", values), nil
+		}
+		return util.NewYAMLMap().Set("type", "object"), nil
+	}
+
+	javaType = javaType[strings.LastIndex(javaType, ".")+1:]
+	if schemaType, ok := javaSchemaTypes[javaType]; ok {
+		schema := util.NewYAMLMap().Set("type", schemaType[0])
+		if schemaType[1] != "" {
+			schema.Set("format", schemaType[1])
+		}
+		return schema, nil
+	}
+	if fileExists(filepath.Join(basePackageDir(g.Config, g.ProjectDir), "messaging/payload", javaType+".java")) {
+		return g.payloadSchema(javaType, schemas)
+	}
+	return util.NewYAMLMap().Set("type", "object"), nil
+}
+
+// recordComponent is a component of a Java record
+type recordComponent struct {
+	Type        string
+	Name        string
+	Annotations string // Annotations of the component, separated by spaces
+}
+
+// recordComponents returns the type and name of each component of a Java record
+func recordComponents(source, name string) ([][2]string, error) {
+	declarations, err := recordComponentDeclarations(source, name)
+	if err != nil {
+		return nil, err
+	}
+	components := make([][2]string, 0, len(declarations))
+	for _, component := range declarations {
+		components = append(components, [2]string{component.Type, component.Name})
+	}
+	return components, nil
+}
+
+// recordComponentDeclarations returns each component of a Java record with its annotations
+func recordComponentDeclarations(source, name string) ([]recordComponent, error) {
+	start := regexp.MustCompile(`record\s+` + regexp.QuoteMeta(name) + `\s*(?:<[^>]*>)?\s*\(`).FindStringIndex(source)
+	if start == nil {
+		return nil, fmt.Errorf("%s is not a record", name)
+	}
+	declaration := source[start[1] : start[1]+closingParen(source[start[1]:])]
+
+	var components []recordComponent
+	for _, component := range splitTypeArguments(declaration) {
+		match := javaAnnotationsPattern.FindStringSubmatch(strings.TrimSpace(component))
+		fields := strings.Fields(match[2])
+		if len(fields) < 2 {
+			continue
+		}
+		components = append(components, recordComponent{
+			Type:        strings.Join(fields[:len(fields)-1], ""),
+			Name:        fields[len(fields)-1],
+			Annotations: strings.TrimSpace(match[1]),
+		})
+	}
+	return components, nil
+}
+
+// splitTypeArguments splits a comma-separated list, ignoring commas between angle brackets,
+// between parentheses and in string literals
+func splitTypeArguments(list string) []string {
+	var parts []string
+	depth, start := 0, 0
+	quoted := false
+	for i, c := range list {
+		switch {
+		case c == '"' && (i == 0 || list[i-1] != '\\'):
+			quoted = !quoted
+		case quoted:
+		case c == '<' || c == '(':
+			depth++
+		case c == '>' || c == ')':
+			depth--
+		case c == ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(list[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if last := strings.TrimSpace(list[start:]); last != "" {
+		parts = append(parts, last)
+	}
+	return parts
+}
@@ -0,0 +1,343 @@
+package generator
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/springwell/cli/pkg/config"
+	"github.com/springwell/cli/pkg/openapi"
+	"github.com/springwell/cli/pkg/util"
+)
+
+// clientGeneratedMarker starts every file written by `generate client`
+const clientGeneratedMarker = "// Generated by springwell generate client"
+
+const wireMockVersion = "3.9.2"
+
+// ClientGenerator generates typed HTTP clients of other services from their OpenAPI documents
+type ClientGenerator struct {
+	Config     *config.Config
+	ProjectDir string
+}
+
+// NewClientGenerator creates a new ClientGenerator
+func NewClientGenerator(config *config.Config, projectDir string) *ClientGenerator {
+	return &ClientGenerator{
+		Config:     config,
+		ProjectDir: projectDir,
+	}
+}
+
+// ClientOptions controls what GenerateClient produces
+type ClientOptions struct {
+	Spec     string // Path of the service's OpenAPI document
+	Reactive bool   // Whether to call the service with WebClient and return Mono
+	BaseURL  string // Base URL of the service, the first server of the document when empty
+}
+
+// GenerateClient writes an @HttpExchange interface with the operations of a service, its
+// records and enums, a configuration creating the client with timeouts and retries, the
+// base URL properties and a WireMock test, all under the client.<name> package. It returns
+// the number of operations of the client.
+func (g *ClientGenerator) GenerateClient(name string, opts ClientOptions) (int, error) {
+	className := util.ToJavaClassName(name)
+	if className == "" {
+		return 0, fmt.Errorf("client name is required")
+	}
+	if !strings.HasSuffix(className, "Client") {
+		className += "Client"
+	}
+	serviceName := strings.TrimSuffix(className, "Client")
+	key := strings.ReplaceAll(util.ToDatabaseTableName(serviceName), "_", "-")
+	pkg := g.Config.Project.Package + ".client." + strings.ToLower(serviceName)
+
+	specPath := opts.Spec
+	if !filepath.IsAbs(specPath) {
+		specPath = filepath.Join(g.ProjectDir, specPath)
+	}
+	doc, err := openapi.Load(specPath)
+	if err != nil {
+		return 0, err
+	}
+	b := &apiBuilder{
+		doc: doc,
+		opts: ApiOptions{
+			Spec:         opts.Spec,
+			ApiPackage:   pkg,
+			ModelPackage: pkg + ".dto",
+			Reactive:     opts.Reactive,
+		},
+		components: map[string]*apiModel{},
+		classNames: map[string]bool{},
+		warned:     map[string]bool{},
+		client:     className,
+	}
+	for _, schema := range doc.Components.Schemas.Keys {
+		if _, _, err := b.componentType(schema); err != nil {
+			return 0, err
+		}
+	}
+	interfaces, err := b.interfaces()
+	if err != nil {
+		return 0, err
+	}
+	if len(interfaces) == 0 {
+		return 0, fmt.Errorf("%s has no operations", opts.Spec)
+	}
+	client := interfaces[0]
+
+	baseURL := opts.BaseURL
+	if baseURL == "" {
+		baseURL = "http://localhost:8080" + b.basePath()
+		if len(doc.Servers) > 0 {
+			if u, err := url.Parse(doc.Servers[0].URL); err == nil && u.IsAbs() {
+				baseURL = strings.TrimSuffix(doc.Servers[0].URL, "/")
+			}
+		}
+	}
+
+	if err := g.ensureDependencies(opts.Reactive); err != nil {
+		return 0, err
+	}
+
+	clientDir := filepath.Join(g.ProjectDir, "src/main/java", strings.ReplaceAll(pkg, ".", "/"))
+	modelDir := filepath.Join(clientDir, "dto")
+	w := (&ApiGenerator{Config: g.Config, ProjectDir: g.ProjectDir}).newGeneratedWriter(clientGeneratedMarker, "generate client", opts.Spec)
+	if err := w.writeModels(b, modelDir); err != nil {
+		return 0, err
+	}
+
+	imports, javaImports := client.Imports.groups()
+	data := map[string]interface{}{
+		"package":     pkg,
+		"imports":     imports,
+		"javaImports": javaImports,
+		"name":        className,
+		"variable":    util.ToJavaVariableName(className),
+		"title":       doc.Info.Title,
+		"version":     doc.Info.Version,
+		"key":         key,
+		"operations":  client.Operations,
+		"reactive":    opts.Reactive,
+	}
+	if err := w.write("client/client.tmpl", clientDir, className, data); err != nil {
+		return 0, err
+	}
+
+	var enums []string
+	for _, model := range b.paramEnums {
+		enums = append(enums, model.Name)
+	}
+	data["enums"] = enums
+	data["modelPackage"] = pkg + ".dto"
+	if err := w.write("client/client_config.tmpl", clientDir, className+"Config", data); err != nil {
+		return 0, err
+	}
+	if err := w.removeStale(clientDir, modelDir); err != nil {
+		return 0, err
+	}
+
+	testPath := filepath.Join(g.ProjectDir, "src/test/java", strings.ReplaceAll(pkg, ".", "/"), className+"Test.java")
+	if !fileExists(testPath) {
+		imports := newJavaImportSet(pkg)
+		imports.add("com.github.tomakehurst.wiremock.junit5.WireMockExtension",
+			"org.junit.jupiter.api.Test",
+			"org.junit.jupiter.api.extension.RegisterExtension",
+			"org.springframework.beans.factory.annotation.Autowired",
+			"org.springframework.boot.autoconfigure.ImportAutoConfiguration",
+			"org.springframework.boot.autoconfigure.jackson.JacksonAutoConfiguration",
+			"org.springframework.boot.test.context.SpringBootTest",
+			"org.springframework.test.context.DynamicPropertyRegistry",
+			"org.springframework.test.context.DynamicPropertySource")
+		if opts.Reactive {
+			imports.add("org.springframework.boot.autoconfigure.http.codec.CodecsAutoConfiguration",
+				"org.springframework.boot.autoconfigure.web.reactive.function.client.WebClientAutoConfiguration")
+		} else {
+			imports.add("org.springframework.boot.autoconfigure.http.HttpMessageConvertersAutoConfiguration",
+				"org.springframework.boot.autoconfigure.web.client.RestClientAutoConfiguration")
+		}
+		sample := clientSample(b, client)
+		if sample != nil {
+			imports.add("com.github.tomakehurst.wiremock.stubbing.Scenario")
+			imports.add(sample["imports"].([]string)...)
+		}
+		data["imports"], data["javaImports"] = imports.groups()
+		data["basePath"] = b.basePath()
+		data["sample"] = sample
+		if err := renderTemplate(g.Config, g.ProjectDir, "client/client_test.tmpl", testPath, data); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(client.Operations), addApplicationConfig(g.ProjectDir, fmt.Sprintf(`clients:
+  %s:
+    base-url: %s
+    connect-timeout: 5s
+    read-timeout: 30s
+    retry:
+      max-attempts: 3
+      backoff: 500ms
+`, key, baseURL))
+}
+
+// ensureDependencies adds Bean Validation for the records, WebFlux for reactive clients
+// and WireMock for the test
+func (g *ClientGenerator) ensureDependencies(reactive bool) error {
+	pomPath := filepath.Join(g.ProjectDir, "pom.xml")
+	if _, err := os.Stat(pomPath); err != nil {
+		util.PrintWarning("No pom.xml found, add org.wiremock:wiremock-standalone:%s to your test dependencies manually", wireMockVersion)
+		return nil
+	}
+	dependencies := []util.MavenDependency{
+		{GroupID: "org.springframework.boot", ArtifactID: "spring-boot-starter-validation"},
+		{GroupID: "org.wiremock", ArtifactID: "wiremock-standalone", Version: wireMockVersion, Scope: "test"},
+	}
+	if reactive {
+		dependencies = append(dependencies, util.MavenDependency{GroupID: "org.springframework.boot", ArtifactID: "spring-boot-starter-webflux"})
+	}
+	for _, dependency := range dependencies {
+		added, err := util.AddMavenDependency(pomPath, dependency)
+		if err != nil {
+			return err
+		}
+		if added {
+			util.PrintInfo("Added %s dependency to pom.xml", dependency.ArtifactID)
+		}
+	}
+	return nil
+}
+
+// clientSample returns the operation the generated test calls, with the arguments of its
+// parameters and the response WireMock stubs, or nil if no GET operation can be called
+// without a body
+func clientSample(b *apiBuilder, client *apiInterface) map[string]interface{} {
+	for _, operation := range client.Operations {
+		if operation.Method != "GET" {
+			continue
+		}
+		imports := newJavaImportSet("")
+		var args, queryMatchers []string
+		callable := true
+		for _, param := range operation.Params {
+			match := javaAnnotationsPattern.FindStringSubmatch(param)
+			declaration := strings.Fields(match[2])
+			javaType := strings.Join(declaration[:len(declaration)-1], " ")
+			arg := sampleArgument(b, javaType, imports)
+			if arg == "" {
+				if strings.Contains(match[1], "@PathVariable") || !strings.Contains(match[1], "required = false") {
+					callable = false
+					break
+				}
+				arg = "null"
+			}
+			args = append(args, arg)
+			// Temporal query parameters must reach the service in ISO 8601
+			if iso, ok := isoSamples[javaType]; ok {
+				if name := requestParamPattern.FindStringSubmatch(match[1]); name != nil {
+					queryMatchers = append(queryMatchers, ".withQueryParam("+javaString(name[1])+", equalTo("+javaString(iso)+"))")
+				}
+			}
+		}
+		returnType := operation.ReturnType
+		if strings.HasPrefix(returnType, "Mono<") {
+			returnType = strings.TrimSuffix(strings.TrimPrefix(returnType, "Mono<"), ">")
+			if returnType == "Void" {
+				returnType = "void"
+			}
+		}
+		stub, assertion := sampleResponse(b, returnType)
+		if !callable || stub == "" {
+			continue
+		}
+
+		pattern := regexp.QuoteMeta(b.basePath() + operation.Path)
+		pattern = regexp.MustCompile(`\\\{[^}]*\\\}`).ReplaceAllString(pattern, "[^/]+")
+		libraries, javaImports := imports.groups()
+		return map[string]interface{}{
+			"name":       operation.Name,
+			"args":       strings.Join(args, ", "),
+			"pattern":    javaString("^" + pattern + "$"),
+			"query":      queryMatchers,
+			"returnType": returnType,
+			"stub":       stub,
+			"assertion":  assertion,
+			"imports":    append(libraries, javaImports...),
+		}
+	}
+	return nil
+}
+
+// isoSamples are the values of temporal arguments in generated tests, as the client
+// must send them
+var isoSamples = map[string]string{
+	"LocalDate":      "2024-01-01",
+	"LocalTime":      "10:00:00",
+	"OffsetDateTime": "2024-01-01T10:00:00Z",
+}
+
+// requestParamPattern matches the name of a query parameter
+var requestParamPattern = regexp.MustCompile(`@RequestParam\(value = "([^"]+)"`)
+
+// sampleArgument returns a Java expression for an argument of a type, or "" if there is
+// no obvious value
+func sampleArgument(b *apiBuilder, javaType string, imports *javaImportSet) string {
+	switch javaType {
+	case "String":
+		return `"test"`
+	case "Integer":
+		return "1"
+	case "Long":
+		return "1L"
+	case "Float":
+		return "1.0f"
+	case "Double":
+		return "1.0"
+	case "Boolean":
+		return "true"
+	case "BigDecimal":
+		imports.add("java.math.BigDecimal")
+		return "BigDecimal.ONE"
+	case "UUID":
+		imports.add("java.util.UUID")
+		return "UUID.randomUUID()"
+	case "LocalDate", "LocalTime", "OffsetDateTime":
+		imports.add("java.time." + javaType)
+		return javaType + ".parse(" + javaString(isoSamples[javaType]) + ")"
+	}
+	if strings.HasPrefix(javaType, "List<") || strings.HasPrefix(javaType, "Set<") {
+		collection := javaType[:strings.Index(javaType, "<")]
+		imports.add("java.util." + collection)
+		return collection + ".of()"
+	}
+	for _, model := range b.models {
+		if model.Name == javaType && model.Enum {
+			imports.add(b.opts.ModelPackage + "." + model.Name)
+			return model.Name + ".values()[0]"
+		}
+	}
+	return ""
+}
+
+// sampleResponse returns the WireMock response of a stub returning an empty value of a
+// type, and the assertion on the value the client returns, or "" if the type has no
+// obvious empty JSON value
+func sampleResponse(b *apiBuilder, returnType string) (string, string) {
+	switch {
+	case returnType == "void":
+		return "noContent()", ""
+	case strings.HasPrefix(returnType, "List<"), strings.HasPrefix(returnType, "Set<"):
+		return `okJson("[]")`, "isEmpty()"
+	case strings.HasPrefix(returnType, "Map<"):
+		return `okJson("{}")`, "isEmpty()"
+	}
+	for _, model := range b.models {
+		if model.Name == returnType && !model.Enum {
+			return `okJson("{}")`, "isNotNull()"
+		}
+	}
+	return "", ""
+}
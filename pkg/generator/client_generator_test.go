@@ -0,0 +1,44 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/springwell/cli/pkg/config"
+)
+
+func TestGenerateClientSendsDateParametersAsISO(t *testing.T) {
+	projectDir := writeSpec(t, dateParamSpec)
+	cfg := config.GetDefaultConfig()
+	g := NewClientGenerator(cfg, projectDir)
+	if _, err := g.GenerateClient("launches", ClientOptions{Spec: "src/main/resources/openapi/api.yaml"}); err != nil {
+		t.Fatal(err)
+	}
+
+	packagePath := strings.ReplaceAll(cfg.Project.Package, ".", "/") + "/client/launches"
+	files := map[string][]string{
+		filepath.Join("src/main/java", packagePath, "LaunchesClient.java"): {
+			"import org.springframework.format.annotation.DateTimeFormat;",
+			`@RequestParam(value = "launch") @DateTimeFormat(iso = DateTimeFormat.ISO.DATE) LocalDate launch`,
+			`@RequestParam(value = "after", required = false) @DateTimeFormat(iso = DateTimeFormat.ISO.DATE_TIME) OffsetDateTime after`,
+		},
+		filepath.Join("src/test/java", packagePath, "LaunchesClientTest.java"): {
+			`client.listLaunches(LocalDate.parse("2024-01-01"), OffsetDateTime.parse("2024-01-01T10:00:00Z"))`,
+			`.withQueryParam("launch", equalTo("2024-01-01"))`,
+			`.withQueryParam("after", equalTo("2024-01-01T10:00:00Z"))`,
+		},
+	}
+	for path, wants := range files {
+		content, err := os.ReadFile(filepath.Join(projectDir, path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range wants {
+			if !strings.Contains(string(content), want) {
+				t.Errorf("%s does not contain %q:\n%s", filepath.Base(path), want, content)
+			}
+		}
+	}
+}
@@ -0,0 +1,332 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/springwell/cli/pkg/config"
+	"github.com/springwell/cli/pkg/util"
+)
+
+const (
+	// Azurite image emulating Azure Blob Storage in compose.yaml
+	azuriteImage = "mcr.microsoft.com/azure-storage/azurite:3.33.0"
+	// Service Bus emulator image and the SQL Edge image it keeps its state in
+	serviceBusEmulatorImage = "mcr.microsoft.com/azure-messaging/servicebus-emulator:1.0.1"
+	sqlEdgeImage            = "mcr.microsoft.com/azure-sql-edge:1.0.7"
+	// Password of the SQL Edge instance, only reachable by the Service Bus emulator
+	serviceBusSQLPassword = "ServiceBus-Emulator-1"
+	// fake-gcs-server image emulating Cloud Storage in compose.yaml
+	fakeGCSImage = "fsouza/fake-gcs-server:1.50"
+	// Google Cloud CLI image providing the Pub/Sub emulator
+	pubSubEmulatorImage = "gcr.io/google.com/cloudsdktool/google-cloud-cli:emulators"
+	// curl image creating the bucket, topic and subscription in the GCP emulators
+	curlImage = "curlimages/curl:8.10.1"
+
+	// Well-known account and key of Azurite
+	azuriteConnectionString = "DefaultEndpointsProtocol=http;AccountName=devstoreaccount1;AccountKey=Eby8vdM02xNOcqFlqUwJPLlmEtlCDXJ1OUzFT50uSRZ6IFsuFq2UVErCz4I6tq/K1SZFPTOtr/KBHBeksoGMGw==;BlobEndpoint=http://127.0.0.1:10000/devstoreaccount1;"
+	// Connection string of the Service Bus emulator
+	serviceBusEmulatorConnectionString = "Endpoint=sb://localhost;SharedAccessKeyName=RootManageSharedAccessKey;SharedAccessKey=SAS_KEY_VALUE;UseDevelopmentEmulator=true;"
+)
+
+// cloudDependencies maps each cloud provider to the SDKs of its blob storage, queues and secrets
+var cloudDependencies = map[string][]util.MavenDependency{
+	config.CloudAWS: {
+		{GroupID: "com.amazonaws", ArtifactID: "aws-java-sdk-s3", Version: awsSdkVersion},
+		{GroupID: "com.amazonaws", ArtifactID: "aws-java-sdk-sqs", Version: awsSdkVersion},
+		{GroupID: "com.amazonaws", ArtifactID: "aws-java-sdk-secretsmanager", Version: awsSdkVersion},
+	},
+	config.CloudAzure: {
+		{GroupID: "com.azure", ArtifactID: "azure-storage-blob", Version: "12.28.1"},
+		{GroupID: "com.azure", ArtifactID: "azure-messaging-servicebus", Version: "7.17.6"},
+		{GroupID: "com.azure", ArtifactID: "azure-security-keyvault-secrets", Version: "4.9.0"},
+		{GroupID: "com.azure", ArtifactID: "azure-identity", Version: "1.14.0"},
+	},
+	config.CloudGCP: {
+		{GroupID: "com.google.cloud", ArtifactID: "google-cloud-storage", Version: "2.43.2"},
+		{GroupID: "com.google.cloud", ArtifactID: "google-cloud-pubsub", Version: "1.133.1"},
+		{GroupID: "com.google.cloud", ArtifactID: "google-cloud-secretmanager", Version: "2.52.0"},
+	},
+}
+
+// cloudImplementations names the classes implementing BlobStorage, MessageQueue and
+// SecretStore for each cloud provider
+var cloudImplementations = map[string][3]string{
+	config.CloudAWS:   {"S3BlobStorage", "SqsMessageQueue", "SecretsManagerSecretStore"},
+	config.CloudAzure: {"AzureBlobStorage", "ServiceBusMessageQueue", "KeyVaultSecretStore"},
+	config.CloudGCP:   {"GcsBlobStorage", "PubSubMessageQueue", "SecretManagerSecretStore"},
+}
+
+var nonAlphanumericPattern = regexp.MustCompile(`[^a-z0-9]`)
+
+// AddCloud adds provider-neutral BlobStorage, MessageQueue and SecretStore interfaces to a
+// project, their implementations for a cloud provider, its configuration and, for the dev
+// profile, emulators in compose.yaml with a bucket and an events queue
+func AddCloud(cfg *config.Config, projectDir, cloud string) error {
+	if !slices.Contains(config.CloudProviders, cloud) {
+		return fmt.Errorf("unsupported cloud provider %q, use %s", cloud, strings.Join(config.CloudProviders, ", "))
+	}
+
+	pomPath := filepath.Join(projectDir, "pom.xml")
+	name := "app"
+	if _, err := os.Stat(pomPath); err != nil {
+		util.PrintWarning("No pom.xml found, add the %s SDKs to your build manually", cloud)
+	} else {
+		for _, dep := range cloudDependencies[cloud] {
+			added, err := util.AddMavenDependency(pomPath, dep)
+			if err != nil {
+				return err
+			}
+			if added {
+				util.PrintInfo("Added %s:%s dependency to pom.xml", dep.GroupID, dep.ArtifactID)
+			}
+		}
+		if project, err := util.ReadMavenProject(pomPath); err == nil && project.ArtifactID != "" {
+			name = project.ArtifactID
+		}
+	}
+
+	data := map[string]interface{}{
+		"package": cfg.Project.Package,
+		"cloud":   cloud,
+		"bucket":  name + "-files",
+		"queue":   name + "-events",
+		"project": name + "-local",
+	}
+
+	javaDir := filepath.Join(projectDir, "src/main/java", strings.ReplaceAll(cfg.Project.Package, ".", "/"))
+	files := map[string]string{
+		"cloud/blob_storage.tmpl":         filepath.Join(javaDir, "cloud", "BlobStorage.java"),
+		"cloud/message_queue.tmpl":        filepath.Join(javaDir, "cloud", "MessageQueue.java"),
+		"cloud/secret_store.tmpl":         filepath.Join(javaDir, "cloud", "SecretStore.java"),
+		"cloud/local_secret_store.tmpl":   filepath.Join(javaDir, "cloud", "LocalSecretStore.java"),
+		"cloud/" + cloud + "/config.tmpl": filepath.Join(javaDir, "config", "CloudConfig.java"),
+	}
+	for i, template := range []string{"blob_storage", "message_queue", "secret_store"} {
+		files["cloud/"+cloud+"/"+template+".tmpl"] = filepath.Join(javaDir, "cloud", cloud, cloudImplementations[cloud][i]+".java")
+	}
+	for _, template := range sortedKeys(files) {
+		path := files[template]
+		if fileExists(path) {
+			util.PrintWarning("%s already exists, leaving it unchanged", filepath.Base(path))
+			continue
+		}
+		if err := renderTemplate(cfg, projectDir, template, path, data); err != nil {
+			return err
+		}
+	}
+
+	if err := addApplicationConfig(projectDir, cloudApplicationConfig(cfg, cloud, name, data)); err != nil {
+		return err
+	}
+	if err := addProfileConfig(projectDir, "dev", cloudDevConfig(cloud, data)); err != nil {
+		return err
+	}
+	if err := util.MergeYAML(filepath.Join(projectDir, "compose.yaml"), cloudComposeConfig(cloud, data)); err != nil {
+		return err
+	}
+
+	util.PrintInfo("Added %s blob storage, queues and secrets with local emulators in compose.yaml", cloud)
+	return nil
+}
+
+// sortedKeys returns the keys of a map in order, so files are generated deterministically
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// cloudApplicationConfig returns the application.yml keys of a cloud provider, pointing at
+// resources named after the project unless overridden through the environment
+func cloudApplicationConfig(cfg *config.Config, cloud, name string, data map[string]interface{}) string {
+	common := `cloud:
+  storage:
+    bucket: ${CLOUD_STORAGE_BUCKET:` + data["bucket"].(string) + `}
+  queues:
+    events: ${CLOUD_QUEUE_EVENTS:` + data["queue"].(string) + `}
+  secrets:
+`
+	switch cloud {
+	case config.CloudAzure:
+		account := nonAlphanumericPattern.ReplaceAllString(strings.ToLower(name), "")
+		if len(account) > 24 {
+			account = account[:24]
+		}
+		return common + `    store: key-vault
+azure:
+  storage:
+    endpoint: ${AZURE_STORAGE_ENDPOINT:https://` + account + `.blob.core.windows.net}
+  servicebus:
+    namespace: ${AZURE_SERVICEBUS_NAMESPACE:` + name + `.servicebus.windows.net}
+  keyvault:
+    uri: ${AZURE_KEYVAULT_URI:https://` + name + `.vault.azure.net}
+`
+	case config.CloudGCP:
+		return common + `    store: secret-manager
+gcp:
+  project-id: ${GOOGLE_CLOUD_PROJECT:` + name + `}
+`
+	default:
+		region := cfg.AWS.Region
+		if region == "" {
+			region = "us-east-1"
+		}
+		return common + `    store: secrets-manager
+aws:
+  region: ${AWS_REGION:` + region + `}
+`
+	}
+}
+
+// cloudDevConfig returns the dev profile keys pointing the clients at the emulators in
+// compose.yaml. Secrets come from cloud.secrets.values, as no emulator covers all of them
+func cloudDevConfig(cloud string, data map[string]interface{}) string {
+	common := `cloud:
+  secrets:
+    store: local
+`
+	switch cloud {
+	case config.CloudAzure:
+		return common + `azure:
+  storage:
+    connection-string: ` + azuriteConnectionString + `
+    create-container: true
+  servicebus:
+    connection-string: ` + serviceBusEmulatorConnectionString + `
+`
+	case config.CloudGCP:
+		return common + `gcp:
+  project-id: ` + data["project"].(string) + `
+  storage:
+    host: http://localhost:4443
+  pubsub:
+    emulator-host: localhost:8085
+`
+	default:
+		return common + `aws:
+  endpoint: http://localhost:4566
+`
+	}
+}
+
+// cloudComposeConfig returns the compose.yaml services emulating a cloud provider's blob
+// storage and queues, with the project's bucket and events queue
+func cloudComposeConfig(cloud string, data map[string]interface{}) string {
+	bucket := data["bucket"].(string)
+	queue := data["queue"].(string)
+
+	switch cloud {
+	case config.CloudAzure:
+		return `services:
+  azurite:
+    image: ` + azuriteImage + `
+    command: azurite-blob --blobHost 0.0.0.0 --blobPort 10000
+    ports:
+      - "10000:10000"
+  servicebus:
+    image: ` + serviceBusEmulatorImage + `
+    ports:
+      - "5672:5672"
+    environment:
+      ACCEPT_EULA: "Y"
+      SQL_SERVER: servicebus-sql
+      MSSQL_SA_PASSWORD: ` + serviceBusSQLPassword + `
+    configs:
+      - source: servicebus-config
+        target: /ServiceBus_Emulator/ConfigFiles/Config.json
+    depends_on:
+      - servicebus-sql
+  servicebus-sql:
+    image: ` + sqlEdgeImage + `
+    environment:
+      ACCEPT_EULA: "Y"
+      MSSQL_SA_PASSWORD: ` + serviceBusSQLPassword + `
+configs:
+  servicebus-config:
+    content: |
+      {
+        "UserConfig": {
+          "Namespaces": [
+            {
+              "Name": "sbemulatorns",
+              "Queues": [
+                {
+                  "Name": "` + queue + `",
+                  "Properties": {
+                    "DeadLetteringOnMessageExpiration": false,
+                    "DefaultMessageTimeToLive": "PT1H",
+                    "LockDuration": "PT1M",
+                    "MaxDeliveryCount": 3,
+                    "RequiresDuplicateDetection": false,
+                    "RequiresSession": false
+                  }
+                }
+              ],
+              "Topics": []
+            }
+          ],
+          "Logging": {
+            "Type": "File"
+          }
+        }
+      }
+`
+	case config.CloudGCP:
+		project := data["project"].(string)
+		return `services:
+  gcs:
+    image: ` + fakeGCSImage + `
+    command: -scheme http -port 4443 -external-url http://localhost:4443
+    ports:
+      - "4443:4443"
+  pubsub:
+    image: ` + pubSubEmulatorImage + `
+    command: gcloud beta emulators pubsub start --host-port=0.0.0.0:8085 --project=` + project + `
+    ports:
+      - "8085:8085"
+  gcp-init:
+    image: ` + curlImage + `
+    entrypoint: ["sh", "/init.sh"]
+    configs:
+      - source: gcp-init
+        target: /init.sh
+    depends_on:
+      - gcs
+      - pubsub
+configs:
+  gcp-init:
+    content: |
+      #!/bin/sh
+      until curl -s http://gcs:4443/storage/v1/b > /dev/null; do sleep 1; done
+      until curl -s http://pubsub:8085 > /dev/null; do sleep 1; done
+      curl -s -X POST -H 'Content-Type: application/json' -d '{"name": "` + bucket + `"}' http://gcs:4443/storage/v1/b
+      curl -s -X PUT http://pubsub:8085/v1/projects/` + project + `/topics/` + queue + `
+      curl -s -X PUT -H 'Content-Type: application/json' -d '{"topic": "projects/` + project + `/topics/` + queue + `"}' http://pubsub:8085/v1/projects/` + project + `/subscriptions/` + queue + `
+`
+	default:
+		return `services:
+  localstack:
+    image: ` + localStackImage + `
+    ports:
+      - "4566:4566"
+    configs:
+      - source: cloud-resources
+        target: /etc/localstack/init/ready.d/cloud-resources.sh
+        mode: 0755
+configs:
+  cloud-resources:
+    content: |
+      #!/bin/sh
+      awslocal s3 mb s3://` + bucket + `
+      awslocal sqs create-queue --queue-name ` + queue + `
+`
+	}
+}
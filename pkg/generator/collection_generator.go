@@ -0,0 +1,647 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/springwell/cli/pkg/config"
+	"github.com/springwell/cli/pkg/openapi"
+	"github.com/springwell/cli/pkg/util"
+)
+
+const postmanSchema = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+// CollectionFormats lists the formats GenerateCollection writes
+var CollectionFormats = []string{"postman", "bruno", "http"}
+
+// CollectionGenerator exports the project's endpoints as request collections for HTTP clients
+type CollectionGenerator struct {
+	Config     *config.Config
+	ProjectDir string
+}
+
+// NewCollectionGenerator creates a new CollectionGenerator
+func NewCollectionGenerator(config *config.Config, projectDir string) *CollectionGenerator {
+	return &CollectionGenerator{
+		Config:     config,
+		ProjectDir: projectDir,
+	}
+}
+
+// CollectionOptions controls what GenerateCollection produces
+type CollectionOptions struct {
+	Format string // postman, bruno or http
+	Output string // Path of the collection, a directory for bruno (default: under http/)
+	Spec   string // OpenAPI document to export instead of the project's REST controllers
+}
+
+// httpCollection is a set of requests with the variables they use
+type httpCollection struct {
+	Name     string
+	BaseURL  string
+	Bearer   bool           // Whether the requests send {{token}} as a bearer token
+	Auth0    *auth0Settings // Tenant to get tokens from, or nil
+	Vars     []httpVariable // Variables beyond the base URL and the token
+	Requests []httpRequest
+}
+
+// httpRequest is a request of a collection
+type httpRequest struct {
+	Name   string
+	Folder string
+	Method string
+	Path   string // Path below the base URL, with the values of its path parameters
+	Query  []httpVariable
+	Body   string // Indented JSON, or ""
+	Auth   bool   // Whether the request sends the bearer token
+}
+
+// httpVariable is a collection variable or a query parameter; disabled parameters are
+// listed by the clients that support it but not sent
+type httpVariable struct {
+	Name     string
+	Value    string
+	Disabled bool
+	Secret   bool
+}
+
+// auth0Settings are the Auth0 tenant and API of the project, as configured by the
+// aws-temporal-auth0 template
+type auth0Settings struct {
+	Domain   string
+	Audience string
+	ClientID string
+}
+
+// GenerateCollection writes a Postman collection, a Bruno collection or an HTTP client file
+// with a request per operation of the project's REST controllers, or of an OpenAPI document.
+// It returns the path written and the number of requests.
+func (g *CollectionGenerator) GenerateCollection(opts CollectionOptions) (string, int, error) {
+	if !slices.Contains(CollectionFormats, opts.Format) {
+		return "", 0, fmt.Errorf("unsupported format %q, use one of %s", opts.Format, strings.Join(CollectionFormats, ", "))
+	}
+
+	var doc *openapi.Document
+	basePath := ""
+	if opts.Spec != "" {
+		specPath := opts.Spec
+		if !filepath.IsAbs(specPath) {
+			specPath = filepath.Join(g.ProjectDir, specPath)
+		}
+		loaded, err := openapi.Load(specPath)
+		if err != nil {
+			return "", 0, err
+		}
+		doc = loaded
+		basePath = (&apiBuilder{doc: doc}).basePath()
+	} else {
+		api := &ApiGenerator{Config: g.Config, ProjectDir: g.ProjectDir}
+		apiOpts, err := api.DefaultOptions()
+		if err != nil {
+			return "", 0, err
+		}
+		description, described, _, err := api.describeAPI(filepath.Join(g.ProjectDir, apiOpts.Spec))
+		if err != nil {
+			return "", 0, err
+		}
+		content, err := util.EncodeYAML(description)
+		if err != nil {
+			return "", 0, err
+		}
+		if doc, err = openapi.Parse([]byte(content)); err != nil {
+			return "", 0, err
+		}
+		basePath = described
+	}
+
+	collection, err := projectCollection(g.ProjectDir)
+	if err != nil {
+		return "", 0, err
+	}
+	if len(doc.Components.SecuritySchemes) > 0 {
+		collection.Bearer = true
+	}
+	for _, path := range doc.Paths.Keys {
+		item := doc.Paths.Values[path]
+		for _, method := range openapi.Methods {
+			op := item.Operation(method)
+			if op == nil {
+				continue
+			}
+			request, err := operationRequest(doc, basePath+path, method, item, op)
+			if err != nil {
+				return "", 0, fmt.Errorf("%s %s: %v", strings.ToUpper(method), path, err)
+			}
+			request.Auth = collection.Bearer && (op.Security == nil || len(op.Security) > 0)
+			collection.Requests = append(collection.Requests, request)
+		}
+	}
+	if len(collection.Requests) == 0 {
+		return "", 0, fmt.Errorf("there are no operations to export")
+	}
+
+	output := opts.Output
+	if output == "" {
+		switch opts.Format {
+		case "postman":
+			output = "http/" + collectionFileName(collection.Name) + ".postman_collection.json"
+		case "bruno":
+			output = "http/bruno"
+		case "http":
+			output = "http/api.http"
+		}
+	}
+	outputPath := output
+	if !filepath.IsAbs(outputPath) {
+		outputPath = filepath.Join(g.ProjectDir, output)
+	}
+	switch opts.Format {
+	case "postman":
+		err = writePostmanCollection(outputPath, collection)
+	case "bruno":
+		err = writeBrunoCollection(outputPath, collection)
+	case "http":
+		err = writeHTTPFile(outputPath, collection)
+	}
+	if err != nil {
+		return "", 0, err
+	}
+	return output, len(collection.Requests), nil
+}
+
+// projectCollection returns an empty collection named after the project, with the local base
+// URL of the application and its authentication
+func projectCollection(projectDir string) (*httpCollection, error) {
+	properties, err := applicationProperties(projectDir)
+	if err != nil {
+		return nil, err
+	}
+	title, _ := projectInfo(projectDir)
+	port := resolvePlaceholders(properties["server.port"], properties)
+	if port == "" {
+		port = "8080"
+	}
+	collection := &httpCollection{
+		Name:    title,
+		BaseURL: "http://localhost:" + port + strings.TrimSuffix(resolvePlaceholders(properties["server.servlet.context-path"], properties), "/"),
+		Bearer:  util.HasMavenDependency(filepath.Join(projectDir, "pom.xml"), "org.springframework.boot", "spring-boot-starter-security"),
+	}
+	if domain := resolvePlaceholders(properties["auth0.domain"], properties); domain != "" {
+		collection.Bearer = true
+		collection.Auth0 = &auth0Settings{
+			Domain:   domain,
+			Audience: resolvePlaceholders(properties["auth0.audience"], properties),
+			ClientID: resolvePlaceholders(properties["auth0.client-id"], properties),
+		}
+	}
+	return collection, nil
+}
+
+// operationRequest builds the request of an operation, with sample values for its path
+// parameters, its query parameters and its JSON body
+func operationRequest(doc *openapi.Document, path, method string, item *openapi.PathItem, op *openapi.Operation) (httpRequest, error) {
+	request := httpRequest{
+		Name:   op.Summary,
+		Folder: operationTag(path, op),
+		Method: strings.ToUpper(method),
+	}
+	if request.Name == "" {
+		request.Name = op.OperationID
+	}
+	if request.Name == "" {
+		request.Name = request.Method + " " + path
+	}
+
+	params, err := doc.OperationParameters(item, op)
+	if err != nil {
+		return request, err
+	}
+	for _, param := range params {
+		value := param.Example
+		if value == nil {
+			if value, err = doc.SampleValue(param.Schema); err != nil {
+				return request, err
+			}
+		}
+		text := parameterText(value)
+		switch param.In {
+		case "path":
+			path = strings.ReplaceAll(path, "{"+param.Name+"}", url.PathEscape(text))
+		case "query":
+			request.Query = append(request.Query, httpVariable{Name: param.Name, Value: text, Disabled: !param.Required})
+		}
+	}
+	request.Path = path
+
+	body, err := doc.ResolveRequestBody(op.RequestBody)
+	if err != nil {
+		return request, err
+	}
+	if body != nil {
+		if _, media := openapi.JSONContent(body.Content); media != nil {
+			value := media.Example
+			if value == nil && len(media.Examples) > 0 {
+				names := make([]string, 0, len(media.Examples))
+				for name := range media.Examples {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+				if example := media.Examples[names[0]]; example != nil {
+					value = example.Value
+				}
+			}
+			if value == nil {
+				if value, err = doc.SampleValue(media.Schema); err != nil {
+					return request, err
+				}
+			}
+			content, err := json.MarshalIndent(value, "", "  ")
+			if err != nil {
+				return request, err
+			}
+			request.Body = string(content)
+		}
+	}
+	return request, nil
+}
+
+// parameterText formats a sample value as a parameter, joining lists with commas
+func parameterText(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = parameterText(item)
+		}
+		return strings.Join(items, ",")
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+// url returns the URL of a request with its enabled query parameters
+func (r httpRequest) url() string {
+	target := "{{baseUrl}}" + r.Path
+	var query []string
+	for _, param := range r.Query {
+		if !param.Disabled {
+			// Commas separate the values of lists and sort orders, clients send them as they are
+			value := strings.ReplaceAll(url.QueryEscape(param.Value), "%2C", ",")
+			query = append(query, url.QueryEscape(param.Name)+"="+value)
+		}
+	}
+	if len(query) > 0 {
+		target += "?" + strings.Join(query, "&")
+	}
+	return target
+}
+
+// variables returns the variables of a collection, starting with the base URL and the token
+func (c *httpCollection) variables() []httpVariable {
+	variables := []httpVariable{{Name: "baseUrl", Value: c.BaseURL}}
+	if c.Bearer {
+		variables = append(variables, httpVariable{Name: "token", Secret: true})
+	}
+	if c.Auth0 != nil {
+		variables = append(variables,
+			httpVariable{Name: "auth0Domain", Value: c.Auth0.Domain},
+			httpVariable{Name: "auth0Audience", Value: c.Auth0.Audience},
+			httpVariable{Name: "auth0ClientId", Value: c.Auth0.ClientID},
+			httpVariable{Name: "auth0ClientSecret", Secret: true})
+	}
+	return append(variables, c.Vars...)
+}
+
+// auth0TokenRequest is the client credentials request getting an access token from Auth0
+var auth0TokenRequest = httpRequest{
+	Name:   "Get an Auth0 access token",
+	Folder: "Auth0",
+	Method: "POST",
+	Body: `{
+  "client_id": "{{auth0ClientId}}",
+  "client_secret": "{{auth0ClientSecret}}",
+  "audience": "{{auth0Audience}}",
+  "grant_type": "client_credentials"
+}`,
+}
+
+// auth0TokenURL is the token endpoint of the Auth0 tenant
+const auth0TokenURL = "https://{{auth0Domain}}/oauth/token"
+
+// writeHTTPFile writes the requests of a collection in the format of the IntelliJ HTTP
+// Client and the VS Code REST Client
+func writeHTTPFile(path string, c *httpCollection) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", c.Name)
+	b.WriteString("# Requests for the IntelliJ HTTP Client and the VS Code REST Client\n")
+	if c.Auth0 != nil {
+		b.WriteString("# Set @auth0ClientSecret, send the Auth0 request and copy its access_token to @token\n")
+	} else if c.Bearer {
+		b.WriteString("# Set @token to an access token of the application\n")
+	}
+	b.WriteString("\n")
+	for _, variable := range c.variables() {
+		fmt.Fprintf(&b, "%s\n", strings.TrimSpace("@"+variable.Name+" = "+variable.Value))
+	}
+
+	requests := c.Requests
+	if c.Auth0 != nil {
+		token := auth0TokenRequest
+		requests = append([]httpRequest{token}, requests...)
+	}
+	for _, request := range requests {
+		target := request.url()
+		if request.Folder == auth0TokenRequest.Folder && request.Name == auth0TokenRequest.Name {
+			target = auth0TokenURL
+		}
+		fmt.Fprintf(&b, "\n### %s\n%s %s\n", request.Name, request.Method, target)
+		if request.Body != "" {
+			b.WriteString("Content-Type: application/json\n")
+		}
+		if request.Auth {
+			b.WriteString("Authorization: Bearer {{token}}\n")
+		}
+		if request.Body != "" {
+			fmt.Fprintf(&b, "\n%s\n", request.Body)
+		}
+	}
+	return util.WriteFile(path, b.String())
+}
+
+// postmanCollection is a Postman collection in the v2.1 format
+type postmanCollection struct {
+	Info struct {
+		Name   string `json:"name"`
+		Schema string `json:"schema"`
+	} `json:"info"`
+	Auth     *postmanAuth      `json:"auth,omitempty"`
+	Variable []postmanVariable `json:"variable"`
+	Item     []*postmanItem    `json:"item"`
+}
+
+type postmanAuth struct {
+	Type   string            `json:"type"`
+	Bearer []postmanVariable `json:"bearer,omitempty"`
+}
+
+type postmanVariable struct {
+	Key      string `json:"key"`
+	Value    string `json:"value"`
+	Type     string `json:"type,omitempty"`
+	Disabled bool   `json:"disabled,omitempty"`
+}
+
+type postmanItem struct {
+	Name    string          `json:"name"`
+	Item    []*postmanItem  `json:"item,omitempty"`
+	Request *postmanRequest `json:"request,omitempty"`
+	Event   []postmanEvent  `json:"event,omitempty"`
+}
+
+type postmanRequest struct {
+	Method string            `json:"method"`
+	Auth   *postmanAuth      `json:"auth,omitempty"`
+	Header []postmanVariable `json:"header"`
+	URL    postmanURL        `json:"url"`
+	Body   *postmanBody      `json:"body,omitempty"`
+}
+
+type postmanURL struct {
+	Raw      string            `json:"raw"`
+	Protocol string            `json:"protocol,omitempty"`
+	Host     []string          `json:"host"`
+	Path     []string          `json:"path,omitempty"`
+	Query    []postmanVariable `json:"query,omitempty"`
+}
+
+type postmanBody struct {
+	Mode    string `json:"mode"`
+	Raw     string `json:"raw"`
+	Options struct {
+		Raw struct {
+			Language string `json:"language"`
+		} `json:"raw"`
+	} `json:"options"`
+}
+
+type postmanEvent struct {
+	Listen string `json:"listen"`
+	Script struct {
+		Type string   `json:"type"`
+		Exec []string `json:"exec"`
+	} `json:"script"`
+}
+
+// writePostmanCollection writes the requests of a collection as a Postman collection, with a
+// folder per tag
+func writePostmanCollection(path string, c *httpCollection) error {
+	collection := postmanCollection{Item: []*postmanItem{}}
+	collection.Info.Name = c.Name
+	collection.Info.Schema = postmanSchema
+	for _, variable := range c.variables() {
+		v := postmanVariable{Key: variable.Name, Value: variable.Value}
+		if variable.Secret {
+			v.Type = "secret"
+		}
+		collection.Variable = append(collection.Variable, v)
+	}
+	if c.Bearer {
+		collection.Auth = &postmanAuth{Type: "bearer", Bearer: []postmanVariable{{Key: "token", Value: "{{token}}", Type: "string"}}}
+	}
+
+	if c.Auth0 != nil {
+		request := postmanRequestOf(auth0TokenRequest)
+		request.Auth = &postmanAuth{Type: "noauth"}
+		request.URL = postmanURL{Raw: auth0TokenURL, Protocol: "https", Host: []string{"{{auth0Domain}}"}, Path: []string{"oauth", "token"}}
+		token := &postmanItem{Name: auth0TokenRequest.Name, Request: request}
+		event := postmanEvent{Listen: "test"}
+		event.Script.Type = "text/javascript"
+		event.Script.Exec = []string{`pm.collectionVariables.set("token", pm.response.json().access_token);`}
+		token.Event = []postmanEvent{event}
+		collection.Item = append(collection.Item, &postmanItem{Name: auth0TokenRequest.Folder, Item: []*postmanItem{token}})
+	}
+
+	folders := map[string]*postmanItem{}
+	for _, request := range c.Requests {
+		folder, ok := folders[request.Folder]
+		if !ok {
+			folder = &postmanItem{Name: request.Folder}
+			folders[request.Folder] = folder
+			collection.Item = append(collection.Item, folder)
+		}
+		item := postmanRequestOf(request)
+		if c.Bearer && !request.Auth {
+			item.Auth = &postmanAuth{Type: "noauth"}
+		}
+		folder.Item = append(folder.Item, &postmanItem{Name: request.Name, Request: item})
+	}
+
+	content, err := json.MarshalIndent(collection, "", "  ")
+	if err != nil {
+		return err
+	}
+	return util.WriteFile(path, string(content)+"\n")
+}
+
+// postmanRequestOf converts a request of a collection
+func postmanRequestOf(r httpRequest) *postmanRequest {
+	request := &postmanRequest{
+		Method: r.Method,
+		Header: []postmanVariable{},
+		URL: postmanURL{
+			Raw:  r.url(),
+			Host: []string{"{{baseUrl}}"},
+			Path: strings.Split(strings.Trim(r.Path, "/"), "/"),
+		},
+	}
+	for _, param := range r.Query {
+		request.URL.Query = append(request.URL.Query, postmanVariable{Key: param.Name, Value: param.Value, Disabled: param.Disabled})
+	}
+	if r.Body != "" {
+		request.Header = append(request.Header, postmanVariable{Key: "Content-Type", Value: "application/json"})
+		request.Body = &postmanBody{Mode: "raw", Raw: r.Body}
+		request.Body.Options.Raw.Language = "json"
+	}
+	return request
+}
+
+// writeBrunoCollection writes the requests of a collection as a Bruno collection: a directory
+// per tag holding a .bru file per request, and a Local environment with the variables
+func writeBrunoCollection(dir string, c *httpCollection) error {
+	manifest, err := json.MarshalIndent(map[string]interface{}{
+		"version": "1",
+		"name":    c.Name,
+		"type":    "collection",
+		"ignore":  []string{"node_modules", ".git"},
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := util.WriteFile(filepath.Join(dir, "bruno.json"), string(manifest)+"\n"); err != nil {
+		return err
+	}
+
+	auth := "auth {\n  mode: none\n}\n"
+	if c.Bearer {
+		auth = "auth {\n  mode: bearer\n}\n\nauth:bearer {\n  token: {{token}}\n}\n"
+	}
+	if err := util.WriteFile(filepath.Join(dir, "collection.bru"), auth); err != nil {
+		return err
+	}
+
+	var vars, secrets []string
+	for _, variable := range c.variables() {
+		if variable.Secret {
+			secrets = append(secrets, "  "+variable.Name)
+		} else {
+			vars = append(vars, fmt.Sprintf("  %s: %s", variable.Name, variable.Value))
+		}
+	}
+	environment := "vars {\n" + strings.Join(vars, "\n") + "\n}\n"
+	if len(secrets) > 0 {
+		environment += "vars:secret [\n" + strings.Join(secrets, ",\n") + "\n]\n"
+	}
+	if err := util.WriteFile(filepath.Join(dir, "environments", "Local.bru"), environment); err != nil {
+		return err
+	}
+
+	if c.Auth0 != nil {
+		token := brunoRequest(auth0TokenRequest, 1, "none")
+		token = strings.Replace(token, "  url: "+auth0TokenRequest.url(), "  url: "+auth0TokenURL, 1)
+		token += "\nscript:post-response {\n  bru.setEnvVar(\"token\", res.body.access_token);\n}\n"
+		if err := util.WriteFile(filepath.Join(dir, auth0TokenRequest.Folder, brunoFileName(auth0TokenRequest.Name)+".bru"), token); err != nil {
+			return err
+		}
+	}
+	seq := map[string]int{}
+	for _, request := range c.Requests {
+		seq[request.Folder]++
+		mode := "none"
+		if request.Auth {
+			mode = "inherit"
+		}
+		content := brunoRequest(request, seq[request.Folder], mode)
+		if err := util.WriteFile(filepath.Join(dir, brunoFileName(request.Folder), brunoFileName(request.Name)+".bru"), content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// brunoRequest returns the .bru file of a request
+func brunoRequest(r httpRequest, seq int, auth string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "meta {\n  name: %s\n  type: http\n  seq: %d\n}\n\n", r.Name, seq)
+	body := "none"
+	if r.Body != "" {
+		body = "json"
+	}
+	fmt.Fprintf(&b, "%s {\n  url: %s\n  body: %s\n  auth: %s\n}\n", strings.ToLower(r.Method), r.url(), body, auth)
+	if len(r.Query) > 0 {
+		b.WriteString("\nparams:query {\n")
+		for _, param := range r.Query {
+			prefix := ""
+			if param.Disabled {
+				prefix = "~"
+			}
+			fmt.Fprintf(&b, "  %s%s: %s\n", prefix, param.Name, param.Value)
+		}
+		b.WriteString("}\n")
+	}
+	if r.Body != "" {
+		b.WriteString("\nbody:json {\n")
+		for _, line := range strings.Split(r.Body, "\n") {
+			fmt.Fprintf(&b, "  %s\n", line)
+		}
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
+
+// brunoFileName returns a file name for the name of a request or folder
+func brunoFileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case strings.ContainsRune(`"'`, r):
+			return -1
+		case strings.ContainsRune(`/\:*?<>|`, r):
+			return '-'
+		}
+		return r
+	}, name)
+}
+
+// collectionFileName returns a file name for a collection name
+func collectionFileName(name string) string {
+	return strings.Trim(strings.ReplaceAll(util.ToDatabaseTableName(strings.ReplaceAll(name, " ", "")), "_", "-"), "-")
+}
+
+// enumSample returns the first constant of an enum of the project, or nil if javaType is not
+// one of its enums
+func enumSample(projectDir, javaType string) interface{} {
+	var constant interface{}
+	filepath.Walk(filepath.Join(projectDir, "src/main/java"), func(path string, info os.FileInfo, err error) error {
+		if err != nil || constant != nil || info.IsDir() || info.Name() != javaType+".java" {
+			return nil
+		}
+		if source, err := os.ReadFile(path); err == nil {
+			if constants := enumConstants(string(source), javaType); len(constants) > 0 {
+				constant = constants[0]
+			}
+		}
+		return nil
+	})
+	return constant
+}
@@ -0,0 +1,487 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/springwell/cli/pkg/config"
+	"github.com/springwell/cli/pkg/util"
+)
+
+// Versions of the contract testing dependencies added to generated projects
+const (
+	springCloudContractVersion = "4.2.1"
+	pactVersion                = "4.6.16"
+)
+
+// ContractFormats are the formats `generate contract` writes
+var ContractFormats = []string{"spring", "pact"}
+
+// ContractGenerator generates consumer-driven contracts of an entity's REST API and the
+// provider-side classes verifying them
+type ContractGenerator struct {
+	Config     *config.Config
+	ProjectDir string
+}
+
+// NewContractGenerator creates a new ContractGenerator
+func NewContractGenerator(config *config.Config, projectDir string) *ContractGenerator {
+	return &ContractGenerator{
+		Config:     config,
+		ProjectDir: projectDir,
+	}
+}
+
+// ContractOptions controls what GenerateContract produces
+type ContractOptions struct {
+	Format   string // Contract format, spring for Spring Cloud Contract or pact
+	Consumer string // Name of the consumer in the pact file
+}
+
+// contractInteraction is a request to an endpoint of the entity's controller and the response
+// the provider promises, written as a Spring Cloud Contract or as a Pact interaction
+type contractInteraction struct {
+	Name         string            // Name of the contract, which names its generated test
+	Description  string            // What the consumer asks for
+	Method       string            // HTTP method of the request
+	Path         string            // Path of the request
+	RequestBody  interface{}       // JSON body of the request, if any
+	Status       int               // Status of the response
+	ContentType  string            // Content type of the response body
+	ResponseBody interface{}       // JSON body of the response, if any
+	TypeMatched  []string          // JSON paths of the response whose values only have to match in type
+	RegexMatched map[string]string // JSON paths of the response whose values have to match a regex
+}
+
+// GenerateContract writes contracts for the CRUD endpoints of an entity, with payloads built
+// from sample values of its fields, and the test class verifying the controller against them
+// with a mocked service. It returns the number of contracts.
+func (g *ContractGenerator) GenerateContract(name string, opts ContractOptions) (int, error) {
+	if !slices.Contains(ContractFormats, opts.Format) {
+		return 0, fmt.Errorf("unsupported contract format %q, use %s", opts.Format, strings.Join(ContractFormats, " or "))
+	}
+
+	javaDir := filepath.Join(g.ProjectDir, "src/main/java", strings.ReplaceAll(g.Config.Project.Package, ".", "/"))
+	entity, err := ParseEntity(filepath.Join(javaDir, "domain/entity", name+".java"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, fmt.Errorf("entity %s not found, generate it first with 'springwell generate entity %s'", name, name)
+		}
+		return 0, err
+	}
+	controller, err := os.ReadFile(filepath.Join(javaDir, "controller", name+"Controller.java"))
+	if err != nil {
+		return 0, fmt.Errorf("no controller found for %s, contracts describe its REST API", name)
+	}
+	service, err := os.ReadFile(filepath.Join(javaDir, "service", name+"Service.java"))
+	if err != nil {
+		return 0, fmt.Errorf("no service found for %s, the contracts are verified against a mock of it", name)
+	}
+
+	entityOpts := generatedEntityOptions(javaDir, entity, string(controller))
+	data := NewEntityGenerator(g.Config, g.ProjectDir).templateData(name, entityOpts, entity.Fields, entity.Relations)
+	// The service may have been generated with another error style than the current one
+	data["problemDetails"] = strings.Contains(string(service), name+"NotFoundException")
+	imports := append(sampleImports(entity.Fields), "java.util.List")
+	if entity.Audit {
+		imports = append(imports, "java.time.LocalDateTime")
+	}
+	if data["problemDetails"] != true {
+		imports = append(imports, "java.util.Optional")
+	}
+	data["contractImports"] = javaImports(entity.Fields, imports...)
+	interactions := g.interactions(name, entityOpts, entity, data)
+
+	pomPath := filepath.Join(g.ProjectDir, "pom.xml")
+	testDir := filepath.Join(g.ProjectDir, "src/test/java", strings.ReplaceAll(g.Config.Project.Package, ".", "/"), "contract")
+	switch opts.Format {
+	case "spring":
+		if err := g.writeSpringContracts(data["nameCamel"].(string), interactions); err != nil {
+			return 0, err
+		}
+		if err := g.addSpringCloudContract(pomPath); err != nil {
+			return 0, err
+		}
+		data["pact"] = false
+		if err := renderTemplate(g.Config, g.ProjectDir, "contract/verification.tmpl", filepath.Join(testDir, name+"Base.java"), data); err != nil {
+			return 0, err
+		}
+	case "pact":
+		provider := "provider"
+		if project, err := util.ReadMavenProject(pomPath); err == nil && project.ArtifactID != "" {
+			provider = project.ArtifactID
+		}
+		consumer := opts.Consumer
+		if consumer == "" {
+			consumer = provider + "-consumer"
+		}
+		state := name + " 1 exists"
+		pactPath := filepath.Join(g.ProjectDir, "src/test/resources/pacts", consumer+"-"+provider+".json")
+		if err := writePact(pactPath, consumer, provider, state, interactions); err != nil {
+			return 0, err
+		}
+		if err := g.addPact(pomPath); err != nil {
+			return 0, err
+		}
+		data["pact"] = true
+		data["provider"] = provider
+		data["state"] = state
+		if err := renderTemplate(g.Config, g.ProjectDir, "contract/verification.tmpl", filepath.Join(testDir, name+"PactTest.java"), data); err != nil {
+			return 0, err
+		}
+	}
+	return len(interactions), nil
+}
+
+// interactions returns the contracts of the endpoints of the entity's controller. Responses
+// hold the values the verification class stubs the service with, and only the ID has to be
+// equal: consumers rely on the other properties being present with the right type.
+func (g *ContractGenerator) interactions(name string, opts EntityOptions, entity *ParsedEntity, data map[string]interface{}) []contractInteraction {
+	path := "/api/" + data["namePlural"].(string)
+	plural := data["nameClassPlural"].(string)
+
+	response := func(n int) *util.YAMLMap {
+		body := util.NewYAMLMap().Set("id", 1)
+		for _, field := range entity.Fields {
+			if value := jsonSampleValue(field, n); value != nil {
+				body.Set(field["name"], value)
+			}
+		}
+		if opts.Dto {
+			for _, relation := range entity.Relations {
+				if relation["type"] == "oneToMany" || relation["type"] == "manyToMany" {
+					body.Set(relation["idField"], []int{1})
+				} else {
+					body.Set(relation["idField"], 1)
+				}
+			}
+		}
+		if opts.Audit {
+			body.Set("createdAt", "2024-01-01T10:00:00")
+			body.Set("updatedAt", "2024-01-01T10:00:00")
+		}
+		return body
+	}
+	typeMatched := func(prefix string, body *util.YAMLMap) []string {
+		var paths []string
+		for _, key := range body.Keys() {
+			if key != "id" {
+				paths = append(paths, prefix+"."+key)
+			}
+		}
+		return paths
+	}
+
+	item := response(1)
+	list := contractInteraction{
+		Name:         "shouldReturn" + plural,
+		Description:  "a request for all " + plural,
+		Method:       "GET",
+		Path:         path,
+		Status:       200,
+		ContentType:  "application/json",
+		ResponseBody: []interface{}{item},
+		TypeMatched:  typeMatched("$[*]", item),
+	}
+	if opts.Paginate {
+		list.ResponseBody = util.NewYAMLMap().
+			Set("content", []interface{}{item}).
+			Set("page", 0).
+			Set("size", 20).
+			Set("totalElements", 1).
+			Set("totalPages", 1).
+			Set("last", true)
+		list.TypeMatched = append(typeMatched("$.content[*]", item), "$.page", "$.size", "$.totalElements", "$.totalPages", "$.last")
+	}
+
+	missing := contractInteraction{
+		Name:        "shouldReturnNotFoundForMissing" + name,
+		Description: "a request for missing " + name + " 999",
+		Method:      "GET",
+		Path:        path + "/999",
+		Status:      404,
+	}
+	if data["problemDetails"] == true {
+		missing.ContentType = "application/problem+json"
+		missing.ResponseBody = util.NewYAMLMap().
+			Set("type", "/problems/"+data["nameKebab"].(string)+"-not-found").
+			Set("status", 404).
+			Set("id", 999)
+		missing.RegexMatched = map[string]string{"$.type": ".*/" + data["nameKebab"].(string) + "-not-found"}
+	}
+
+	created := response(1)
+	updated := response(2)
+	interactions := []contractInteraction{
+		list,
+		{
+			Name:         "shouldReturn" + name + "ById",
+			Description:  "a request for " + name + " 1",
+			Method:       "GET",
+			Path:         path + "/1",
+			Status:       200,
+			ContentType:  "application/json",
+			ResponseBody: item,
+			TypeMatched:  typeMatched("$", item),
+		},
+		missing,
+		{
+			Name:         "shouldCreate" + name,
+			Description:  "a request to create a " + name,
+			Method:       "POST",
+			Path:         path,
+			RequestBody:  sampleRequest(g.ProjectDir, opts.Dto, entity.Fields, entity.Relations, 1),
+			Status:       201,
+			ContentType:  "application/json",
+			ResponseBody: created,
+			TypeMatched:  typeMatched("$", created),
+		},
+	}
+	if opts.Dto && data["requiredFields"] == true {
+		interactions = append(interactions, contractInteraction{
+			Name:        "shouldRejectInvalid" + name,
+			Description: "a request to create a " + name + " without its required fields",
+			Method:      "POST",
+			Path:        path,
+			RequestBody: util.NewYAMLMap(),
+			Status:      400,
+		})
+	}
+	return append(interactions,
+		contractInteraction{
+			Name:         "shouldUpdate" + name,
+			Description:  "a request to update " + name + " 1",
+			Method:       "PUT",
+			Path:         path + "/1",
+			RequestBody:  sampleRequest(g.ProjectDir, opts.Dto, entity.Fields, entity.Relations, 2),
+			Status:       200,
+			ContentType:  "application/json",
+			ResponseBody: updated,
+			TypeMatched:  typeMatched("$", updated),
+		},
+		contractInteraction{
+			Name:        "shouldDelete" + name,
+			Description: "a request to delete " + name + " 1",
+			Method:      "DELETE",
+			Path:        path + "/1",
+			Status:      204,
+		},
+	)
+}
+
+// writeSpringContracts writes a YAML contract per interaction to src/test/resources/contracts/<entity>,
+// the directory that names the base class of the generated tests
+func (g *ContractGenerator) writeSpringContracts(directory string, interactions []contractInteraction) error {
+	dir := filepath.Join(g.ProjectDir, "src/test/resources/contracts", directory)
+	for _, interaction := range interactions {
+		request := util.NewYAMLMap().
+			Set("method", interaction.Method).
+			Set("url", interaction.Path)
+		if interaction.RequestBody != nil {
+			request.Set("headers", util.NewYAMLMap().Set("Content-Type", "application/json"))
+			request.Set("body", interaction.RequestBody)
+		}
+
+		response := util.NewYAMLMap().Set("status", interaction.Status)
+		if interaction.ResponseBody != nil {
+			response.Set("headers", util.NewYAMLMap().Set("Content-Type", interaction.ContentType))
+			response.Set("body", interaction.ResponseBody)
+		}
+		var matchers []*util.YAMLMap
+		for _, path := range interaction.TypeMatched {
+			matchers = append(matchers, util.NewYAMLMap().Set("path", path).Set("type", "by_type"))
+		}
+		for path, regex := range interaction.RegexMatched {
+			matchers = append(matchers, util.NewYAMLMap().Set("path", path).Set("type", "by_regex").Set("value", regex))
+		}
+		if len(matchers) > 0 {
+			response.Set("matchers", util.NewYAMLMap().Set("body", matchers))
+		}
+
+		contract := util.NewYAMLMap().
+			Set("name", interaction.Name).
+			Set("description", interaction.Description).
+			Set("request", request).
+			Set("response", response)
+		content, err := util.EncodeYAML(contract)
+		if err != nil {
+			return err
+		}
+		if err := util.WriteFile(filepath.Join(dir, interaction.Name+".yml"), content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Pact specification v3 file structure
+type (
+	pactFile struct {
+		Consumer     pactParticipant   `json:"consumer"`
+		Provider     pactParticipant   `json:"provider"`
+		Interactions []json.RawMessage `json:"interactions"`
+		Metadata     pactMetadata      `json:"metadata"`
+	}
+	pactParticipant struct {
+		Name string `json:"name"`
+	}
+	pactMetadata struct {
+		PactSpecification struct {
+			Version string `json:"version"`
+		} `json:"pactSpecification"`
+	}
+	pactInteraction struct {
+		Description    string       `json:"description"`
+		ProviderStates []pactState  `json:"providerStates"`
+		Request        pactRequest  `json:"request"`
+		Response       pactResponse `json:"response"`
+	}
+	pactState struct {
+		Name string `json:"name"`
+	}
+	pactRequest struct {
+		Method  string            `json:"method"`
+		Path    string            `json:"path"`
+		Headers map[string]string `json:"headers,omitempty"`
+		Body    interface{}       `json:"body,omitempty"`
+	}
+	pactResponse struct {
+		Status        int                                     `json:"status"`
+		Headers       map[string]string                       `json:"headers,omitempty"`
+		Body          interface{}                             `json:"body,omitempty"`
+		MatchingRules map[string]map[string]pactMatchingRules `json:"matchingRules,omitempty"`
+	}
+	pactMatchingRules struct {
+		Matchers []map[string]string `json:"matchers"`
+	}
+)
+
+// writePact adds the interactions to the pact file between a consumer and the provider,
+// replacing those with the same description and keeping the ones of other entities
+func writePact(path, consumer, provider, state string, interactions []contractInteraction) error {
+	pact := pactFile{
+		Consumer: pactParticipant{Name: consumer},
+		Provider: pactParticipant{Name: provider},
+	}
+	pact.Metadata.PactSpecification.Version = "3.0.0"
+
+	descriptions := map[string]bool{}
+	for _, interaction := range interactions {
+		descriptions[interaction.Description] = true
+	}
+	if content, err := os.ReadFile(path); err == nil {
+		var existing pactFile
+		if err := json.Unmarshal(content, &existing); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		for _, raw := range existing.Interactions {
+			var interaction struct {
+				Description string `json:"description"`
+			}
+			if err := json.Unmarshal(raw, &interaction); err == nil && !descriptions[interaction.Description] {
+				pact.Interactions = append(pact.Interactions, raw)
+			}
+		}
+	}
+
+	for _, interaction := range interactions {
+		request := pactRequest{Method: interaction.Method, Path: interaction.Path, Body: interaction.RequestBody}
+		if interaction.RequestBody != nil {
+			request.Headers = map[string]string{"Content-Type": "application/json"}
+		}
+		response := pactResponse{Status: interaction.Status, Body: interaction.ResponseBody}
+		if interaction.ResponseBody != nil {
+			response.Headers = map[string]string{"Content-Type": interaction.ContentType}
+		}
+		rules := map[string]pactMatchingRules{}
+		for _, path := range interaction.TypeMatched {
+			rules[path] = pactMatchingRules{Matchers: []map[string]string{{"match": "type"}}}
+		}
+		for path, regex := range interaction.RegexMatched {
+			rules[path] = pactMatchingRules{Matchers: []map[string]string{{"match": "regex", "regex": regex}}}
+		}
+		if len(rules) > 0 {
+			response.MatchingRules = map[string]map[string]pactMatchingRules{"body": rules}
+		}
+
+		raw, err := json.Marshal(pactInteraction{
+			Description:    interaction.Description,
+			ProviderStates: []pactState{{Name: state}},
+			Request:        request,
+			Response:       response,
+		})
+		if err != nil {
+			return err
+		}
+		pact.Interactions = append(pact.Interactions, raw)
+	}
+
+	content, err := json.MarshalIndent(pact, "", "  ")
+	if err != nil {
+		return err
+	}
+	return util.WriteFile(path, string(content)+"\n")
+}
+
+// addSpringCloudContract adds the contract verifier and the Maven plugin generating tests
+// from src/test/resources/contracts, extending the base classes of the contract package
+func (g *ContractGenerator) addSpringCloudContract(pomPath string) error {
+	if !fileExists(pomPath) {
+		util.PrintWarning("No pom.xml found, add org.springframework.cloud:spring-cloud-starter-contract-verifier and spring-cloud-contract-maven-plugin to your build manually")
+		return nil
+	}
+	added, err := util.AddMavenDependency(pomPath, util.MavenDependency{
+		GroupID:    "org.springframework.cloud",
+		ArtifactID: "spring-cloud-starter-contract-verifier",
+		Version:    springCloudContractVersion,
+		Scope:      "test",
+	})
+	if err != nil {
+		return err
+	}
+	if added {
+		util.PrintInfo("Added Spring Cloud Contract verifier dependency to pom.xml")
+	}
+
+	added, err = util.AddMavenPlugin(pomPath, util.MavenDependency{
+		GroupID:    "org.springframework.cloud",
+		ArtifactID: "spring-cloud-contract-maven-plugin",
+		Version:    springCloudContractVersion,
+	}, `<extensions>true</extensions>
+<configuration>
+	<testFramework>JUNIT5</testFramework>
+	<packageWithBaseClasses>`+g.Config.Project.Package+`.contract</packageWithBaseClasses>
+</configuration>`)
+	if err != nil {
+		return err
+	}
+	if added {
+		util.PrintInfo("Added spring-cloud-contract-maven-plugin to pom.xml")
+	}
+	return nil
+}
+
+// addPact adds the Pact provider verification for Spring MockMvc
+func (g *ContractGenerator) addPact(pomPath string) error {
+	if !fileExists(pomPath) {
+		util.PrintWarning("No pom.xml found, add au.com.dius.pact.provider:spring6:%s as a test dependency manually", pactVersion)
+		return nil
+	}
+	added, err := util.AddMavenDependency(pomPath, util.MavenDependency{
+		GroupID:    "au.com.dius.pact.provider",
+		ArtifactID: "spring6",
+		Version:    pactVersion,
+		Scope:      "test",
+	})
+	if err != nil {
+		return err
+	}
+	if added {
+		util.PrintInfo("Added Pact provider dependency to pom.xml")
+	}
+	return nil
+}
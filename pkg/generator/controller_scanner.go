@@ -0,0 +1,353 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	restControllerPattern  = regexp.MustCompile(`@RestController\b`)
+	classMappingPattern    = regexp.MustCompile(`@RequestMapping\(([^)]*)\)\s*(?:@\w+(?:\([^)]*\))?\s*)*public\s+(?:final\s+)?class\s+(\w+)`)
+	classAuthorizedPattern = regexp.MustCompile(`@PreAuthorize\b[^;{}]*?public\s+(?:final\s+)?class\b`)
+	handlerMappingPattern  = regexp.MustCompile(`@(Get|Post|Put|Patch|Delete)Mapping\b(?:\(([^)]*)\))?`)
+	handlerMethodPattern   = regexp.MustCompile(`^\s*(?:@[\w.]+(?:\((?:[^()"]|"(?:[^"\\]|\\.)*")*\))?\s*)*public\s+([\w<>, ?\[\].]+?)\s+(\w+)\s*\(`)
+	javaStringPattern      = regexp.MustCompile(`"((?:[^"\\]|\\.)*)"`)
+	annotationArgPattern   = regexp.MustCompile(`(\w+)\s*=\s*("(?:[^"\\]|\\.)*"|[\w.]+)`)
+	javadocParamPattern    = regexp.MustCompile(`@param\s+(\w+)\s+(.+)`)
+	sortableFieldsPattern  = regexp.MustCompile(`SORTABLE_FIELDS\s*=\s*Set\.of\(([^)]*)\)`)
+	responseEntityPattern  = regexp.MustCompile(`^ResponseEntity<(.*)>$`)
+	javaAnnotationsPattern = regexp.MustCompile(`^((?:@[\w.]+(?:\((?:[^()"]|"(?:[^"\\]|\\.)*"|\([^()]*\))*\))?\s+)*)(.*)$`)
+)
+
+// RestEndpoint is a handler method of a @RestController
+type RestEndpoint struct {
+	Controller string      // Name of the controller class
+	Entity     string      // Entity the controller manages, if it was generated for one
+	Method     string      // HTTP method
+	Path       string      // Full path, including the mapping of the controller
+	Name       string      // Name of the Java method
+	Summary    string      // First sentence of the method's Javadoc
+	Params     []RestParam // Path variables, query parameters and headers
+	Body       string      // Java type of the request body, or ""
+	Validated  bool        // Whether the request body is validated with @Valid
+	Filter     string      // Record bound from the query parameters with @ModelAttribute, or ""
+	Pageable   bool        // Whether the endpoint takes a Pageable
+	PageSize   int         // Default page size of a pageable endpoint
+	PageSort   string      // Default sort property of a pageable endpoint
+	Sortable   []string    // Properties a pageable endpoint can be sorted by
+	Response   string      // Java type of the response body, or "Void"
+	Status     int         // Status code of a successful response
+	Errors     []int       // Status codes of the errors the endpoint responds with
+	Authorized bool        // Whether a @PreAuthorize rule on the method or its class restricts the endpoint
+}
+
+// RestParam is a path variable, query parameter or header of an endpoint
+type RestParam struct {
+	Name        string
+	In          string // path, query or header
+	Type        string // Java type
+	Required    bool
+	Default     string
+	Description string
+}
+
+// scanControllers finds the handler methods of the @RestController classes in the
+// controller package, as written by `generate entity`
+func scanControllers(javaDir string) ([]RestEndpoint, error) {
+	paths, err := filepath.Glob(filepath.Join(javaDir, "controller", "*.java"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	var endpoints []RestEndpoint
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		source := string(content)
+		if !restControllerPattern.MatchString(source) {
+			continue
+		}
+		controller := strings.TrimSuffix(filepath.Base(path), ".java")
+		basePath := ""
+		if match := classMappingPattern.FindStringSubmatch(source); match != nil {
+			basePath = mappingPath(match[1])
+		}
+		entity := ""
+		if name := strings.TrimSuffix(controller, "Controller"); fileExists(filepath.Join(javaDir, "domain/entity", name+".java")) {
+			entity = name
+		}
+		classAuthorized := classAuthorizedPattern.MatchString(source)
+		var sortable []string
+		if match := sortableFieldsPattern.FindStringSubmatch(source); match != nil {
+			for _, field := range javaStringPattern.FindAllStringSubmatch(match[1], -1) {
+				sortable = append(sortable, field[1])
+			}
+		}
+
+		for _, match := range handlerMappingPattern.FindAllStringSubmatchIndex(source, -1) {
+			signature := source[match[1]:]
+			method := handlerMethodPattern.FindStringSubmatchIndex(signature)
+			if method == nil {
+				continue
+			}
+			endpoint := RestEndpoint{
+				Controller: controller,
+				Entity:     entity,
+				Method:     strings.ToUpper(source[match[2]:match[3]]),
+				Path:       basePath,
+				Name:       signature[method[4]:method[5]],
+				Status:     200,
+				Authorized: classAuthorized,
+			}
+			if strings.Contains(leadingAnnotations(source[:match[0]])+signature[:method[1]], "@PreAuthorize") {
+				endpoint.Authorized = true
+			}
+			if match[4] >= 0 {
+				endpoint.Path = joinPaths(basePath, mappingPath(source[match[4]:match[5]]))
+			}
+			if endpoint.Path == "" {
+				endpoint.Path = "/"
+			}
+			endpoint.Response = signature[method[2]:method[3]]
+			if inner := responseEntityPattern.FindStringSubmatch(endpoint.Response); inner != nil {
+				endpoint.Response = inner[1]
+			}
+
+			paramsStart := method[1]
+			paramsEnd := paramsStart + closingParen(signature[paramsStart:])
+			bodyStart := paramsEnd + strings.Index(signature[paramsEnd:], "{") + 1
+			body := signature[bodyStart : bodyStart+blockEnd(signature[bodyStart:])]
+			javadoc := precedingJavadoc(source[:match[0]])
+			endpoint.Summary = javadocSummary(javadoc)
+			paramDocs := map[string]string{}
+			for _, param := range javadocParamPattern.FindAllStringSubmatch(javadoc, -1) {
+				paramDocs[param[1]] = strings.TrimSpace(param[2])
+			}
+
+			for _, param := range splitTypeArguments(signature[paramsStart:paramsEnd]) {
+				parseHandlerParam(&endpoint, param, paramDocs)
+			}
+			if endpoint.Pageable {
+				endpoint.Sortable = sortable
+			}
+
+			switch {
+			case strings.Contains(body, "HttpStatus.CREATED"):
+				endpoint.Status = 201
+			case strings.Contains(body, "noContent()"):
+				endpoint.Status = 204
+			}
+			if endpoint.Validated || strings.Contains(body, "BAD_REQUEST") || strings.Contains(body, "validateSort(") {
+				endpoint.Errors = append(endpoint.Errors, 400)
+			}
+			if strings.Contains(body, "NOT_FOUND") {
+				endpoint.Errors = append(endpoint.Errors, 404)
+			}
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	return endpoints, nil
+}
+
+// parseHandlerParam adds a parameter of a handler method to its endpoint
+func parseHandlerParam(endpoint *RestEndpoint, param string, paramDocs map[string]string) {
+	match := javaAnnotationsPattern.FindStringSubmatch(strings.TrimSpace(param))
+	annotations, declaration := match[1], strings.Fields(match[2])
+	if len(declaration) < 2 {
+		return
+	}
+	javaType := strings.Join(declaration[:len(declaration)-1], "")
+	name := declaration[len(declaration)-1]
+
+	switch {
+	case javaType == "Pageable":
+		endpoint.Pageable = true
+		endpoint.PageSize = 20
+		if args := annotationArgs(annotations, "PageableDefault"); args != nil {
+			if size, err := strconv.Atoi(args["size"]); err == nil {
+				endpoint.PageSize = size
+			}
+			endpoint.PageSort = args["sort"]
+		}
+	case strings.Contains(annotations, "@RequestBody"):
+		endpoint.Body = javaType
+		endpoint.Validated = strings.Contains(annotations, "@Valid") || strings.Contains(annotations, "@Validated")
+	case strings.Contains(annotations, "@ModelAttribute"):
+		endpoint.Filter = javaType
+	default:
+		for annotation, in := range map[string]string{"PathVariable": "path", "RequestParam": "query", "RequestHeader": "header"} {
+			args := annotationArgs(annotations, annotation)
+			if args == nil {
+				continue
+			}
+			restParam := RestParam{
+				Name:        name,
+				In:          in,
+				Type:        javaType,
+				Required:    args["required"] != "false",
+				Default:     args["defaultValue"],
+				Description: paramDocs[name],
+			}
+			if args["value"] != "" {
+				restParam.Name = args["value"]
+			} else if args["name"] != "" {
+				restParam.Name = args["name"]
+			}
+			if restParam.Default != "" || in == "path" {
+				restParam.Required = in == "path"
+			}
+			endpoint.Params = append(endpoint.Params, restParam)
+		}
+	}
+}
+
+// leadingAnnotations returns the text between the previous member, or its Javadoc, and the end of
+// source, holding the annotations written before a handler's mapping annotation
+func leadingAnnotations(source string) string {
+	start := strings.LastIndexAny(source, ";{}")
+	if end := strings.LastIndex(source, "*/"); end > start {
+		start = end + 1
+	}
+	return source[start+1:]
+}
+
+// annotationArgs returns the arguments of an annotation in a list of annotations, with a
+// single unnamed argument under "value", or nil if the annotation is not in the list
+func annotationArgs(annotations, name string) map[string]string {
+	match := regexp.MustCompile(`@` + name + `\b(?:\(((?:[^()"]|"(?:[^"\\]|\\.)*")*)\))?`).FindStringSubmatch(annotations)
+	if match == nil {
+		return nil
+	}
+	args := map[string]string{}
+	for _, arg := range annotationArgPattern.FindAllStringSubmatch(match[1], -1) {
+		args[arg[1]] = unquoteJava(arg[2])
+	}
+	if len(args) == 0 {
+		if value := javaStringPattern.FindStringSubmatch(match[1]); value != nil {
+			args["value"] = unquoteJava(value[0])
+		}
+	}
+	return args
+}
+
+// mappingPath returns the path of a mapping annotation's arguments
+func mappingPath(args string) string {
+	if named := annotationArgPattern.FindAllStringSubmatch(args, -1); len(named) > 0 {
+		for _, arg := range named {
+			if arg[1] == "value" || arg[1] == "path" {
+				return unquoteJava(arg[2])
+			}
+		}
+		return ""
+	}
+	if match := javaStringPattern.FindStringSubmatch(args); match != nil {
+		return unquoteJava(match[0])
+	}
+	return ""
+}
+
+// joinPaths joins a controller mapping and a method mapping
+func joinPaths(base, path string) string {
+	if path == "" {
+		return base
+	}
+	return strings.TrimSuffix(base, "/") + "/" + strings.TrimPrefix(path, "/")
+}
+
+// closingParen returns the offset of the parenthesis closing a list whose content starts
+// the source
+func closingParen(source string) int {
+	depth := 1
+	quoted := false
+	for i, c := range source {
+		switch {
+		case c == '"' && (i == 0 || source[i-1] != '\\'):
+			quoted = !quoted
+		case quoted:
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(source)
+}
+
+// precedingJavadoc returns the Javadoc comment ending right before an annotation and the
+// annotations preceding it, or ""
+func precedingJavadoc(source string) string {
+	trimmed := strings.TrimRight(source, " \t\n")
+	for {
+		lineStart := strings.LastIndex(trimmed, "\n") + 1
+		if !strings.HasPrefix(strings.TrimSpace(trimmed[lineStart:]), "@") {
+			break
+		}
+		trimmed = strings.TrimRight(trimmed[:lineStart], " \t\n")
+	}
+	if !strings.HasSuffix(trimmed, "*/") {
+		return ""
+	}
+	start := strings.LastIndex(trimmed, "/**")
+	if start < 0 {
+		return ""
+	}
+	return trimmed[start:]
+}
+
+// javadocSummary returns the first line of a Javadoc comment without the
+// "METHOD /path : " prefix the generated controllers use
+func javadocSummary(javadoc string) string {
+	for _, line := range strings.Split(javadoc, "\n") {
+		line = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "/**"))
+		line = strings.TrimSpace(strings.TrimPrefix(line, "*"))
+		if line == "" || line == "/" {
+			continue
+		}
+		if strings.HasPrefix(line, "@") {
+			return ""
+		}
+		if _, summary, found := strings.Cut(line, " : "); found {
+			line = summary
+		}
+		return strings.TrimSuffix(line, ".")
+	}
+	return ""
+}
+
+// unquoteJava returns the content of a Java string literal, or the text itself if it is
+// not one
+func unquoteJava(literal string) string {
+	if len(literal) < 2 || literal[0] != '"' || literal[len(literal)-1] != '"' {
+		return literal
+	}
+	var b strings.Builder
+	content := literal[1 : len(literal)-1]
+	for i := 0; i < len(content); i++ {
+		if content[i] == '\\' && i+1 < len(content) {
+			i++
+			switch content[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			default:
+				b.WriteByte(content[i])
+			}
+			continue
+		}
+		b.WriteByte(content[i])
+	}
+	return b.String()
+}
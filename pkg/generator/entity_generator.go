@@ -2,16 +2,24 @@ package generator
 
 import (
 	"bytes"
-	"io/ioutil"
+	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"text/template"
 
 	"github.com/springwell/cli/pkg/config"
+	"github.com/springwell/cli/pkg/templates"
 	"github.com/springwell/cli/pkg/util"
 )
 
+// Versions of build dependencies added to generated projects
+const (
+	mapStructVersion              = "1.5.5.Final"
+	lombokMapStructBindingVersion = "0.2.0"
+)
+
 // EntityGenerator generates entity-related code
 type EntityGenerator struct {
 	Config     *config.Config
@@ -26,66 +34,547 @@ func NewEntityGenerator(config *config.Config, projectDir string) *EntityGenerat
 	}
 }
 
+// EntityOptions controls what GenerateEntity produces
+type EntityOptions struct {
+	Fields     string // Field definitions (format: "name:type[:modifier]")
+	Relations  string // Relationship definitions (format: "type:field:entity")
+	TableName  string // Database table name (default: derived from entity name)
+	Audit      bool   // Add created/updated timestamps
+	Lombok     bool   // Use Lombok annotations
+	Dto        bool   // Generate request/response records and a mapper
+	Repository bool   // Generate the repository
+	Service    bool   // Generate the service
+	Controller bool   // Generate the REST controller
+	Paginate   bool   // Page and sort the list endpoint
+	Filter     bool   // Filter the list endpoint with JPA Specifications
+	Tests      bool   // Generate tests and fixtures for the generated components
+	Access     string // Access matrix (format: "operation:ROLE[|ROLE...]", comma separated)
+}
+
 // GenerateEntity generates an entity and its related components
-func (g *EntityGenerator) GenerateEntity(name, fieldsStr, relationsStr, tableName string, audit, lombok, generateDto, generateRepo, generateService, generateController bool) error {
+func (g *EntityGenerator) GenerateEntity(name string, opts EntityOptions) error {
 	// Parse fields and relations
-	fields, err := util.ParseFieldDefinitions(fieldsStr)
+	fields, err := util.ParseFieldDefinitions(opts.Fields)
 	if err != nil {
 		return err
 	}
 
-	relations, err := util.ParseRelationships(relationsStr)
+	relations, err := util.ParseRelationships(opts.Relations)
 	if err != nil {
 		return err
 	}
 
-	// If table name is not provided, generate it from entity name
-	if tableName == "" {
-		tableName = util.ToDatabaseTableName(name)
+	rules, err := util.ParseAccessRules(opts.Access)
+	if err != nil {
+		return err
 	}
 
-	// Create template data
-	data := map[string]interface{}{
-		"name":       name,
-		"nameCamel":  util.ToJavaVariableName(name),
-		"namePlural": util.ToJavaVariableName(name) + "s", // Simple pluralization, can be improved
-		"package":    g.Config.Project.Package,
-		"tableName":  tableName,
-		"fields":     fields,
-		"relations":  relations,
-		"audit":      audit,
-		"lombok":     lombok,
-	}
+	data := g.templateData(name, opts, fields, relations)
+	data["access"] = accessRules(rules)
 
 	// Generate entity
 	if err := g.generateFromTemplate("entity/entity.tmpl", filepath.Join(g.ProjectDir, "src/main/java", strings.ReplaceAll(g.Config.Project.Package, ".", "/"), "domain/entity", name+".java"), data); err != nil {
 		return err
 	}
 
+	// Audit timestamps are only populated once JPA auditing is enabled
+	if opts.Audit {
+		if err := g.enableJpaAuditing(data); err != nil {
+			return err
+		}
+	}
+
 	// Generate repository
-	if generateRepo {
+	if opts.Repository {
 		if err := g.generateFromTemplate("entity/repository.tmpl", filepath.Join(g.ProjectDir, "src/main/java", strings.ReplaceAll(g.Config.Project.Package, ".", "/"), "repository", name+"Repository.java"), data); err != nil {
 			return err
 		}
 	}
 
 	// Generate service
-	if generateService {
+	if opts.Service {
 		if err := g.generateFromTemplate("entity/service.tmpl", filepath.Join(g.ProjectDir, "src/main/java", strings.ReplaceAll(g.Config.Project.Package, ".", "/"), "service", name+"Service.java"), data); err != nil {
 			return err
 		}
 	}
 
+	// Enforce the access matrix with @PreAuthorize on the controller's methods
+	if len(rules) > 0 && opts.Controller {
+		if err := g.addMethodSecurity(data); err != nil {
+			return err
+		}
+		if err := addKeycloakRoles(g.ProjectDir, matrixRoles(rules)); err != nil {
+			return err
+		}
+	}
+
 	// Generate controller
-	if generateController {
+	if opts.Controller {
 		if err := g.generateFromTemplate("entity/controller.tmpl", filepath.Join(g.ProjectDir, "src/main/java", strings.ReplaceAll(g.Config.Project.Package, ".", "/"), "controller", name+"Controller.java"), data); err != nil {
 			return err
 		}
+		if err := g.generateHTTPRequests(name, opts, fields, relations); err != nil {
+			return err
+		}
+	}
+
+	// Generate the exceptions the service and controller throw instead of ResponseStatusException
+	if data["problemDetails"] == true && (opts.Service || opts.Controller) {
+		if err := g.addProblemDetails(name, opts, data); err != nil {
+			return err
+		}
+	}
+
+	// Generate request/response DTO records
+	if opts.Dto {
+		dtoDir := filepath.Join(g.ProjectDir, "src/main/java", strings.ReplaceAll(g.Config.Project.Package, ".", "/"), "dto")
+		dtoFiles := map[string]string{
+			"entity/create_request.tmpl": "Create" + name + "Request.java",
+			"entity/update_request.tmpl": "Update" + name + "Request.java",
+			"entity/response.tmpl":       name + "Response.java",
+		}
+		for templatePath, fileName := range dtoFiles {
+			if err := g.generateFromTemplate(templatePath, filepath.Join(dtoDir, fileName), data); err != nil {
+				return err
+			}
+		}
+
+		// Generate the MapStruct mapper between the entity and its DTOs
+		if err := g.generateFromTemplate("entity/mapper.tmpl", filepath.Join(g.ProjectDir, "src/main/java", strings.ReplaceAll(g.Config.Project.Package, ".", "/"), "util/mapper", name+"Mapper.java"), data); err != nil {
+			return err
+		}
+		if err := g.addMapStruct(); err != nil {
+			return err
+		}
+	}
+
+	// Generate the page wrapper shared by all paginated endpoints
+	if opts.Paginate && opts.Controller {
+		pagePath := filepath.Join(g.ProjectDir, "src/main/java", strings.ReplaceAll(g.Config.Project.Package, ".", "/"), "dto", "PageResponse.java")
+		if _, err := os.Stat(pagePath); os.IsNotExist(err) {
+			if err := g.generateFromTemplate("entity/page_response.tmpl", pagePath, data); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Generate the filter record and its JPA Specification
+	if opts.Filter {
+		if err := g.generateFromTemplate("entity/filter.tmpl", filepath.Join(g.ProjectDir, "src/main/java", strings.ReplaceAll(g.Config.Project.Package, ".", "/"), "dto", name+"Filter.java"), data); err != nil {
+			return err
+		}
+		if err := g.generateFromTemplate("entity/specifications.tmpl", filepath.Join(g.ProjectDir, "src/main/java", strings.ReplaceAll(g.Config.Project.Package, ".", "/"), "repository", name+"Specifications.java"), data); err != nil {
+			return err
+		}
+	}
+
+	// Generate tests for each generated layer, sharing fixture builders
+	if opts.Tests {
+		if err := g.generateTests(name, opts, data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GenerateIntegrationTest generates an end-to-end test for an existing entity's REST API,
+// running against the project's database in a Testcontainers container
+func (g *EntityGenerator) GenerateIntegrationTest(name string) error {
+	javaDir := filepath.Join(g.ProjectDir, "src/main/java", strings.ReplaceAll(g.Config.Project.Package, ".", "/"))
+	entity, err := ParseEntity(filepath.Join(javaDir, "domain/entity", name+".java"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("entity %s not found, generate it first with 'springwell generate entity %s'", name, name)
+		}
+		return err
+	}
+
+	controller, err := os.ReadFile(filepath.Join(javaDir, "controller", name+"Controller.java"))
+	if err != nil {
+		return fmt.Errorf("no controller found for %s, integration tests exercise its REST API", name)
+	}
+
+	db := ProjectDatabase(g.Config, g.ProjectDir)
+	if err := AddTestcontainers(g.Config, g.ProjectDir, db); err != nil {
+		return err
+	}
+
+	opts := generatedEntityOptions(javaDir, entity, string(controller))
+	data := g.templateData(name, opts, entity.Fields, entity.Relations)
+
+	pomPath := filepath.Join(g.ProjectDir, "pom.xml")
+	data["security"] = util.HasMavenDependency(pomPath, "org.springframework.boot", "spring-boot-starter-security")
+	data["flyway"] = util.HasMavenDependency(pomPath, "org.flywaydb", "flyway-core")
+	// The test user needs every role the controller's access rules ask for
+	data["roles"] = controllerRoles(string(controller))
+	if data["security"] == true {
+		if _, err := util.AddMavenDependency(pomPath, util.MavenDependency{
+			GroupID:    "org.springframework.security",
+			ArtifactID: "spring-security-test",
+			Scope:      "test",
+		}); err != nil {
+			return err
+		}
+	}
+
+	testDir := filepath.Join(g.ProjectDir, "src/test/java", strings.ReplaceAll(g.Config.Project.Package, ".", "/"))
+	fixturesPath := filepath.Join(testDir, "fixture", name+"Fixtures.java")
+	if !fileExists(fixturesPath) {
+		if err := g.generateFromTemplate("entity/fixtures.tmpl", fixturesPath, data); err != nil {
+			return err
+		}
+	}
+
+	return g.generateFromTemplate("entity/integration_test.tmpl", filepath.Join(testDir, "integration", name+"IntegrationTest.java"), data)
+}
+
+// generatedEntityOptions recovers the options an entity with a controller was generated
+// with from the files it left behind
+func generatedEntityOptions(javaDir string, entity *ParsedEntity, controller string) EntityOptions {
+	return EntityOptions{
+		TableName:  entity.TableName,
+		Audit:      entity.Audit,
+		Lombok:     entity.Lombok,
+		Dto:        fileExists(filepath.Join(javaDir, "dto", "Create"+entity.Name+"Request.java")),
+		Repository: true,
+		Service:    true,
+		Controller: true,
+		Paginate:   strings.Contains(controller, "PageResponse<"),
+		Filter:     fileExists(filepath.Join(javaDir, "dto", entity.Name+"Filter.java")),
+	}
+}
+
+// templateData builds the data shared by all entity templates
+func (g *EntityGenerator) templateData(name string, opts EntityOptions, fields, relations []map[string]string) map[string]interface{} {
+	// If table name is not provided, generate it from entity name
+	tableName := opts.TableName
+	if tableName == "" {
+		tableName = util.ToDatabaseTableName(name)
+	}
+
+	// Derive the ID properties that represent relations in DTOs, and which
+	// reference conversions the mapper needs for each related entity
+	var relationEntities []map[string]interface{}
+	byEntity := map[string]map[string]interface{}{}
+	for _, relation := range relations {
+		relation["idField"] = relationIDField(relation)
+		related, ok := byEntity[relation["entity"]]
+		if !ok {
+			related = map[string]interface{}{"name": relation["entity"]}
+			byEntity[relation["entity"]] = related
+			relationEntities = append(relationEntities, related)
+		}
+		switch relation["type"] {
+		case "oneToOne", "manyToOne":
+			related["fromId"] = true
+		case "manyToMany":
+			related["fromId"] = true
+			related["listFromIds"] = true
+			related["ids"] = true
+		case "oneToMany":
+			related["ids"] = true
+		}
+	}
+
+	// Collect the imports the entity and DTOs need
+	var entityExtraImports, requestExtraImports, responseExtraImports []string
+	for _, relation := range relations {
+		switch relation["type"] {
+		case "oneToMany":
+			entityExtraImports = append(entityExtraImports, "java.util.List")
+			responseExtraImports = append(responseExtraImports, "java.util.List")
+		case "manyToMany":
+			entityExtraImports = append(entityExtraImports, "java.util.List")
+			requestExtraImports = append(requestExtraImports, "java.util.List")
+			responseExtraImports = append(responseExtraImports, "java.util.List")
+		}
+	}
+	if opts.Audit {
+		entityExtraImports = append(entityExtraImports, "java.time.LocalDateTime")
+		responseExtraImports = append(responseExtraImports, "java.time.LocalDateTime")
+	}
+
+	// Temporal filter parameters need a format to bind from the query string
+	filterDateTimeFormat := false
+	for _, field := range fields {
+		if dateTimeFormat(field) != "" {
+			filterDateTimeFormat = true
+		}
+	}
+
+	// Fixtures build entities and DTOs from sample values
+	fixtureExtraImports := sampleImports(fields)
+	for _, relation := range relations {
+		if relation["type"] == "oneToMany" || relation["type"] == "manyToMany" {
+			fixtureExtraImports = append(fixtureExtraImports, "java.util.ArrayList", "java.util.List")
+		}
+	}
+	if opts.Audit {
+		fixtureExtraImports = append(fixtureExtraImports, "java.time.LocalDateTime")
+	}
+
+	return map[string]interface{}{
+		"name":             name,
+		"nameCamel":        util.ToJavaVariableName(name),
+		"namePlural":       util.ToJavaVariableName(name) + "s", // Simple pluralization, can be improved
+		"nameClassPlural":  name + "s",
+		"nameKebab":        strings.ReplaceAll(util.ToDatabaseTableName(name), "_", "-"),
+		"package":          g.Config.Project.Package,
+		"tableName":        tableName,
+		"fields":           fields,
+		"relations":        relations,
+		"relationEntities": relationEntities,
+		"audit":            opts.Audit,
+		"lombok":           opts.Lombok,
+		"dto":              opts.Dto,
+		"paginate":         opts.Paginate,
+		"filter":           opts.Filter,
+		"sortableFields":   sortableFields(fields, opts.Audit),
+		"entityImports":    javaImports(fields, entityExtraImports...),
+		"requestImports":   javaImports(fields, requestExtraImports...),
+		"responseImports":  javaImports(fields, responseExtraImports...),
+		"filterImports":    javaImports(fields),
+		"filterDateFormat": filterDateTimeFormat,
+		"properties":       entityProperties(fields, relations, opts.Audit),
+		"requiredFields":   hasRequiredFields(fields),
+		"filterField":      sampleFilterField(fields),
+		"repository":       opts.Repository,
+		"service":          opts.Service,
+		"problemDetails":   g.Config.Errors.Style == config.ErrorStyleProblemDetails,
+		"fixtureImports":   javaImports(fields, fixtureExtraImports...),
+		"testImports":      javaImports(fields, sampleImports(fields)...),
+		"access":           map[string]map[string]interface{}{},
+	}
+}
+
+// generateHTTPRequests writes http/<Entity>.http with a request per endpoint of the
+// controller, sending bodies built from sample values of the fields
+func (g *EntityGenerator) generateHTTPRequests(name string, opts EntityOptions, fields, relations []map[string]string) error {
+	collection, err := projectCollection(g.ProjectDir)
+	if err != nil {
+		return err
+	}
+	collection.Name = name + " API"
+	collection.Vars = []httpVariable{{Name: "id", Value: "1"}}
+
+	path := "/api/" + util.ToJavaVariableName(name) + "s"
+	var query []httpVariable
+	if opts.Paginate {
+		query = []httpVariable{{Name: "page", Value: "0"}, {Name: "size", Value: "20"}, {Name: "sort", Value: "id,asc"}}
+	}
+	collection.Requests = []httpRequest{
+		{Name: "Get all " + util.ToJavaVariableName(name) + "s", Method: "GET", Path: path, Query: query},
+		{Name: "Get " + name + " by id", Method: "GET", Path: path + "/{{id}}"},
+		{Name: "Create " + name, Method: "POST", Path: path, Body: g.sampleBody(opts, fields, relations, 1)},
+		{Name: "Update " + name, Method: "PUT", Path: path + "/{{id}}", Body: g.sampleBody(opts, fields, relations, 2)},
+		{Name: "Delete " + name, Method: "DELETE", Path: path + "/{{id}}"},
+	}
+	for i := range collection.Requests {
+		collection.Requests[i].Auth = collection.Bearer
+	}
+	return writeHTTPFile(filepath.Join(g.ProjectDir, "http", name+".http"), collection)
+}
+
+// sampleBody returns the JSON body of a create or update request, with sample values of the
+// fields and the IDs of the related entities; different values of n give different samples
+func (g *EntityGenerator) sampleBody(opts EntityOptions, fields, relations []map[string]string, n int) string {
+	body := sampleRequest(g.ProjectDir, opts.Dto, fields, relations, n)
+	if body.Len() == 0 {
+		return "{}"
+	}
+	var lines []string
+	for _, name := range body.Keys() {
+		value, _ := body.Get(name)
+		content, _ := json.Marshal(value)
+		lines = append(lines, fmt.Sprintf("  %q: %s", name, content))
+	}
+	return "{\n" + strings.Join(lines, ",\n") + "\n}"
+}
+
+// sampleRequest returns the properties of a create or update request, with sample values
+// of the fields and the IDs of the related entities
+func sampleRequest(projectDir string, dto bool, fields, relations []map[string]string, n int) *util.YAMLMap {
+	body := util.NewYAMLMap()
+	for _, field := range fields {
+		value := jsonSampleValue(field, n)
+		if value == nil {
+			value = enumSample(projectDir, field["type"])
+		}
+		body.Set(field["name"], value)
+	}
+	for _, relation := range relations {
+		switch {
+		case relation["type"] == "oneToMany":
+		case dto && relation["type"] == "manyToMany":
+			body.Set(relation["idField"], []int{1})
+		case dto:
+			body.Set(relation["idField"], 1)
+		case relation["type"] == "manyToMany":
+			body.Set(relation["field"], []map[string]int{{"id": 1}})
+		default:
+			body.Set(relation["field"], map[string]int{"id": 1})
+		}
+	}
+	return body
+}
+
+// generateTests generates fixture builders and tests for the entity's repository,
+// service and controller
+func (g *EntityGenerator) generateTests(name string, opts EntityOptions, data map[string]interface{}) error {
+	testDir := filepath.Join(g.ProjectDir, "src/test/java", strings.ReplaceAll(g.Config.Project.Package, ".", "/"))
+
+	if err := g.generateFromTemplate("entity/fixtures.tmpl", filepath.Join(testDir, "fixture", name+"Fixtures.java"), data); err != nil {
+		return err
+	}
+
+	if opts.Repository {
+		if err := g.generateFromTemplate("entity/repository_test.tmpl", filepath.Join(testDir, "repository", name+"RepositoryTest.java"), data); err != nil {
+			return err
+		}
+		if err := g.addEmbeddedTestDatabase(); err != nil {
+			return err
+		}
 	}
 
-	// Generate DTO
-	if generateDto {
-		if err := g.generateFromTemplate("entity/dto.tmpl", filepath.Join(g.ProjectDir, "src/main/java", strings.ReplaceAll(g.Config.Project.Package, ".", "/"), "dto", name+"DTO.java"), data); err != nil {
+	// The service and controller tests exercise the generated repository and service
+	if opts.Service && opts.Repository {
+		if err := g.generateFromTemplate("entity/service_test.tmpl", filepath.Join(testDir, "service", name+"ServiceTest.java"), data); err != nil {
+			return err
+		}
+	}
+	if opts.Controller && opts.Service {
+		if err := g.generateFromTemplate("entity/controller_test.tmpl", filepath.Join(testDir, "controller", name+"ControllerTest.java"), data); err != nil {
+			return err
+		}
+		if strings.TrimSpace(opts.Access) != "" {
+			if err := g.generateFromTemplate("entity/security_test.tmpl", filepath.Join(testDir, "controller", name+"SecurityTest.java"), data); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// addEmbeddedTestDatabase adds H2 as a test dependency so that @DataJpaTest can
+// replace the application's database with an embedded one
+func (g *EntityGenerator) addEmbeddedTestDatabase() error {
+	pomPath := filepath.Join(g.ProjectDir, "pom.xml")
+	if _, err := os.Stat(pomPath); err != nil {
+		util.PrintWarning("No pom.xml found, add com.h2database:h2 as a test dependency to run repository tests")
+		return nil
+	}
+	if util.HasMavenDependency(pomPath, "com.h2database", "h2") {
+		return nil
+	}
+
+	added, err := util.AddMavenDependency(pomPath, util.MavenDependency{
+		GroupID:    "com.h2database",
+		ArtifactID: "h2",
+		Scope:      "test",
+	})
+	if err != nil {
+		return err
+	}
+	if added {
+		util.PrintInfo("Added H2 test dependency to pom.xml")
+	}
+	return nil
+}
+
+// enableJpaAuditing adds a configuration enabling JPA auditing unless the project already has one
+func (g *EntityGenerator) enableJpaAuditing(data map[string]interface{}) error {
+	javaDir := filepath.Join(g.ProjectDir, "src/main/java")
+	enabled, err := sourcesContain(javaDir, "@EnableJpaAuditing")
+	if err != nil || enabled {
+		return err
+	}
+
+	util.PrintInfo("Enabling JPA auditing for created/updated timestamps")
+	return g.generateFromTemplate("entity/auditing_config.tmpl", filepath.Join(javaDir, strings.ReplaceAll(g.Config.Project.Package, ".", "/"), "config", "JpaAuditingConfig.java"), data)
+}
+
+// addProblemDetails generates the entity's not found exception and, unless the project
+// already has them, the domain exception base class and the global handler answering
+// exceptions with RFC 9457 problem details
+func (g *EntityGenerator) addProblemDetails(name string, opts EntityOptions, data map[string]interface{}) error {
+	javaDir := filepath.Join(g.ProjectDir, "src/main/java", strings.ReplaceAll(g.Config.Project.Package, ".", "/"))
+	exceptionDir := filepath.Join(javaDir, "exception")
+
+	if err := g.generateFromTemplate("errors/not_found_exception.tmpl", filepath.Join(exceptionDir, name+"NotFoundException.java"), data); err != nil {
+		return err
+	}
+	shared := map[string]string{"errors/domain_exception.tmpl": "DomainException.java"}
+	if opts.Paginate && opts.Controller {
+		shared["errors/unsupported_sort_exception.tmpl"] = "UnsupportedSortException.java"
+	}
+	for templatePath, fileName := range shared {
+		if path := filepath.Join(exceptionDir, fileName); !fileExists(path) {
+			if err := g.generateFromTemplate(templatePath, path, data); err != nil {
+				return err
+			}
+		}
+	}
+
+	handlerPath := filepath.Join(exceptionDir, "GlobalExceptionHandler.java")
+	if fileExists(handlerPath) {
+		return nil
+	}
+	handlerData := map[string]interface{}{
+		"package":  g.Config.Project.Package,
+		"security": util.HasMavenDependency(filepath.Join(g.ProjectDir, "pom.xml"), "org.springframework.boot", "spring-boot-starter-security"),
+		// Projects created from the AWS template have generic exceptions of their own
+		"resourceExceptions": fileExists(filepath.Join(exceptionDir, "ResourceNotFoundException.java")) && fileExists(filepath.Join(exceptionDir, "ValidationException.java")),
+	}
+	if err := g.generateFromTemplate("errors/exception_handler.tmpl", handlerPath, handlerData); err != nil {
+		return err
+	}
+	if err := addApplicationConfig(g.ProjectDir, "problems:\n  base-uri: /problems/\n"); err != nil {
+		return err
+	}
+	util.PrintInfo("Errors are answered with problem details by exception/GlobalExceptionHandler.java")
+	if fileExists(filepath.Join(javaDir, "middleware", "ErrorHandlingAdvice.java")) {
+		util.PrintWarning("GlobalExceptionHandler takes precedence over middleware/ErrorHandlingAdvice.java, remove it and ErrorResponseDTO once no client relies on them")
+	}
+	return nil
+}
+
+// addMapStruct adds the MapStruct dependency and annotation processor to the project's pom.xml
+func (g *EntityGenerator) addMapStruct() error {
+	pomPath := filepath.Join(g.ProjectDir, "pom.xml")
+	if _, err := os.Stat(pomPath); err != nil {
+		util.PrintWarning("No pom.xml found, add org.mapstruct:mapstruct:%s and its annotation processor to your build manually", mapStructVersion)
+		return nil
+	}
+
+	added, err := util.AddMavenDependency(pomPath, util.MavenDependency{
+		GroupID:    "org.mapstruct",
+		ArtifactID: "mapstruct",
+		Version:    mapStructVersion,
+	})
+	if err != nil {
+		return err
+	}
+	if added {
+		util.PrintInfo("Added MapStruct dependency to pom.xml")
+	}
+
+	if _, err := util.AddAnnotationProcessorPath(pomPath, util.MavenDependency{
+		GroupID:    "org.mapstruct",
+		ArtifactID: "mapstruct-processor",
+		Version:    mapStructVersion,
+	}); err != nil {
+		return err
+	}
+
+	// Lombok needs a binding so MapStruct sees the generated accessors
+	if util.HasMavenDependency(pomPath, "org.projectlombok", "lombok") {
+		if _, err := util.AddAnnotationProcessorPath(pomPath, util.MavenDependency{
+			GroupID:    "org.projectlombok",
+			ArtifactID: "lombok-mapstruct-binding",
+			Version:    lombokMapStructBindingVersion,
+		}); err != nil {
 			return err
 		}
 	}
@@ -95,20 +584,18 @@ func (g *EntityGenerator) GenerateEntity(name, fieldsStr, relationsStr, tableNam
 
 // generateFromTemplate generates a file from a template
 func (g *EntityGenerator) generateFromTemplate(templatePath, outputPath string, data map[string]interface{}) error {
-	// Determine the template directory
-	var templateDir string
-	if _, err := os.Stat(filepath.Join(g.ProjectDir, g.Config.Templates.Directory)); err == nil {
-		// Use project-specific templates if available
-		templateDir = filepath.Join(g.ProjectDir, g.Config.Templates.Directory)
-	} else {
-		// Use built-in templates
-		templateDir = filepath.Join("pkg", "templates")
-	}
-
-	// Read the template file
-	templateContent, err := ioutil.ReadFile(filepath.Join(templateDir, templatePath))
+	return renderTemplate(g.Config, g.ProjectDir, templatePath, outputPath, data)
+}
+
+// renderTemplate renders a built-in or project-specific template to a file
+func renderTemplate(cfg *config.Config, projectDir, templatePath, outputPath string, data map[string]interface{}) error {
+	// Prefer a project-specific template, falling back to the built-in one
+	templateContent, err := os.ReadFile(filepath.Join(projectDir, cfg.Templates.Directory, templatePath))
 	if err != nil {
-		return err
+		templateContent, err = templates.FS.ReadFile(templatePath)
+		if err != nil {
+			return err
+		}
 	}
 
 	// Create a new template
@@ -117,6 +604,15 @@ func (g *EntityGenerator) generateFromTemplate(templatePath, outputPath string,
 			return a == b
 		},
 		"toLowerCase": strings.ToLower,
+		"capitalize":  capitalize,
+		"camel":       util.ToJavaVariableName,
+		"boxed":       boxedType,
+		"filterOp":    filterOperation,
+		"dateFormat":  dateTimeFormat,
+		"validation":  validationAnnotations,
+		"getter":      getterName,
+		"sample":      sampleValue,
+		"assertEq":    assertEquals,
 	}).Parse(string(templateContent))
 	if err != nil {
 		return err
@@ -131,3 +627,38 @@ func (g *EntityGenerator) generateFromTemplate(templatePath, outputPath string,
 	// Write the generated file
 	return util.WriteFile(outputPath, buf.String())
 }
+
+// sourcesContain reports whether any Java source under dir contains text
+func sourcesContain(dir, text string) (bool, error) {
+	path, err := findSource(dir, text)
+	return path != "", err
+}
+
+// findSource returns the path of the first Java source under dir containing text,
+// or an empty path if there is none
+func findSource(dir, text string) (string, error) {
+	found := ""
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || found != "" || info.IsDir() || !strings.HasSuffix(path, ".java") {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if strings.Contains(string(content), text) {
+			found = path
+		}
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	return found, nil
+}
+
+// fileExists reports whether a regular file exists at path
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
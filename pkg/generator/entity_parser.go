@@ -0,0 +1,105 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/springwell/cli/pkg/util"
+)
+
+var (
+	entityTablePattern    = regexp.MustCompile(`@Table\(\s*name\s*=\s*"([^"]+)"`)
+	entityPropertyPattern = regexp.MustCompile(`^\s*private\s+([\w.]+(?:<[\w.]+>)?)\s+(\w+)\s*(?:=.*)?;`)
+	entityColumnPattern   = regexp.MustCompile(`@Column\(\s*name\s*=\s*"([^"]+)"`)
+	entityRelationPattern = regexp.MustCompile(`@(OneToOne|OneToMany|ManyToOne|ManyToMany)\b`)
+	entityListPattern     = regexp.MustCompile(`^List<([\w.]+)>$`)
+)
+
+// ParsedEntity is the model of an entity recovered from its Java source
+type ParsedEntity struct {
+	Name      string
+	TableName string
+	Fields    []map[string]string
+	Relations []map[string]string
+	Audit     bool
+	Lombok    bool
+}
+
+// ParseEntity reads a JPA entity class, as written by GenerateEntity, back into the
+// field and relation definitions it was generated from
+func ParseEntity(path string) (*ParsedEntity, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	source := string(content)
+
+	name := strings.TrimSuffix(filepath.Base(path), ".java")
+	entity := &ParsedEntity{
+		Name:      name,
+		TableName: util.ToDatabaseTableName(name),
+		Lombok:    strings.Contains(source, "@Data"),
+	}
+	if match := entityTablePattern.FindStringSubmatch(source); match != nil {
+		entity.TableName = match[1]
+	}
+
+	// Annotations apply to the next property declaration
+	var annotations []string
+	for _, line := range strings.Split(source, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "@") || strings.HasPrefix(trimmed, "name =") ||
+			strings.HasPrefix(trimmed, "joinColumns") || strings.HasPrefix(trimmed, "inverseJoinColumns") {
+			annotations = append(annotations, trimmed)
+			continue
+		}
+		match := entityPropertyPattern.FindStringSubmatch(line)
+		if match == nil || strings.Contains(line, " static ") {
+			// Anything else, such as a method or the class declaration, ends the annotations
+			if trimmed != "" && trimmed != ")" {
+				annotations = nil
+			}
+			continue
+		}
+		javaType, property := match[1], match[2]
+		annotated := strings.Join(annotations, " ")
+		annotations = nil
+
+		switch {
+		case strings.Contains(annotated, "@Id"):
+			continue
+		case strings.Contains(annotated, "@CreatedDate") || strings.Contains(annotated, "@LastModifiedDate"):
+			entity.Audit = true
+		case entityRelationPattern.MatchString(annotated):
+			relationType := entityRelationPattern.FindStringSubmatch(annotated)[1]
+			related := javaType
+			if list := entityListPattern.FindStringSubmatch(javaType); list != nil {
+				related = list[1]
+			}
+			entity.Relations = append(entity.Relations, map[string]string{
+				"type":   strings.ToLower(relationType[:1]) + relationType[1:],
+				"field":  property,
+				"entity": related,
+			})
+		default:
+			columnName := util.ToColumnName(property)
+			if column := entityColumnPattern.FindStringSubmatch(annotated); column != nil {
+				columnName = column[1]
+			}
+			nullable := "false"
+			if strings.Contains(annotated, "nullable = true") {
+				nullable = "true"
+			}
+			entity.Fields = append(entity.Fields, map[string]string{
+				"name":       property,
+				"type":       javaType,
+				"columnName": columnName,
+				"nullable":   nullable,
+			})
+		}
+	}
+
+	return entity, nil
+}
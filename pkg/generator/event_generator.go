@@ -0,0 +1,439 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/springwell/cli/pkg/config"
+	"github.com/springwell/cli/pkg/util"
+)
+
+var migrationVersionPattern = regexp.MustCompile(`^V(\d+)__`)
+
+// EventGenerator generates domain events published through a transactional outbox
+type EventGenerator struct {
+	Config     *config.Config
+	ProjectDir string
+}
+
+// NewEventGenerator creates a new EventGenerator
+func NewEventGenerator(config *config.Config, projectDir string) *EventGenerator {
+	return &EventGenerator{
+		Config:     config,
+		ProjectDir: projectDir,
+	}
+}
+
+// EventOptions controls what GenerateEvent produces
+type EventOptions struct {
+	Entity   string // Entity the event is about
+	Producer string // Producer the relay publishes the event with, found by payload when empty
+	On       string // Service operation publishing the event: create, update or delete
+}
+
+// GenerateEvent generates a domain event record, publishes it to the outbox from the
+// entity's service, and routes it from the outbox relay to an SQS or Kafka producer
+func (g *EventGenerator) GenerateEvent(name string, opts EventOptions) error {
+	name = util.ToJavaClassName(name)
+	entityName := util.ToJavaClassName(opts.Entity)
+	if entityName == "" {
+		return fmt.Errorf("entity is required")
+	}
+	on := opts.On
+	if on == "" {
+		on = "create"
+		switch {
+		case strings.HasSuffix(name, "Updated"):
+			on = "update"
+		case strings.HasSuffix(name, "Deleted"):
+			on = "delete"
+		}
+	}
+	if on != "create" && on != "update" && on != "delete" {
+		return fmt.Errorf("unsupported operation: %s, use create, update or delete", on)
+	}
+
+	javaDir := basePackageDir(g.Config, g.ProjectDir)
+	entity, err := ParseEntity(filepath.Join(javaDir, "domain/entity", entityName+".java"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("entity %s not found, generate it first with 'springwell generate entity %s'", entityName, entityName)
+		}
+		return err
+	}
+	servicePath := filepath.Join(javaDir, "service", entityName+"Service.java")
+	if !fileExists(servicePath) {
+		return fmt.Errorf("service %sService not found, generate the entity with its service first", entityName)
+	}
+	producer, err := g.findProducer(name, opts.Producer)
+	if err != nil {
+		return err
+	}
+
+	fields := entity.Fields
+	if on == "delete" {
+		fields = nil
+	}
+	data := map[string]interface{}{
+		"package":     g.Config.Project.Package,
+		"name":        name,
+		"entity":      entityName,
+		"entityCamel": util.ToJavaVariableName(entityName),
+		"fields":      fields,
+		"imports":     javaImports(fields, "java.time.Instant"),
+		"on":          on,
+		"producer":    strings.TrimSuffix(producer.Class, "Producer"),
+		"payload":     producer.Payload,
+		"broker":      producer.Broker,
+		"lombok":      g.Config.Code.Lombok,
+		"properties": []map[string]string{
+			{"name": "id", "type": "Long"},
+			{"name": "aggregateType", "type": "String"},
+			{"name": "aggregateId", "type": "String"},
+			{"name": "eventType", "type": "String"},
+			{"name": "payload", "type": "String"},
+			{"name": "createdAt", "type": "Instant"},
+			{"name": "publishedAt", "type": "Instant"},
+			{"name": "attempts", "type": "int"},
+		},
+	}
+
+	if err := g.ensureOutbox(data); err != nil {
+		return err
+	}
+	if err := renderTemplate(g.Config, g.ProjectDir, "outbox/event.tmpl",
+		filepath.Join(javaDir, "domain/event", name+".java"), data); err != nil {
+		return err
+	}
+	if err := renderTemplate(g.Config, g.ProjectDir, "outbox/event_route.tmpl",
+		filepath.Join(javaDir, "outbox", name+"OutboxRoute.java"), data); err != nil {
+		return err
+	}
+	return g.publishFromService(servicePath, data)
+}
+
+// findProducer returns the producer the relay publishes an event with: the named one, or
+// the one whose payload is named after the event
+func (g *EventGenerator) findProducer(event, producer string) (MessagingEndpoint, error) {
+	endpoints, err := NewAsyncAPIGenerator(g.Config, g.ProjectDir).ScanMessaging()
+	if err != nil {
+		return MessagingEndpoint{}, err
+	}
+	producer = strings.TrimSuffix(util.ToJavaClassName(producer), "Producer")
+	for _, endpoint := range endpoints {
+		if endpoint.Action != "send" {
+			continue
+		}
+		if (producer != "" && endpoint.Class == producer+"Producer") || (producer == "" && endpoint.Payload == event) {
+			if endpoint.Avro {
+				return MessagingEndpoint{}, fmt.Errorf("%s sends Avro payloads, which the outbox relay cannot build from the JSON it stores", endpoint.Class)
+			}
+			return endpoint, nil
+		}
+	}
+	if producer != "" {
+		return MessagingEndpoint{}, fmt.Errorf("producer %sProducer not found in messaging/", producer)
+	}
+	return MessagingEndpoint{}, fmt.Errorf("no producer with a %s payload found, generate one with 'springwell generate producer --payload %s ...' or pass --producer", event, event)
+}
+
+// ensureOutbox writes the outbox table, entity, publisher and relay shared by all events
+func (g *EventGenerator) ensureOutbox(data map[string]interface{}) error {
+	javaDir := basePackageDir(g.Config, g.ProjectDir)
+	files := []struct {
+		template string
+		path     string
+	}{
+		{"outbox/domain_event.tmpl", filepath.Join(javaDir, "domain/event", "DomainEvent.java")},
+		{"outbox/outbox_event.tmpl", filepath.Join(javaDir, "domain/entity", "OutboxEvent.java")},
+		{"outbox/outbox_repository.tmpl", filepath.Join(javaDir, "repository", "OutboxEventRepository.java")},
+		{"outbox/outbox_publisher.tmpl", filepath.Join(javaDir, "outbox", "OutboxPublisher.java")},
+		{"outbox/outbox_route.tmpl", filepath.Join(javaDir, "outbox", "OutboxRoute.java")},
+		{"outbox/outbox_relay.tmpl", filepath.Join(javaDir, "outbox", "OutboxRelay.java")},
+	}
+	for _, file := range files {
+		if fileExists(file.path) {
+			continue
+		}
+		if err := renderTemplate(g.Config, g.ProjectDir, file.template, file.path, data); err != nil {
+			return err
+		}
+	}
+
+	scheduling, err := sourcesContain(filepath.Join(g.ProjectDir, "src/main/java"), "@EnableScheduling")
+	if err != nil {
+		return err
+	}
+	if !scheduling {
+		if err := renderTemplate(g.Config, g.ProjectDir, "outbox/outbox_config.tmpl",
+			filepath.Join(javaDir, "config", "OutboxConfig.java"), data); err != nil {
+			return err
+		}
+	}
+
+	if err := addApplicationConfig(g.ProjectDir, "outbox:\n  relay:\n    interval-ms: 1000\n    batch-size: 100\n"); err != nil {
+		return err
+	}
+	return g.addOutboxMigration()
+}
+
+// addOutboxMigration writes a Flyway migration creating the outbox_events table, unless
+// one of the project's migrations already does
+func (g *EventGenerator) addOutboxMigration() error {
+	migrationDir := filepath.Join(g.ProjectDir, "src/main/resources/db/migration")
+	entries, err := os.ReadDir(migrationDir)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	version := 0
+	for _, entry := range entries {
+		match := migrationVersionPattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(migrationDir, entry.Name()))
+		if err != nil {
+			return err
+		}
+		if strings.Contains(string(content), "outbox_events") {
+			return nil
+		}
+		if n, _ := strconv.Atoi(match[1]); n > version {
+			version = n
+		}
+	}
+
+	timestamp, index := "TIMESTAMP WITH TIME ZONE", ""
+	id := "BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY"
+	switch g.Config.Project.Database {
+	case "postgres":
+		id = "BIGSERIAL PRIMARY KEY"
+		index = "CREATE INDEX idx_outbox_events_unpublished ON outbox_events (id) WHERE published_at IS NULL;\n"
+	case "mysql":
+		timestamp = "TIMESTAMP(6)"
+		id = "BIGINT AUTO_INCREMENT PRIMARY KEY"
+	}
+	if index == "" {
+		index = "CREATE INDEX idx_outbox_events_published_at ON outbox_events (published_at, id);\n"
+	}
+	sql := `-- Domain events waiting to be published by OutboxRelay
+CREATE TABLE outbox_events (
+    id ` + id + `,
+    aggregate_type VARCHAR(255) NOT NULL,
+    aggregate_id VARCHAR(255) NOT NULL,
+    event_type VARCHAR(255) NOT NULL,
+    payload TEXT NOT NULL,
+    created_at ` + timestamp + ` NOT NULL,
+    published_at ` + timestamp + `,
+    attempts INT NOT NULL DEFAULT 0
+);
+
+` + index
+
+	path := filepath.Join(migrationDir, fmt.Sprintf("V%d__create_outbox_events.sql", version+1))
+	if err := util.WriteFile(path, sql); err != nil {
+		return err
+	}
+	util.PrintInfo("Added migration %s", filepath.Base(path))
+
+	pomPath := filepath.Join(g.ProjectDir, "pom.xml")
+	if !util.HasMavenDependency(pomPath, "org.flywaydb", "flyway-core") {
+		util.PrintWarning("Flyway is not set up, add org.flywaydb:flyway-core or create the outbox_events table from %s yourself",
+			filepath.Join("src/main/resources/db/migration", filepath.Base(path)))
+	}
+	return nil
+}
+
+// publishFromService makes the entity's service record the event in the outbox when it
+// creates, updates or deletes an entity
+func (g *EventGenerator) publishFromService(servicePath string, data map[string]interface{}) error {
+	content, err := os.ReadFile(servicePath)
+	if err != nil {
+		return err
+	}
+	source := string(content)
+	name := data["name"].(string)
+	entity := data["entity"].(string)
+	entityCamel := data["entityCamel"].(string)
+	pkg := data["package"].(string)
+	if strings.Contains(source, name+".from(") || strings.Contains(source, name+".of(") {
+		util.PrintWarning("%sService already publishes %s", entity, name)
+		return nil
+	}
+
+	source, err = addOutboxPublisher(source, entity)
+	if err != nil {
+		return fmt.Errorf("%sService: %v", entity, err)
+	}
+	source = addJavaImports(source, pkg+".domain.event."+name, pkg+".outbox.OutboxPublisher")
+
+	publish := "outboxPublisher.publish(" + name + ".from(saved));"
+	var method string
+	switch {
+	case data["on"] == "delete":
+		method = "deleteById("
+		publish = "outboxPublisher.publish(" + name + ".of(id));"
+	case strings.Contains(source, " save("+entity+" "):
+		method = "save("
+	case data["on"] == "create":
+		method = "create("
+	default:
+		method = "update("
+	}
+
+	start := strings.Index(source, " "+method)
+	if start < 0 {
+		return fmt.Errorf("%sService has no %s method to publish %s from", entity, strings.TrimSuffix(method, "("), name)
+	}
+	bodyStart := start + strings.Index(source[start:], "{") + 1
+	bodyEnd := bodyStart + blockEnd(source[bodyStart:])
+	body := source[bodyStart:bodyEnd]
+
+	if method == "deleteById(" {
+		deletion := entityCamel + "Repository.deleteById(id);"
+		idx := strings.Index(body, deletion)
+		if idx < 0 {
+			return fmt.Errorf("%sService.deleteById does not call %sRepository.deleteById", entity, entityCamel)
+		}
+		indent := lineIndent(body, idx)
+		body = body[:idx+len(deletion)] + "\n" + indent + publish + body[idx+len(deletion):]
+	} else {
+		// Keep the saved entity in a variable the event is built from
+		save := entityCamel + "Repository.save(" + entityCamel + ")"
+		if idx := strings.Index(body, save); idx >= 0 && !strings.Contains(body, entity+" saved = ") {
+			lineStart := strings.LastIndex(body[:idx], "\n") + 1
+			indent := lineIndent(body, idx)
+			body = body[:lineStart] + indent + entity + " saved = " + save + ";\n" +
+				body[lineStart:idx] + "saved" + body[idx+len(save):]
+		}
+		if method == "save(" {
+			// save both creates and updates entities, tell them apart by their ID
+			if !strings.Contains(body, "boolean created = ") {
+				idx := strings.Index(body, entity+" saved = ")
+				indent := lineIndent(body, idx)
+				body = body[:idx-len(indent)] + indent + "boolean created = " + entityCamel + ".getId() == null;\n" + body[idx-len(indent):]
+			}
+			condition := "created"
+			if data["on"] == "update" {
+				condition = "!created"
+			}
+			publish = "if (" + condition + ") {\n" + "    " + publish + "\n}"
+		}
+
+		savedIdx := strings.Index(body, entity+" saved = ")
+		returnIdx := strings.Index(body[max(savedIdx, 0):], "return ")
+		if savedIdx < 0 || returnIdx < 0 {
+			return fmt.Errorf("could not find where %sService.%s returns the saved %s", entity, strings.TrimSuffix(method, "("), entity)
+		}
+		returnIdx += savedIdx
+		indent := lineIndent(body, returnIdx)
+		var lines []string
+		for _, line := range strings.Split(publish, "\n") {
+			lines = append(lines, indent+line)
+		}
+		lineStart := strings.LastIndex(body[:returnIdx], "\n") + 1
+		body = body[:lineStart] + strings.Join(lines, "\n") + "\n" + body[lineStart:]
+	}
+
+	source = source[:bodyStart] + body + source[bodyEnd:]
+	util.PrintInfo("Publishing %s from %sService.%s", name, entity, strings.TrimSuffix(method, "("))
+	return util.WriteFile(servicePath, source)
+}
+
+// addOutboxPublisher injects OutboxPublisher into a service through its constructor
+func addOutboxPublisher(source, entity string) (string, error) {
+	if strings.Contains(source, "OutboxPublisher outboxPublisher") {
+		return source, nil
+	}
+	fields := regexp.MustCompile(`(?m)^([ \t]*)private final [\w<>, ]+ \w+;\n`).FindAllStringSubmatchIndex(source, -1)
+	if fields == nil {
+		return "", fmt.Errorf("no final fields found to add OutboxPublisher to")
+	}
+	last := fields[len(fields)-1]
+	source = source[:last[1]] + source[last[2]:last[3]] + "private final OutboxPublisher outboxPublisher;\n" + source[last[1]:]
+
+	constructor := regexp.MustCompile(`public ` + entity + `Service\([^)]*\)\s*\{`).FindStringIndex(source)
+	if constructor == nil {
+		if strings.Contains(source, "@RequiredArgsConstructor") {
+			return source, nil
+		}
+		return "", fmt.Errorf("no constructor found to inject OutboxPublisher into")
+	}
+	params := strings.LastIndex(source[:constructor[1]], ")")
+	bodyEnd := constructor[1] + blockEnd(source[constructor[1]:])
+	lastStatement := strings.LastIndex(source[:bodyEnd], ";") + 1
+	if lastStatement <= constructor[1] {
+		return "", fmt.Errorf("the constructor assigns no fields")
+	}
+	// The assignment goes after the others, then the parameter, which comes before them
+	source = source[:lastStatement] + "\n" + lineIndent(source, lastStatement-1) +
+		"this.outboxPublisher = outboxPublisher;" + source[lastStatement:]
+	source = source[:params] + ", OutboxPublisher outboxPublisher" + source[params:]
+	return source, nil
+}
+
+// addJavaImports adds imports to the first import block of a Java source, in order
+func addJavaImports(source string, imports ...string) string {
+	lines := strings.Split(source, "\n")
+	for _, imp := range imports {
+		statement := "import " + imp + ";"
+		if strings.Contains(strings.Join(lines, "\n"), statement) {
+			continue
+		}
+		at := -1
+		for i, line := range lines {
+			if !strings.HasPrefix(line, "import ") || strings.HasPrefix(line, "import static ") {
+				if at >= 0 {
+					break
+				}
+				continue
+			}
+			if line > statement {
+				at = i
+				break
+			}
+			at = i + 1
+		}
+		if at < 0 {
+			// No imports yet, start a block after the package declaration
+			for i, line := range lines {
+				if strings.HasPrefix(line, "package ") {
+					at = i + 1
+					lines = append(lines[:at], append([]string{""}, lines[at:]...)...)
+					at++
+					break
+				}
+			}
+		}
+		lines = append(lines[:at], append([]string{statement}, lines[at:]...)...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// blockEnd returns the offset of the brace closing the block whose body starts the source
+func blockEnd(source string) int {
+	depth := 1
+	for i, c := range source {
+		switch c {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(source)
+}
+
+// lineIndent returns the indentation of the line containing an offset
+func lineIndent(source string, offset int) string {
+	lineStart := strings.LastIndex(source[:offset], "\n") + 1
+	line := source[lineStart:]
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
@@ -0,0 +1,299 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// javaTypeImports maps field types that live outside java.lang to their import
+var javaTypeImports = map[string]string{
+	"BigDecimal":     "java.math.BigDecimal",
+	"BigInteger":     "java.math.BigInteger",
+	"Date":           "java.util.Date",
+	"Instant":        "java.time.Instant",
+	"LocalDate":      "java.time.LocalDate",
+	"LocalDateTime":  "java.time.LocalDateTime",
+	"LocalTime":      "java.time.LocalTime",
+	"OffsetDateTime": "java.time.OffsetDateTime",
+	"UUID":           "java.util.UUID",
+	"ZonedDateTime":  "java.time.ZonedDateTime",
+}
+
+// javaImports returns the sorted, de-duplicated imports needed by the given fields
+// plus any extra imports
+func javaImports(fields []map[string]string, extra ...string) []string {
+	seen := map[string]bool{}
+	for _, imp := range extra {
+		seen[imp] = true
+	}
+	for _, field := range fields {
+		if imp, ok := javaTypeImports[field["type"]]; ok {
+			seen[imp] = true
+		}
+	}
+
+	imports := make([]string, 0, len(seen))
+	for imp := range seen {
+		imports = append(imports, imp)
+	}
+	sort.Strings(imports)
+	return imports
+}
+
+// validationAnnotations returns the Bean Validation annotations for a request field,
+// each followed by a space so the result can be placed directly before the type
+func validationAnnotations(field map[string]string) string {
+	var annotations []string
+	if field["nullable"] != "true" && !isPrimitive(field["type"]) {
+		if field["type"] == "String" {
+			annotations = append(annotations, "@NotBlank")
+		} else {
+			annotations = append(annotations, "@NotNull")
+		}
+	}
+	if field["type"] == "String" {
+		annotations = append(annotations, "@Size(max = 255)")
+	}
+	if len(annotations) == 0 {
+		return ""
+	}
+	return strings.Join(annotations, " ") + " "
+}
+
+// capitalize upper-cases the first letter of a Java identifier, e.g. for setter names
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// getterName returns the accessor name Lombok and JavaBeans use for a property
+func getterName(property map[string]string) string {
+	if property["type"] == "boolean" {
+		return "is" + capitalize(property["name"])
+	}
+	return "get" + capitalize(property["name"])
+}
+
+// isPrimitive reports whether a Java type is a primitive, which can never be null
+func isPrimitive(javaType string) bool {
+	switch javaType {
+	case "boolean", "byte", "char", "short", "int", "long", "float", "double":
+		return true
+	}
+	return false
+}
+
+// relationIDField returns the DTO property that carries a relation's IDs,
+// e.g. customer -> customerId and items -> itemIds
+func relationIDField(relation map[string]string) string {
+	if relation["type"] == "oneToMany" || relation["type"] == "manyToMany" {
+		return strings.TrimSuffix(relation["field"], "s") + "Ids"
+	}
+	return relation["field"] + "Id"
+}
+
+// boxedType returns the wrapper type of a Java primitive, or the type itself
+func boxedType(javaType string) string {
+	switch javaType {
+	case "boolean":
+		return "Boolean"
+	case "byte":
+		return "Byte"
+	case "char":
+		return "Character"
+	case "short":
+		return "Short"
+	case "int":
+		return "Integer"
+	case "long":
+		return "Long"
+	case "float":
+		return "Float"
+	case "double":
+		return "Double"
+	}
+	return javaType
+}
+
+// filterOperation returns how a field is filtered: "like" for text,
+// "range" for numbers and dates, and "eq" for everything else
+func filterOperation(field map[string]string) string {
+	switch boxedType(field["type"]) {
+	case "String":
+		return "like"
+	case "Short", "Integer", "Long", "Float", "Double", "BigDecimal", "BigInteger",
+		"Date", "Instant", "LocalDate", "LocalDateTime", "LocalTime", "OffsetDateTime", "ZonedDateTime":
+		return "range"
+	}
+	return "eq"
+}
+
+// dateTimeFormat returns the @DateTimeFormat annotation needed to bind a
+// temporal field from a query parameter, followed by a space
+func dateTimeFormat(field map[string]string) string {
+	switch field["type"] {
+	case "LocalDate":
+		return "@DateTimeFormat(iso = DateTimeFormat.ISO.DATE) "
+	case "LocalTime":
+		return "@DateTimeFormat(iso = DateTimeFormat.ISO.TIME) "
+	case "LocalDateTime", "OffsetDateTime", "ZonedDateTime":
+		return "@DateTimeFormat(iso = DateTimeFormat.ISO.DATE_TIME) "
+	}
+	return ""
+}
+
+// sortableFields returns the properties clients may sort a list endpoint by
+func sortableFields(fields []map[string]string, audit bool) []string {
+	sortable := []string{"id"}
+	for _, field := range fields {
+		sortable = append(sortable, field["name"])
+	}
+	if audit {
+		sortable = append(sortable, "createdAt", "updatedAt")
+	}
+	return sortable
+}
+
+// entityProperties returns the name and Java type of every persistent property of an
+// entity, in declaration order, for accessors and test fixtures
+func entityProperties(fields, relations []map[string]string, audit bool) []map[string]string {
+	properties := []map[string]string{{"name": "id", "type": "Long"}}
+	for _, field := range fields {
+		properties = append(properties, map[string]string{"name": field["name"], "type": field["type"]})
+	}
+	for _, relation := range relations {
+		javaType := relation["entity"]
+		if relation["type"] == "oneToMany" || relation["type"] == "manyToMany" {
+			javaType = "List<" + javaType + ">"
+		}
+		properties = append(properties, map[string]string{"name": relation["field"], "type": javaType, "relation": relation["type"]})
+	}
+	if audit {
+		properties = append(properties,
+			map[string]string{"name": "createdAt", "type": "LocalDateTime"},
+			map[string]string{"name": "updatedAt", "type": "LocalDateTime"})
+	}
+	return properties
+}
+
+// sampleValue returns a Java expression producing sample data for a field. Different
+// values of n give different samples, so tests can tell created and updated data apart
+func sampleValue(field map[string]string, n int) string {
+	switch field["type"] {
+	case "String":
+		return fmt.Sprintf("\"%s %d\"", field["name"], n)
+	case "int", "Integer":
+		return fmt.Sprintf("%d", n)
+	case "long", "Long":
+		return fmt.Sprintf("%dL", n)
+	case "short", "Short":
+		return fmt.Sprintf("(short) %d", n)
+	case "byte", "Byte":
+		return fmt.Sprintf("(byte) %d", n)
+	case "double", "Double":
+		return fmt.Sprintf("%d.5", n)
+	case "float", "Float":
+		return fmt.Sprintf("%d.5f", n)
+	case "boolean", "Boolean":
+		return fmt.Sprintf("%t", n%2 == 1)
+	case "char", "Character":
+		return fmt.Sprintf("'%c'", 'a'+rune(n))
+	case "BigDecimal":
+		return fmt.Sprintf("new BigDecimal(\"%d.50\")", n)
+	case "BigInteger":
+		return fmt.Sprintf("BigInteger.valueOf(%d)", n)
+	case "UUID":
+		return fmt.Sprintf("UUID.fromString(\"00000000-0000-0000-0000-%012d\")", n)
+	case "LocalDate":
+		return fmt.Sprintf("LocalDate.of(2024, 1, %d)", n)
+	case "LocalTime":
+		return fmt.Sprintf("LocalTime.of(10, %d)", n)
+	case "LocalDateTime":
+		return fmt.Sprintf("LocalDateTime.of(2024, 1, %d, 10, 0)", n)
+	case "OffsetDateTime":
+		return fmt.Sprintf("OffsetDateTime.of(2024, 1, %d, 10, 0, 0, 0, ZoneOffset.UTC)", n)
+	case "ZonedDateTime":
+		return fmt.Sprintf("ZonedDateTime.of(2024, 1, %d, 10, 0, 0, 0, ZoneOffset.UTC)", n)
+	case "Instant":
+		return fmt.Sprintf("Instant.parse(\"2024-01-%02dT10:00:00Z\")", n)
+	case "Date":
+		return fmt.Sprintf("new Date(%dL)", 1704067200000+int64(n)*86400000)
+	}
+	return "null"
+}
+
+// jsonSampleValue returns the JSON value of the sample of a field that sampleValue builds in
+// Java, or nil if the field has no sample, such as an enum
+func jsonSampleValue(field map[string]string, n int) interface{} {
+	switch field["type"] {
+	case "String":
+		return fmt.Sprintf("%s %d", field["name"], n)
+	case "int", "Integer", "long", "Long", "short", "Short", "byte", "Byte", "BigInteger":
+		return n
+	case "double", "Double", "float", "Float", "BigDecimal":
+		return float64(n) + 0.5
+	case "boolean", "Boolean":
+		return n%2 == 1
+	case "char", "Character":
+		return string('a' + rune(n))
+	case "UUID":
+		return fmt.Sprintf("00000000-0000-0000-0000-%012d", n)
+	case "LocalDate":
+		return fmt.Sprintf("2024-01-%02d", n)
+	case "LocalTime":
+		return fmt.Sprintf("10:%02d:00", n)
+	case "LocalDateTime":
+		return fmt.Sprintf("2024-01-%02dT10:00:00", n)
+	case "OffsetDateTime", "ZonedDateTime", "Instant", "Date":
+		return fmt.Sprintf("2024-01-%02dT10:00:00Z", n)
+	}
+	return nil
+}
+
+// sampleImports returns the imports needed by sample values beyond the field types themselves
+func sampleImports(fields []map[string]string) []string {
+	for _, field := range fields {
+		if field["type"] == "OffsetDateTime" || field["type"] == "ZonedDateTime" {
+			return []string{"java.time.ZoneOffset"}
+		}
+	}
+	return nil
+}
+
+// assertEquals returns the AssertJ assertion comparing a field read back from the
+// database with the value that was saved, tolerating changes in scale or zone
+func assertEquals(field map[string]string) string {
+	switch field["type"] {
+	case "BigDecimal":
+		return "isEqualByComparingTo"
+	case "OffsetDateTime", "ZonedDateTime":
+		return "isAtSameInstantAs"
+	case "Date":
+		return "hasSameTimeAs"
+	}
+	return "isEqualTo"
+}
+
+// sampleFilterField returns the first field whose sample values can be told apart
+// by a filter, or nil if there is none
+func sampleFilterField(fields []map[string]string) map[string]string {
+	for _, field := range fields {
+		if sampleValue(field, 1) != "null" {
+			return field
+		}
+	}
+	return nil
+}
+
+// hasRequiredFields reports whether a create request rejects an empty body
+func hasRequiredFields(fields []map[string]string) bool {
+	for _, field := range fields {
+		if field["nullable"] != "true" && !isPrimitive(field["type"]) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,316 @@
+package generator
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/springwell/cli/pkg/config"
+	"github.com/springwell/cli/pkg/util"
+)
+
+const (
+	// Kafka image providing a single KRaft broker in compose.yaml
+	kafkaImage = "apache/kafka:3.8.0"
+	// Confluent Schema Registry image used for Avro payloads
+	schemaRegistryImage = "confluentinc/cp-schema-registry:7.7.1"
+	// Version of the Confluent Avro serializers, matching the schema registry
+	confluentVersion = "7.7.1"
+	// Version of Apache Avro and its Maven plugin
+	avroVersion = "1.12.0"
+	// Suffix of the dead-letter topic the error handler publishes failed records to
+	kafkaDeadLetterSuffix = "-dlt"
+)
+
+// avroLogicalType is an Avro type annotated with a logical type
+type avroLogicalType struct {
+	Type        string `json:"type"`
+	LogicalType string `json:"logicalType"`
+	Precision   int    `json:"precision,omitempty"`
+	Scale       int    `json:"scale,omitempty"`
+}
+
+// avroTypes maps Java field types to Avro schema types
+var avroTypes = map[string]interface{}{
+	"String":        "string",
+	"int":           "int",
+	"Integer":       "int",
+	"long":          "long",
+	"Long":          "long",
+	"float":         "float",
+	"Float":         "float",
+	"double":        "double",
+	"Double":        "double",
+	"boolean":       "boolean",
+	"Boolean":       "boolean",
+	"UUID":          avroLogicalType{Type: "string", LogicalType: "uuid"},
+	"LocalDate":     avroLogicalType{Type: "int", LogicalType: "date"},
+	"Instant":       avroLogicalType{Type: "long", LogicalType: "timestamp-millis"},
+	"LocalDateTime": avroLogicalType{Type: "long", LogicalType: "local-timestamp-millis"},
+	"BigDecimal":    avroLogicalType{Type: "bytes", LogicalType: "decimal", Precision: 19, Scale: 2},
+}
+
+// AddKafka adds Spring Kafka to a project: the dependency, a local broker in compose.yaml,
+// the connection settings and an error handler publishing failed records to dead-letter topics
+func AddKafka(cfg *config.Config, projectDir string, avro bool) error {
+	pomPath := filepath.Join(projectDir, "pom.xml")
+	name := "app"
+	if _, err := os.Stat(pomPath); err != nil {
+		util.PrintWarning("No pom.xml found, add org.springframework.kafka:spring-kafka to your build manually")
+	} else {
+		added, err := util.AddMavenDependency(pomPath, util.MavenDependency{
+			GroupID:    "org.springframework.kafka",
+			ArtifactID: "spring-kafka",
+		})
+		if err != nil {
+			return err
+		}
+		if added {
+			util.PrintInfo("Added Spring Kafka dependency to pom.xml")
+		}
+		if avro {
+			if err := addAvroBuild(pomPath); err != nil {
+				return err
+			}
+		}
+		if project, err := util.ReadMavenProject(pomPath); err == nil && project.ArtifactID != "" {
+			name = project.ArtifactID
+		}
+	}
+
+	data := map[string]interface{}{
+		"package": cfg.Project.Package,
+		"avro":    avro,
+	}
+	configPath := filepath.Join(projectDir, "src/main/java", strings.ReplaceAll(cfg.Project.Package, ".", "/"), "config", "KafkaConfig.java")
+	if !fileExists(configPath) {
+		if err := renderTemplate(cfg, projectDir, "messaging/kafka_config.tmpl", configPath, data); err != nil {
+			return err
+		}
+	} else if avro {
+		if err := addAvroDeadLetterSerializer(configPath); err != nil {
+			return err
+		}
+	}
+
+	if err := addApplicationConfig(projectDir, kafkaApplicationConfig(name, avro)); err != nil {
+		return err
+	}
+	if err := util.MergeYAML(filepath.Join(projectDir, "compose.yaml"), kafkaComposeConfig(avro)); err != nil {
+		return err
+	}
+	if avro {
+		util.PrintInfo("Added Kafka and Schema Registry services to compose.yaml")
+	} else {
+		util.PrintInfo("Added a Kafka broker to compose.yaml")
+	}
+	return nil
+}
+
+// addAvroDeadLetterSerializer makes the dead-letter publisher of an existing KafkaConfig
+// serialize Avro records with the Avro serializer instead of JSON
+func addAvroDeadLetterSerializer(configPath string) error {
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		return err
+	}
+	source := string(content)
+	if strings.Contains(source, "KafkaAvroSerializer") {
+		return nil
+	}
+	jsonSerializer := "serializers.put(Object.class, new JsonSerializer<>());"
+	idx := strings.Index(source, jsonSerializer)
+	if idx < 0 {
+		util.PrintWarning("Could not find the dead-letter serializers in %s, add a KafkaAvroSerializer for Avro records manually",
+			filepath.Base(configPath))
+		return nil
+	}
+	lineStart := strings.LastIndex(source[:idx], "\n") + 1
+	source = source[:lineStart] + source[lineStart:idx] + "serializers.put(SpecificRecord.class, new KafkaAvroSerializer());\n" + source[lineStart:]
+	source = strings.Replace(source, "import org.apache.kafka.common.TopicPartition;",
+		"import io.confluent.kafka.serializers.KafkaAvroSerializer;\n"+
+			"import org.apache.avro.specific.SpecificRecord;\n"+
+			"import org.apache.kafka.common.TopicPartition;", 1)
+	util.PrintInfo("Adding an Avro serializer for dead-letter records to %s", filepath.Base(configPath))
+	return util.WriteFile(configPath, source)
+}
+
+// ensureKafkaSetup adds Spring Kafka to the project and the configuration key of the topic
+func (g *MessagingGenerator) ensureKafkaSetup(data map[string]interface{}) error {
+	if err := AddKafka(g.Config, g.ProjectDir, data["avro"].(bool)); err != nil {
+		return err
+	}
+
+	topic := data["topic"].(string)
+	fragment := "kafka:\n  topics:\n    " + topic + ":\n      name: " + topic + "\n"
+	if err := addApplicationConfig(g.ProjectDir, fragment); err != nil {
+		return err
+	}
+	util.PrintInfo("Added %s configuration for topic %s", data["topicProperty"], topic)
+	return nil
+}
+
+// addAvroBuild adds the Avro serializers and the plugin generating payload classes from
+// the schemas in src/main/avro
+func addAvroBuild(pomPath string) error {
+	dependencies := []util.MavenDependency{
+		{GroupID: "org.apache.avro", ArtifactID: "avro", Version: avroVersion},
+		{GroupID: "io.confluent", ArtifactID: "kafka-avro-serializer", Version: confluentVersion},
+	}
+	for _, dep := range dependencies {
+		added, err := util.AddMavenDependency(pomPath, dep)
+		if err != nil {
+			return err
+		}
+		if added {
+			util.PrintInfo("Added %s:%s dependency to pom.xml", dep.GroupID, dep.ArtifactID)
+		}
+	}
+
+	// The Confluent serializers are not published to Maven Central
+	if _, err := util.AddMavenRepository(pomPath, "confluent", "https://packages.confluent.io/maven/"); err != nil {
+		return err
+	}
+
+	added, err := util.AddMavenPlugin(pomPath, util.MavenDependency{
+		GroupID:    "org.apache.avro",
+		ArtifactID: "avro-maven-plugin",
+		Version:    avroVersion,
+	}, `<executions>
+	<execution>
+		<phase>generate-sources</phase>
+		<goals>
+			<goal>schema</goal>
+		</goals>
+		<configuration>
+			<sourceDirectory>${project.basedir}/src/main/avro</sourceDirectory>
+			<outputDirectory>${project.build.directory}/generated-sources/avro</outputDirectory>
+			<stringType>String</stringType>
+			<enableDecimalLogicalType>true</enableDecimalLogicalType>
+		</configuration>
+	</execution>
+</executions>`)
+	if err != nil {
+		return err
+	}
+	if added {
+		util.PrintInfo("Added avro-maven-plugin to pom.xml")
+	}
+	return nil
+}
+
+// generateAvroSchema writes the Avro schema of a payload to src/main/avro, from which
+// avro-maven-plugin generates the payload class
+func (g *MessagingGenerator) generateAvroSchema(data map[string]interface{}) error {
+	path := filepath.Join(g.ProjectDir, "src/main/avro", data["payload"].(string)+".avsc")
+	if fileExists(path) {
+		return nil
+	}
+	fields := data["fields"].([]map[string]string)
+	if len(fields) == 0 {
+		util.PrintWarning("No --fields given, %s is generated without fields", data["payload"])
+	}
+
+	type avroField struct {
+		Name    string          `json:"name"`
+		Type    interface{}     `json:"type"`
+		Default json.RawMessage `json:"default,omitempty"`
+	}
+	schemaFields := []avroField{}
+	for _, field := range fields {
+		avroType, ok := avroTypes[field["type"]]
+		if !ok {
+			util.PrintWarning("No Avro type for %s, field %s is generated as a string", field["type"], field["name"])
+			avroType = "string"
+		}
+		if field["nullable"] == "true" {
+			// A null default must come with null as the first type of the union
+			schemaFields = append(schemaFields, avroField{
+				Name:    field["name"],
+				Type:    []interface{}{"null", avroType},
+				Default: json.RawMessage("null"),
+			})
+			continue
+		}
+		schemaFields = append(schemaFields, avroField{Name: field["name"], Type: avroType})
+	}
+
+	schema, err := json.MarshalIndent(struct {
+		Type      string      `json:"type"`
+		Name      string      `json:"name"`
+		Namespace string      `json:"namespace"`
+		Doc       string      `json:"doc"`
+		Fields    []avroField `json:"fields"`
+	}{
+		Type:      "record",
+		Name:      data["payload"].(string),
+		Namespace: data["package"].(string) + ".messaging.payload",
+		Doc:       "Payload of the records on the " + data["topic"].(string) + " topic",
+		Fields:    schemaFields,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	util.PrintInfo("Writing Avro schema %s", filepath.Join("src/main/avro", filepath.Base(path)))
+	return util.WriteFile(path, string(schema)+"\n")
+}
+
+// kafkaApplicationConfig returns the application.yml keys connecting to the broker,
+// consumers joining the group of the application name, else of the project name
+func kafkaApplicationConfig(name string, avro bool) string {
+	config := `spring:
+  kafka:
+    bootstrap-servers: ${KAFKA_BOOTSTRAP_SERVERS:localhost:9092}
+    consumer:
+      group-id: ${spring.application.name:` + name + `}
+      auto-offset-reset: earliest
+`
+	if avro {
+		config += `    properties:
+      schema.registry.url: ${SCHEMA_REGISTRY_URL:http://localhost:8081}
+`
+	}
+	return config + `kafka:
+  error-handler:
+    max-attempts: 3
+    interval-ms: 1000
+`
+}
+
+// kafkaComposeConfig returns the compose.yaml services of a single-node broker, reachable
+// on localhost:9092 from the host and on kafka:29092 from other services
+func kafkaComposeConfig(avro bool) string {
+	config := `services:
+  kafka:
+    image: ` + kafkaImage + `
+    ports:
+      - "9092:9092"
+    environment:
+      KAFKA_NODE_ID: 1
+      KAFKA_PROCESS_ROLES: broker,controller
+      KAFKA_LISTENERS: PLAINTEXT://:9092,DOCKER://:29092,CONTROLLER://:9093
+      KAFKA_ADVERTISED_LISTENERS: PLAINTEXT://localhost:9092,DOCKER://kafka:29092
+      KAFKA_LISTENER_SECURITY_PROTOCOL_MAP: PLAINTEXT:PLAINTEXT,DOCKER:PLAINTEXT,CONTROLLER:PLAINTEXT
+      KAFKA_INTER_BROKER_LISTENER_NAME: DOCKER
+      KAFKA_CONTROLLER_LISTENER_NAMES: CONTROLLER
+      KAFKA_CONTROLLER_QUORUM_VOTERS: 1@localhost:9093
+      KAFKA_OFFSETS_TOPIC_REPLICATION_FACTOR: 1
+      KAFKA_TRANSACTION_STATE_LOG_REPLICATION_FACTOR: 1
+      KAFKA_TRANSACTION_STATE_LOG_MIN_ISR: 1
+`
+	if avro {
+		config += `  schema-registry:
+    image: ` + schemaRegistryImage + `
+    ports:
+      - "8081:8081"
+    environment:
+      SCHEMA_REGISTRY_HOST_NAME: schema-registry
+      SCHEMA_REGISTRY_LISTENERS: http://0.0.0.0:8081
+      SCHEMA_REGISTRY_KAFKASTORE_BOOTSTRAP_SERVERS: kafka:29092
+    depends_on:
+      - kafka
+`
+	}
+	return config
+}
@@ -0,0 +1,404 @@
+package generator
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/springwell/cli/pkg/util"
+)
+
+const (
+	// Keycloak image serving the dev realm in compose.yaml
+	keycloakImage = "quay.io/keycloak/keycloak:26.0"
+	// KeycloakURL is where the dev Keycloak is reachable from the host
+	KeycloakURL = "http://localhost:8180"
+	// KeycloakRealmFile is the realm, relative to the project, Keycloak imports on startup
+	KeycloakRealmFile = "keycloak/realm.json"
+)
+
+// keycloakDefaultRoles are the realm roles of a new project, before any access matrix
+var keycloakDefaultRoles = []string{"ADMIN", "USER"}
+
+type keycloakRealm struct {
+	Realm               string                         `json:"realm"`
+	Enabled             bool                           `json:"enabled"`
+	SSLRequired         string                         `json:"sslRequired"`
+	AccessTokenLifespan int                            `json:"accessTokenLifespan"`
+	Roles               keycloakRoles                  `json:"roles"`
+	Clients             []keycloakClient               `json:"clients"`
+	Users               []keycloakUser                 `json:"users"`
+	Components          map[string][]keycloakComponent `json:"components,omitempty"`
+}
+
+type keycloakRoles struct {
+	Realm []keycloakRole `json:"realm"`
+}
+
+type keycloakRole struct {
+	Name string `json:"name"`
+}
+
+type keycloakClient struct {
+	ClientID                  string                   `json:"clientId"`
+	Enabled                   bool                     `json:"enabled"`
+	PublicClient              bool                     `json:"publicClient"`
+	Secret                    string                   `json:"secret,omitempty"`
+	StandardFlowEnabled       bool                     `json:"standardFlowEnabled"`
+	DirectAccessGrantsEnabled bool                     `json:"directAccessGrantsEnabled"`
+	RedirectURIs              []string                 `json:"redirectUris,omitempty"`
+	WebOrigins                []string                 `json:"webOrigins,omitempty"`
+	Attributes                map[string]string        `json:"attributes,omitempty"`
+	ProtocolMappers           []keycloakProtocolMapper `json:"protocolMappers"`
+}
+
+type keycloakProtocolMapper struct {
+	Name           string            `json:"name"`
+	Protocol       string            `json:"protocol"`
+	ProtocolMapper string            `json:"protocolMapper"`
+	Config         map[string]string `json:"config"`
+}
+
+type keycloakUser struct {
+	Username      string               `json:"username"`
+	Enabled       bool                 `json:"enabled"`
+	Email         string               `json:"email"`
+	EmailVerified bool                 `json:"emailVerified"`
+	FirstName     string               `json:"firstName"`
+	LastName      string               `json:"lastName"`
+	Credentials   []keycloakCredential `json:"credentials"`
+	RealmRoles    []string             `json:"realmRoles"`
+}
+
+type keycloakCredential struct {
+	Type      string `json:"type"`
+	Value     string `json:"value"`
+	Temporary bool   `json:"temporary"`
+}
+
+type keycloakComponent struct {
+	Name       string              `json:"name"`
+	ProviderID string              `json:"providerId"`
+	Config     map[string][]string `json:"config"`
+}
+
+// KeycloakLogin holds what a test user of the dev realm needs to get a token
+type KeycloakLogin struct {
+	TokenURL string
+	ClientID string
+	Username string
+	Password string
+}
+
+// addKeycloak adds a Keycloak service to compose.yaml importing a dev realm with the
+// project's clients, roles and test users. For jwt the realm signs tokens with the dev
+// keypair, so the dev profile accepts them
+func addKeycloak(projectDir, name, auth, clientSecret string) error {
+	realmPath := filepath.Join(projectDir, KeycloakRealmFile)
+	if fileExists(realmPath) {
+		util.PrintWarning("%s already exists, leaving it unchanged", KeycloakRealmFile)
+	} else {
+		realm, err := keycloakDevRealm(projectDir, name, auth, clientSecret)
+		if err != nil {
+			return err
+		}
+		if err := writeKeycloakRealm(realmPath, realm); err != nil {
+			return err
+		}
+	}
+
+	if err := util.MergeYAML(filepath.Join(projectDir, "compose.yaml"), keycloakComposeConfig()); err != nil {
+		return err
+	}
+
+	if auth == "jwt" {
+		if err := addProfileConfig(projectDir, "dev", `spring:
+  security:
+    oauth2:
+      resourceserver:
+        jwt:
+          issuer-uri: `+KeycloakURL+`/realms/`+name+`
+`); err != nil {
+			return err
+		}
+	}
+
+	util.PrintInfo("Added Keycloak to compose.yaml with realm %s, get a dev token with 'springwell auth token --user alice'", name)
+	return nil
+}
+
+// keycloakDevRealm returns the realm of a new project: a public CLI client for direct
+// grants, for oauth2 the application's confidential client, the default roles and the
+// test users alice, holding every role, and bob, holding USER
+func keycloakDevRealm(projectDir, name, auth, clientSecret string) (*keycloakRealm, error) {
+	realm := &keycloakRealm{
+		Realm:               name,
+		Enabled:             true,
+		SSLRequired:         "none",
+		AccessTokenLifespan: 3600,
+	}
+	for _, role := range keycloakDefaultRoles {
+		realm.Roles.Realm = append(realm.Roles.Realm, keycloakRole{Name: role})
+	}
+
+	if auth == "oauth2" {
+		realm.Clients = append(realm.Clients, keycloakClient{
+			ClientID:                  name,
+			Enabled:                   true,
+			Secret:                    clientSecret,
+			StandardFlowEnabled:       true,
+			DirectAccessGrantsEnabled: true,
+			RedirectURIs:              []string{"http://localhost:8080/login/oauth2/code/oidc"},
+			WebOrigins:                []string{"http://localhost:8080"},
+			Attributes:                map[string]string{"post.logout.redirect.uris": "http://localhost:8080/*"},
+			ProtocolMappers:           []keycloakProtocolMapper{keycloakRolesMapper()},
+		})
+	}
+	realm.Clients = append(realm.Clients, keycloakClient{
+		ClientID:                  name + "-cli",
+		Enabled:                   true,
+		PublicClient:              true,
+		DirectAccessGrantsEnabled: true,
+		ProtocolMappers:           []keycloakProtocolMapper{keycloakRolesMapper()},
+	})
+
+	realm.Users = []keycloakUser{
+		keycloakTestUser("alice", "Alice", keycloakDefaultRoles),
+		keycloakTestUser("bob", "Bob", []string{"USER"}),
+	}
+
+	if auth == "jwt" {
+		component, err := keycloakDevKey(projectDir, name)
+		if err != nil {
+			return nil, err
+		}
+		realm.Components = map[string][]keycloakComponent{"org.keycloak.keys.KeyProvider": {component}}
+	}
+	return realm, nil
+}
+
+// keycloakRolesMapper puts the user's realm roles into the top-level roles claim the
+// generated SecurityConfig reads, rather than Keycloak's nested realm_access.roles
+func keycloakRolesMapper() keycloakProtocolMapper {
+	return keycloakProtocolMapper{
+		Name:           "realm roles",
+		Protocol:       "openid-connect",
+		ProtocolMapper: "oidc-usermodel-realm-role-mapper",
+		Config: map[string]string{
+			"claim.name":           "roles",
+			"jsonType.label":       "String",
+			"multivalued":          "true",
+			"access.token.claim":   "true",
+			"id.token.claim":       "true",
+			"userinfo.token.claim": "true",
+		},
+	}
+}
+
+// keycloakTestUser returns a test user whose password is its username
+func keycloakTestUser(username, firstName string, roles []string) keycloakUser {
+	return keycloakUser{
+		Username:      username,
+		Enabled:       true,
+		Email:         username + "@example.com",
+		EmailVerified: true,
+		FirstName:     firstName,
+		LastName:      "Test",
+		Credentials:   []keycloakCredential{{Type: "password", Value: username}},
+		RealmRoles:    append([]string(nil), roles...),
+	}
+}
+
+// keycloakDevKey returns a key provider signing the realm's tokens with the dev keypair,
+// along with the self-signed certificate Keycloak publishes for it
+func keycloakDevKey(projectDir, name string) (keycloakComponent, error) {
+	privatePEM, err := os.ReadFile(filepath.Join(projectDir, devKeysDir, "private.pem"))
+	if err != nil {
+		return keycloakComponent{}, err
+	}
+	block, _ := pem.Decode(privatePEM)
+	if block == nil {
+		return keycloakComponent{}, fmt.Errorf("%s/private.pem is not a PEM encoded key", devKeysDir)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return keycloakComponent{}, err
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return keycloakComponent{}, fmt.Errorf("%s/private.pem is not an RSA key", devKeysDir)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().Unix()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().AddDate(10, 0, 0),
+	}
+	certificate, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return keycloakComponent{}, err
+	}
+
+	return keycloakComponent{
+		Name:       "dev-keys",
+		ProviderID: "rsa",
+		Config: map[string][]string{
+			"privateKey":  {base64.StdEncoding.EncodeToString(block.Bytes)},
+			"certificate": {base64.StdEncoding.EncodeToString(certificate)},
+			"algorithm":   {"RS256"},
+			"priority":    {"200"},
+			"active":      {"true"},
+			"enabled":     {"true"},
+		},
+	}, nil
+}
+
+// writeKeycloakRealm writes a realm as indented JSON
+func writeKeycloakRealm(path string, realm interface{}) error {
+	content, err := json.MarshalIndent(realm, "", "  ")
+	if err != nil {
+		return err
+	}
+	return util.WriteFile(path, string(content)+"\n")
+}
+
+// addKeycloakRoles adds the roles of an access matrix to the dev realm, if the project has
+// one, and grants them to its first test user
+func addKeycloakRoles(projectDir string, roles []string) error {
+	realmPath := filepath.Join(projectDir, KeycloakRealmFile)
+	content, err := os.ReadFile(realmPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// The realm is edited as a generic document, so settings exported from Keycloak survive
+	var realm map[string]interface{}
+	if err := json.Unmarshal(content, &realm); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", KeycloakRealmFile, err)
+	}
+	realmRoles, _ := realm["roles"].(map[string]interface{})
+	if realmRoles == nil {
+		realmRoles = map[string]interface{}{}
+		realm["roles"] = realmRoles
+	}
+	existing, _ := realmRoles["realm"].([]interface{})
+	var user map[string]interface{}
+	if users, _ := realm["users"].([]interface{}); len(users) > 0 {
+		user, _ = users[0].(map[string]interface{})
+	}
+
+	var added []string
+	for _, role := range roles {
+		found := false
+		for _, r := range existing {
+			if entry, ok := r.(map[string]interface{}); ok && entry["name"] == role {
+				found = true
+				break
+			}
+		}
+		if found {
+			continue
+		}
+		existing = append(existing, map[string]interface{}{"name": role})
+		if user != nil {
+			granted, _ := user["realmRoles"].([]interface{})
+			user["realmRoles"] = append(granted, role)
+		}
+		added = append(added, role)
+	}
+	if len(added) == 0 {
+		return nil
+	}
+	realmRoles["realm"] = existing
+
+	if err := writeKeycloakRealm(realmPath, realm); err != nil {
+		return err
+	}
+	if user != nil {
+		util.PrintInfo("Added realm roles %s to %s and granted them to %v, recreate the Keycloak container to import them", strings.Join(added, ", "), KeycloakRealmFile, user["username"])
+	} else {
+		util.PrintInfo("Added realm roles %s to %s, recreate the Keycloak container to import them", strings.Join(added, ", "), KeycloakRealmFile)
+	}
+	return nil
+}
+
+// NewKeycloakLogin looks up how a test user of the dev realm gets a token from the Keycloak
+// at baseURL: the realm's public client allowing direct grants and, unless given, the
+// user's password
+func NewKeycloakLogin(projectDir, baseURL, username, password string) (*KeycloakLogin, error) {
+	content, err := os.ReadFile(filepath.Join(projectDir, KeycloakRealmFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%s not found, create the project with --auth jwt or --auth oauth2 to get a dev realm", KeycloakRealmFile)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var realm keycloakRealm
+	if err := json.Unmarshal(content, &realm); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", KeycloakRealmFile, err)
+	}
+
+	login := &KeycloakLogin{
+		TokenURL: strings.TrimSuffix(baseURL, "/") + "/realms/" + realm.Realm + "/protocol/openid-connect/token",
+		Username: username,
+		Password: password,
+	}
+	for _, client := range realm.Clients {
+		if client.PublicClient && client.DirectAccessGrantsEnabled {
+			login.ClientID = client.ClientID
+			break
+		}
+	}
+	if login.ClientID == "" {
+		return nil, fmt.Errorf("realm %s has no public client allowing direct access grants", realm.Realm)
+	}
+
+	if login.Password == "" {
+		for _, user := range realm.Users {
+			if user.Username != username {
+				continue
+			}
+			for _, credential := range user.Credentials {
+				if credential.Type == "password" {
+					login.Password = credential.Value
+				}
+			}
+		}
+		if login.Password == "" {
+			return nil, fmt.Errorf("no password for user %s in %s, pass it with --password", username, KeycloakRealmFile)
+		}
+	}
+	return login, nil
+}
+
+// keycloakComposeConfig returns the compose.yaml service running Keycloak in dev mode on
+// port 8180, importing the realm in keycloak/ when it starts
+func keycloakComposeConfig() string {
+	return `services:
+  keycloak:
+    image: ` + keycloakImage + `
+    command: start-dev --import-realm --http-port 8180
+    ports:
+      - "8180:8180"
+    environment:
+      KC_BOOTSTRAP_ADMIN_USERNAME: admin
+      KC_BOOTSTRAP_ADMIN_PASSWORD: admin
+    volumes:
+      - ./keycloak:/opt/keycloak/data/import
+`
+}
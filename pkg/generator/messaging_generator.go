@@ -0,0 +1,380 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/springwell/cli/pkg/config"
+	"github.com/springwell/cli/pkg/util"
+)
+
+const (
+	// Version of the AWS SDK for Java used by the aws project template
+	awsSdkVersion = "1.12.780"
+	// LocalStack image providing SQS in compose.yaml
+	localStackImage = "localstack/localstack:3.8"
+	// Account ID LocalStack uses in queue URLs and ARNs
+	localStackAccountID = "000000000000"
+)
+
+// placeholderPattern matches an innermost ${...} property placeholder
+var placeholderPattern = regexp.MustCompile(`\$\{([^${}]+)\}`)
+
+// MessagingGenerator generates message consumers and producers with typed payloads
+type MessagingGenerator struct {
+	Config     *config.Config
+	ProjectDir string
+}
+
+// NewMessagingGenerator creates a new MessagingGenerator
+func NewMessagingGenerator(config *config.Config, projectDir string) *MessagingGenerator {
+	return &MessagingGenerator{
+		Config:     config,
+		ProjectDir: projectDir,
+	}
+}
+
+// MessagingOptions controls what GenerateConsumer and GenerateProducer produce
+type MessagingOptions struct {
+	Broker            string // sqs or kafka, defaults to sqs
+	Queue             string // Queue or topic name, also used in configuration keys
+	Payload           string // Class the messages are (de)serialized to
+	Fields            string // Payload field definitions, used when the payload does not exist yet
+	Format            string // Kafka payload format, json or avro, defaults to json
+	MaxReceiveCount   int    // SQS deliveries before a message is moved to the dead-letter queue
+	VisibilityTimeout int    // Seconds a received SQS message stays hidden from other consumers
+}
+
+// GenerateConsumer generates a consumer that receives messages from a queue or topic,
+// deserializes each one to the payload and hands it to a processor
+func (g *MessagingGenerator) GenerateConsumer(name string, opts MessagingOptions) error {
+	data, err := g.templateData(name, opts)
+	if err != nil {
+		return err
+	}
+	if err := g.generatePayload(data); err != nil {
+		return err
+	}
+
+	messagingDir := filepath.Join(basePackageDir(g.Config, g.ProjectDir), "messaging")
+	processorPath := filepath.Join(messagingDir, data["name"].(string)+"Processor.java")
+	if fileExists(processorPath) {
+		util.PrintWarning("%s already exists, keeping it", filepath.Base(processorPath))
+	} else if err := renderTemplate(g.Config, g.ProjectDir, "messaging/processor.tmpl", processorPath, data); err != nil {
+		return err
+	}
+	if err := renderTemplate(g.Config, g.ProjectDir, "messaging/"+data["broker"].(string)+"_consumer.tmpl",
+		filepath.Join(messagingDir, data["name"].(string)+"Consumer.java"), data); err != nil {
+		return err
+	}
+
+	if data["broker"] == "kafka" {
+		return g.ensureKafkaSetup(data)
+	}
+	return g.ensureSqsSetup(data, true)
+}
+
+// GenerateProducer generates a producer that serializes the payload and sends it to
+// a queue or topic
+func (g *MessagingGenerator) GenerateProducer(name string, opts MessagingOptions) error {
+	data, err := g.templateData(name, opts)
+	if err != nil {
+		return err
+	}
+	if err := g.generatePayload(data); err != nil {
+		return err
+	}
+	if err := renderTemplate(g.Config, g.ProjectDir, "messaging/"+data["broker"].(string)+"_producer.tmpl",
+		filepath.Join(basePackageDir(g.Config, g.ProjectDir), "messaging", data["name"].(string)+"Producer.java"), data); err != nil {
+		return err
+	}
+
+	if data["broker"] == "kafka" {
+		return g.ensureKafkaSetup(data)
+	}
+	return g.ensureSqsSetup(data, false)
+}
+
+// templateData builds the template data shared by consumers and producers
+func (g *MessagingGenerator) templateData(name string, opts MessagingOptions) (map[string]interface{}, error) {
+	broker := opts.Broker
+	if broker == "" {
+		broker = "sqs"
+	}
+	format := opts.Format
+	if format == "" {
+		format = "json"
+	}
+	switch {
+	case broker != "sqs" && broker != "kafka":
+		return nil, fmt.Errorf("unsupported broker: %s, use sqs or kafka", broker)
+	case format != "json" && format != "avro":
+		return nil, fmt.Errorf("unsupported payload format: %s, use json or avro", format)
+	case format == "avro" && broker != "kafka":
+		return nil, fmt.Errorf("avro payloads are only supported with --broker kafka")
+	}
+
+	data, err := g.sqsTemplateData(name, opts)
+	if err != nil {
+		return nil, err
+	}
+	data["broker"] = broker
+	data["destination"] = "queue"
+	if broker == "kafka" {
+		topic := data["queue"].(string)
+		data["destination"] = "topic"
+		data["topic"] = topic
+		data["deadLetterTopic"] = topic + kafkaDeadLetterSuffix
+		data["topicProperty"] = "kafka.topics." + topic + ".name"
+		data["avro"] = format == "avro"
+	}
+	return data, nil
+}
+
+// sqsTemplateData builds the template data of a queue, which Kafka topics reuse
+func (g *MessagingGenerator) sqsTemplateData(name string, opts MessagingOptions) (map[string]interface{}, error) {
+	if opts.Queue == "" {
+		return nil, fmt.Errorf("queue is required")
+	}
+	if opts.Payload == "" {
+		return nil, fmt.Errorf("payload is required")
+	}
+	fields, err := util.ParseFieldDefinitions(opts.Fields)
+	if err != nil {
+		return nil, err
+	}
+
+	queue := strings.ReplaceAll(util.ToDatabaseTableName(opts.Queue), "_", "-")
+	region := g.Config.AWS.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+	return map[string]interface{}{
+		"name":              util.ToJavaClassName(name),
+		"package":           g.Config.Project.Package,
+		"queue":             queue,
+		"avro":              false,
+		"deadLetterQueue":   queue + "-dlq",
+		"queueProperty":     "aws.sqs.queues." + queue,
+		"queueEnv":          "SQS_" + strings.ToUpper(util.ToDatabaseTableName(opts.Queue)),
+		"payload":           util.ToJavaClassName(opts.Payload),
+		"payloadCamel":      util.ToJavaVariableName(opts.Payload),
+		"fields":            fields,
+		"payloadImports":    javaImports(fields),
+		"region":            region,
+		"maxReceiveCount":   opts.MaxReceiveCount,
+		"visibilityTimeout": opts.VisibilityTimeout,
+	}, nil
+}
+
+// generatePayload writes the payload record, or the Avro schema the payload class is
+// generated from, unless another consumer or producer already did
+func (g *MessagingGenerator) generatePayload(data map[string]interface{}) error {
+	if data["avro"].(bool) {
+		return g.generateAvroSchema(data)
+	}
+	path := filepath.Join(basePackageDir(g.Config, g.ProjectDir), "messaging/payload", data["payload"].(string)+".java")
+	if fileExists(path) {
+		return nil
+	}
+	if len(data["fields"].([]map[string]string)) == 0 {
+		util.PrintWarning("No --fields given, %s is generated without components", data["payload"])
+	}
+	return renderTemplate(g.Config, g.ProjectDir, "messaging/payload.tmpl", path, data)
+}
+
+// ensureSqsSetup adds the SQS SDK, a client with scheduling enabled, the queue
+// configuration keys and a LocalStack queue with its dead-letter queue
+func (g *MessagingGenerator) ensureSqsSetup(data map[string]interface{}, consumer bool) error {
+	pomPath := filepath.Join(g.ProjectDir, "pom.xml")
+	if _, err := os.Stat(pomPath); err != nil {
+		util.PrintWarning("No pom.xml found, add com.amazonaws:aws-java-sdk-sqs:%s to your build manually", awsSdkVersion)
+	} else {
+		added, err := util.AddMavenDependency(pomPath, util.MavenDependency{
+			GroupID:    "com.amazonaws",
+			ArtifactID: "aws-java-sdk-sqs",
+			Version:    awsSdkVersion,
+		})
+		if err != nil {
+			return err
+		}
+		if added {
+			util.PrintInfo("Added AWS SQS SDK dependency to pom.xml")
+		}
+	}
+
+	// The aws project template already provides a client in AwsConfig
+	javaDir := filepath.Join(g.ProjectDir, "src/main/java")
+	hasClient, err := sourcesContain(javaDir, "AmazonSQS amazonSQS(")
+	if err != nil {
+		return err
+	}
+	data["sqsClient"] = !hasClient
+	if consumer {
+		scheduling, err := sourcesContain(javaDir, "@EnableScheduling")
+		if err != nil {
+			return err
+		}
+		data["scheduling"] = !scheduling
+	} else {
+		data["scheduling"] = false
+	}
+	if data["sqsClient"].(bool) || data["scheduling"].(bool) {
+		configPath := filepath.Join(basePackageDir(g.Config, g.ProjectDir), "config", "SqsConfig.java")
+		if fileExists(configPath) {
+			// Only scheduling can be missing from a config written for a producer
+			if err := enableScheduling(configPath); err != nil {
+				return err
+			}
+		} else if err := renderTemplate(g.Config, g.ProjectDir, "messaging/sqs_config.tmpl", configPath, data); err != nil {
+			return err
+		}
+	}
+
+	if err := addApplicationConfig(g.ProjectDir, sqsApplicationConfig(data, consumer)); err != nil {
+		return err
+	}
+	util.PrintInfo("Added %s configuration for queue %s", data["queueProperty"], data["queue"])
+
+	if err := util.MergeYAML(filepath.Join(g.ProjectDir, "compose.yaml"), sqsComposeConfig(data)); err != nil {
+		return err
+	}
+	util.PrintInfo("Added LocalStack queues %s and %s to compose.yaml", data["queue"], data["deadLetterQueue"])
+	return nil
+}
+
+// enableScheduling adds @EnableScheduling to an existing configuration class
+func enableScheduling(configPath string) error {
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		return err
+	}
+	source := string(content)
+	source = insertAfterLast(source, "import org.springframework.context.annotation.Configuration;",
+		"import org.springframework.scheduling.annotation.EnableScheduling;\n")
+	source = strings.Replace(source, "@Configuration\n", "@Configuration\n@EnableScheduling\n", 1)
+	util.PrintInfo("Enabling scheduling in %s", filepath.Base(configPath))
+	return util.WriteFile(configPath, source)
+}
+
+// sqsApplicationConfig returns the application.yml keys of a queue
+func sqsApplicationConfig(data map[string]interface{}, consumer bool) string {
+	queue := data["queue"].(string)
+	env := data["queueEnv"].(string)
+	baseURL := "http://localhost:4566/" + localStackAccountID + "/"
+
+	var b strings.Builder
+	b.WriteString("aws:\n")
+	b.WriteString("  region: " + data["region"].(string) + "\n")
+	b.WriteString("  sqs:\n")
+	if data["sqsClient"].(bool) {
+		b.WriteString("    endpoint: ${AWS_SQS_ENDPOINT:http://localhost:4566}\n")
+	}
+	b.WriteString("    queues:\n")
+	b.WriteString("      " + queue + ":\n")
+	b.WriteString("        url: ${" + env + "_QUEUE_URL:" + baseURL + queue + "}\n")
+	b.WriteString("        dead-letter-queue-url: ${" + env + "_DLQ_URL:" + baseURL + data["deadLetterQueue"].(string) + "}\n")
+	b.WriteString(fmt.Sprintf("        max-receive-count: %d\n", data["maxReceiveCount"]))
+	b.WriteString(fmt.Sprintf("        visibility-timeout-seconds: %d\n", data["visibilityTimeout"]))
+	if consumer {
+		b.WriteString("        max-messages: 10\n")
+		b.WriteString("        wait-time-seconds: 20\n")
+		b.WriteString("        polling-interval-ms: 1000\n")
+	}
+	return b.String()
+}
+
+// sqsComposeConfig returns the compose.yaml services and configs that create a queue and its
+// dead-letter queue in LocalStack, with a redrive policy moving messages after max-receive-count
+func sqsComposeConfig(data map[string]interface{}) string {
+	queue := data["queue"].(string)
+	dlq := data["deadLetterQueue"].(string)
+	dlqArn := fmt.Sprintf("arn:aws:sqs:%s:%s:%s", data["region"], localStackAccountID, dlq)
+	attributes := fmt.Sprintf(`{"RedrivePolicy":"{\"deadLetterTargetArn\":\"%s\",\"maxReceiveCount\":\"%d\"}","VisibilityTimeout":"%d"}`,
+		dlqArn, data["maxReceiveCount"], data["visibilityTimeout"])
+
+	return `services:
+  localstack:
+    image: ` + localStackImage + `
+    ports:
+      - "4566:4566"
+    configs:
+      - source: sqs-` + queue + `
+        target: /etc/localstack/init/ready.d/sqs-` + queue + `.sh
+        mode: 0755
+configs:
+  sqs-` + queue + `:
+    content: |
+      #!/bin/sh
+      awslocal sqs create-queue --queue-name ` + dlq + `
+      awslocal sqs create-queue --queue-name ` + queue + ` --attributes '` + attributes + `'
+`
+}
+
+// addApplicationConfig merges YAML configuration keys into the project's application.yml,
+// or into application.properties when that is what the project uses
+func addApplicationConfig(projectDir, fragment string) error {
+	return addProfileConfig(projectDir, "", fragment)
+}
+
+// addProfileConfig merges YAML configuration keys into the configuration of a Spring
+// profile, such as application-dev.yml, in the format the project's application
+// configuration uses. An empty profile stands for the application configuration itself
+func addProfileConfig(projectDir, profile, fragment string) error {
+	resources := filepath.Join(projectDir, "src/main/resources")
+	base := "application"
+	if profile != "" {
+		base += "-" + profile
+	}
+	for _, ext := range []string{".yml", ".yaml"} {
+		if fileExists(filepath.Join(resources, "application"+ext)) {
+			return util.MergeYAML(filepath.Join(resources, base+ext), fragment)
+		}
+	}
+	if fileExists(filepath.Join(resources, "application.properties")) {
+		return util.MergeProperties(filepath.Join(resources, base+".properties"), fragment)
+	}
+	return util.MergeYAML(filepath.Join(resources, base+".yml"), fragment)
+}
+
+// applicationProperties reads the project's application.yml, application.yaml or
+// application.properties into dotted property names
+func applicationProperties(projectDir string) (map[string]string, error) {
+	resources := filepath.Join(projectDir, "src/main/resources")
+	for _, name := range []string{"application.yml", "application.yaml", "application.properties"} {
+		if path := filepath.Join(resources, name); fileExists(path) {
+			return util.ReadProperties(path)
+		}
+	}
+	return map[string]string{}, nil
+}
+
+// resolvePlaceholders replaces ${key:default} placeholders with the value of the property,
+// or with their default when the property, such as an environment variable, is not set
+func resolvePlaceholders(value string, properties map[string]string) string {
+	for depth := 0; depth < 10; depth++ {
+		match := placeholderPattern.FindStringSubmatchIndex(value)
+		if match == nil {
+			break
+		}
+		expression := value[match[2]:match[3]]
+		key, fallback, hasDefault := strings.Cut(expression, ":")
+		replacement, ok := properties[key]
+		if !ok {
+			if !hasDefault {
+				break
+			}
+			replacement = fallback
+		}
+		value = value[:match[0]] + replacement + value[match[1]:]
+	}
+	return value
+}
+
+// basePackageDir returns the source directory of the project's base package
+func basePackageDir(cfg *config.Config, projectDir string) string {
+	return filepath.Join(projectDir, "src/main/java", strings.ReplaceAll(cfg.Project.Package, ".", "/"))
+}
@@ -0,0 +1,160 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/springwell/cli/pkg/util"
+)
+
+// accessOperations lists the operations of an access matrix in the order tests exercise them
+var accessOperations = []string{"read", "create", "update", "delete"}
+
+// accessRules turns a parsed access matrix into the data the controller and security test
+// templates use for each operation: the @PreAuthorize expression, a role it allows and a
+// role it denies
+func accessRules(rules map[string][]string) map[string]map[string]interface{} {
+	allRoles := matrixRoles(rules)
+
+	result := map[string]map[string]interface{}{}
+	for _, operation := range accessOperations {
+		roles, ok := rules[operation]
+		if !ok {
+			continue
+		}
+
+		quoted := make([]string, len(roles))
+		for i, role := range roles {
+			quoted[i] = "'" + role + "'"
+		}
+		expression := fmt.Sprintf("hasRole(%s)", quoted[0])
+		if len(roles) > 1 {
+			expression = fmt.Sprintf("hasAnyRole(%s)", strings.Join(quoted, ", "))
+		}
+
+		// Prefer a role of the matrix that this operation leaves out, so the test shows
+		// the rules tell the roles apart
+		denied := "GUEST"
+		for _, role := range allRoles {
+			if !containsString(roles, role) {
+				denied = role
+				break
+			}
+		}
+
+		result[operation] = map[string]interface{}{
+			"expression":  expression,
+			"allowed":     roles[0],
+			"allowedName": util.ToCamelCaseFirstLower(roles[0]),
+			"denied":      denied,
+			"deniedName":  util.ToCamelCaseFirstLower(denied),
+		}
+	}
+	return result
+}
+
+// matrixRoles returns the roles an access matrix names, in the order of its operations
+func matrixRoles(rules map[string][]string) []string {
+	var roles []string
+	for _, operation := range accessOperations {
+		for _, role := range rules[operation] {
+			if !containsString(roles, role) {
+				roles = append(roles, role)
+			}
+		}
+	}
+	return roles
+}
+
+// addMethodSecurity makes sure @PreAuthorize rules on generated controllers are enforced:
+// it adds Spring Security and its test support to the project and enables method security
+// unless a configuration already does
+func (g *EntityGenerator) addMethodSecurity(data map[string]interface{}) error {
+	pomPath := filepath.Join(g.ProjectDir, "pom.xml")
+	if _, err := os.Stat(pomPath); err != nil {
+		util.PrintWarning("No pom.xml found, add spring-boot-starter-security and spring-security-test to enforce and test access rules")
+	} else {
+		added, err := util.AddMavenDependency(pomPath, util.MavenDependency{
+			GroupID:    "org.springframework.boot",
+			ArtifactID: "spring-boot-starter-security",
+		})
+		if err != nil {
+			return err
+		}
+		if added {
+			util.PrintWarning("Added Spring Security to pom.xml, every endpoint now requires authentication until a SecurityFilterChain says otherwise")
+		}
+		if _, err := util.AddMavenDependency(pomPath, util.MavenDependency{
+			GroupID:    "org.springframework.security",
+			ArtifactID: "spring-security-test",
+			Scope:      "test",
+		}); err != nil {
+			return err
+		}
+	}
+
+	javaDir := filepath.Join(g.ProjectDir, "src/main/java")
+	configPath, err := findSource(javaDir, "@EnableMethodSecurity")
+	if err != nil {
+		return err
+	}
+	if configPath == "" {
+		configPath = filepath.Join(javaDir, strings.ReplaceAll(g.Config.Project.Package, ".", "/"), "config", "MethodSecurityConfig.java")
+		util.PrintInfo("Enabling method security for @PreAuthorize access rules")
+		if err := g.generateFromTemplate("entity/method_security_config.tmpl", configPath, data); err != nil {
+			return err
+		}
+	}
+
+	// @WebMvcTest only loads the configuration of the application class itself, so the
+	// security test imports the one enabling method security
+	data["methodSecurityConfig"] = ""
+	data["methodSecurityConfigName"] = strings.TrimSuffix(filepath.Base(configPath), ".java")
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(string(content), "@SpringBootApplication") {
+		data["methodSecurityConfig"] = javaClassName(string(content), configPath)
+	}
+
+	handlerPath := filepath.Join(javaDir, strings.ReplaceAll(g.Config.Project.Package, ".", "/"), "exception", "GlobalExceptionHandler.java")
+	if handler, err := os.ReadFile(handlerPath); err == nil && !strings.Contains(string(handler), "AccessDeniedException") {
+		util.PrintWarning("exception/GlobalExceptionHandler.java answers denied access with 500, rethrow AccessDeniedException from its fallback handler so Spring Security can answer 403")
+	}
+	return nil
+}
+
+var javaPackagePattern = regexp.MustCompile(`(?m)^package\s+([\w.]+)\s*;`)
+
+// javaClassName returns the fully qualified name of the class declared in a Java source file
+func javaClassName(source, path string) string {
+	name := strings.TrimSuffix(filepath.Base(path), ".java")
+	if match := javaPackagePattern.FindStringSubmatch(source); match != nil {
+		return match[1] + "." + name
+	}
+	return name
+}
+
+var preAuthorizeRolePattern = regexp.MustCompile(`has(?:Any)?Role\(([^)]*)\)`)
+
+// controllerRoles returns the sorted roles named by the @PreAuthorize rules of a controller
+func controllerRoles(controller string) []string {
+	seen := map[string]bool{}
+	for _, match := range preAuthorizeRolePattern.FindAllStringSubmatch(controller, -1) {
+		for _, role := range strings.Split(match[1], ",") {
+			seen[strings.Trim(strings.TrimSpace(role), `'"`)] = true
+		}
+	}
+
+	roles := make([]string, 0, len(seen))
+	for role := range seen {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	return roles
+}
@@ -0,0 +1,236 @@
+package generator
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/springwell/cli/pkg/config"
+	"github.com/springwell/cli/pkg/util"
+)
+
+// AuthModes lists the authentication modes AddSecurity can configure
+var AuthModes = []string{"jwt", "oauth2", "basic", "auth0"}
+
+const (
+	// Directory, relative to the project, holding the keypair that signs local JWTs
+	devKeysDir = "dev-keys"
+	// Size of the generated dev RSA key in bits
+	devKeySize = 2048
+)
+
+// securityDependencies maps each auth mode to the starters it needs besides Spring Security
+var securityDependencies = map[string][]string{
+	"jwt":    {"spring-boot-starter-oauth2-resource-server"},
+	"auth0":  {"spring-boot-starter-oauth2-resource-server"},
+	"oauth2": {"spring-boot-starter-oauth2-client"},
+	"basic":  nil,
+}
+
+// AddSecurity configures Spring Security for an auth mode: a SecurityFilterChain with public
+// health, API documentation and error paths, its application properties, a dev keypair for
+// modes verifying JWTs, a local Keycloak for jwt and oauth2, and an integration test of the
+// filter chain
+func AddSecurity(cfg *config.Config, projectDir, auth string) error {
+	extra, ok := securityDependencies[auth]
+	if !ok {
+		return fmt.Errorf("unsupported auth mode %q, use %s", auth, strings.Join(AuthModes, ", "))
+	}
+
+	pomPath := filepath.Join(projectDir, "pom.xml")
+	name := "app"
+	if _, err := os.Stat(pomPath); err != nil {
+		util.PrintWarning("No pom.xml found, add spring-boot-starter-security and spring-security-test to your build manually")
+	} else {
+		dependencies := []util.MavenDependency{{GroupID: "org.springframework.boot", ArtifactID: "spring-boot-starter-security"}}
+		for _, artifactID := range extra {
+			dependencies = append(dependencies, util.MavenDependency{GroupID: "org.springframework.boot", ArtifactID: artifactID})
+		}
+		dependencies = append(dependencies, util.MavenDependency{GroupID: "org.springframework.security", ArtifactID: "spring-security-test", Scope: "test"})
+		for _, dep := range dependencies {
+			added, err := util.AddMavenDependency(pomPath, dep)
+			if err != nil {
+				return err
+			}
+			if added {
+				util.PrintInfo("Added %s:%s dependency to pom.xml", dep.GroupID, dep.ArtifactID)
+			}
+		}
+		if project, err := util.ReadMavenProject(pomPath); err == nil && project.ArtifactID != "" {
+			name = project.ArtifactID
+		}
+	}
+
+	data := map[string]interface{}{
+		"package": cfg.Project.Package,
+		"auth":    auth,
+		"jwt":     auth == "jwt" || auth == "auth0",
+	}
+	packageDir := strings.ReplaceAll(cfg.Project.Package, ".", "/")
+	configPath := filepath.Join(projectDir, "src/main/java", packageDir, "config", "SecurityConfig.java")
+	if fileExists(configPath) {
+		util.PrintWarning("config/SecurityConfig.java already exists, leaving it unchanged")
+	} else if err := renderTemplate(cfg, projectDir, "security/security_config.tmpl", configPath, data); err != nil {
+		return err
+	}
+
+	// Default client secret for oauth2 and password for basic, shared with the dev realm
+	secret, err := randomSecret()
+	if err != nil {
+		return err
+	}
+	fragment := securityApplicationConfig(auth, name, secret)
+	if err := addApplicationConfig(projectDir, fragment); err != nil {
+		return err
+	}
+
+	// Tokens signed with the dev keypair are only accepted under the dev profile
+	// 'springwell dev' runs with, so a deployment never trusts them
+	if data["jwt"] == true {
+		if err := writeDevKeys(projectDir); err != nil {
+			return err
+		}
+		if err := addProfileConfig(projectDir, "dev", devKeyConfig()); err != nil {
+			return err
+		}
+	}
+
+	// A local Keycloak issues tokens for jwt and logs users in for oauth2
+	if auth == "jwt" || auth == "oauth2" {
+		if err := addKeycloak(projectDir, name, auth, secret); err != nil {
+			return err
+		}
+	}
+
+	testPath := filepath.Join(projectDir, "src/test/java", packageDir, "config", "SecurityIntegrationTest.java")
+	if err := renderTemplate(cfg, projectDir, "security/security_integration_test.tmpl", testPath, data); err != nil {
+		return err
+	}
+
+	util.PrintInfo("Configured %s authentication in config/SecurityConfig.java", auth)
+	return nil
+}
+
+// securityApplicationConfig returns the application.yml keys of an auth mode, defaulting the
+// oauth2 client secret or basic password to secret. Roles for @PreAuthorize rules are read
+// from the token claim named by security.roles-claim
+func securityApplicationConfig(auth, name, secret string) string {
+	switch auth {
+	case "jwt":
+		return `spring:
+  security:
+    oauth2:
+      resourceserver:
+        jwt:
+          issuer-uri: ${JWT_ISSUER_URI:urn:` + name + `:dev}
+security:
+  roles-claim: roles
+`
+	case "auth0":
+		return `auth0:
+  domain: ${AUTH0_DOMAIN:` + name + `.us.auth0.com}
+  audience: ${AUTH0_AUDIENCE:https://api.` + name + `.com}
+spring:
+  security:
+    oauth2:
+      resourceserver:
+        jwt:
+          issuer-uri: https://${auth0.domain}/
+          audiences: ${auth0.audience}
+security:
+  roles-claim: ${auth0.audience}/roles
+`
+	case "oauth2":
+		// The provider endpoints are spelled out rather than discovered from the issuer,
+		// so the application and its tests start without the provider running. They
+		// follow Keycloak's layout, other providers list theirs in their discovery document
+		return `oauth2:
+  issuer-uri: ${OAUTH2_ISSUER_URI:` + KeycloakURL + `/realms/` + name + `}
+spring:
+  security:
+    oauth2:
+      client:
+        registration:
+          oidc:
+            client-id: ${OAUTH2_CLIENT_ID:` + name + `}
+            client-secret: ${OAUTH2_CLIENT_SECRET:` + secret + `}
+            scope: openid,profile,email
+        provider:
+          oidc:
+            authorization-uri: ${oauth2.issuer-uri}/protocol/openid-connect/auth
+            token-uri: ${oauth2.issuer-uri}/protocol/openid-connect/token
+            jwk-set-uri: ${oauth2.issuer-uri}/protocol/openid-connect/certs
+            user-info-uri: ${oauth2.issuer-uri}/protocol/openid-connect/userinfo
+            user-name-attribute: preferred_username
+security:
+  roles-claim: roles
+`
+	default:
+		return `spring:
+  security:
+    user:
+      name: ${APP_USER:admin}
+      password: ${APP_PASSWORD:` + secret + `}
+      roles: ADMIN,USER
+`
+	}
+}
+
+// devKeyConfig returns the dev profile keys verifying JWTs with the dev public key
+func devKeyConfig() string {
+	return `spring:
+  security:
+    oauth2:
+      resourceserver:
+        jwt:
+          public-key-location: file:` + devKeysDir + `/public.pem
+`
+}
+
+// writeDevKeys generates the RSA keypair local JWTs are signed and verified with,
+// unless the project already has one
+func writeDevKeys(projectDir string) error {
+	publicPath := filepath.Join(projectDir, devKeysDir, "public.pem")
+	if fileExists(publicPath) {
+		return nil
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, devKeySize)
+	if err != nil {
+		return err
+	}
+	privateDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return err
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return err
+	}
+
+	privatePEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER})
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+	if err := util.WriteFile(filepath.Join(projectDir, devKeysDir, "private.pem"), string(privatePEM)); err != nil {
+		return err
+	}
+	if err := util.WriteFile(publicPath, string(publicPEM)); err != nil {
+		return err
+	}
+	util.PrintInfo("Generated a dev keypair in %s/ for signing local JWTs, never use it outside development", devKeysDir)
+	return nil
+}
+
+// randomSecret returns a random hex string for development passwords and client secrets
+func randomSecret() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
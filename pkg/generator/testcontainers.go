@@ -0,0 +1,92 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/springwell/cli/pkg/config"
+	"github.com/springwell/cli/pkg/util"
+)
+
+// testcontainersDatabase describes the Testcontainers module and container for a database.
+// Images match the services in the generated compose.yaml.
+type testcontainersDatabase struct {
+	Module    string
+	Container string
+	Bean      string
+	Image     string
+}
+
+var testcontainersDatabases = map[string]testcontainersDatabase{
+	"postgres": {Module: "postgresql", Container: "PostgreSQLContainer", Bean: "postgresContainer", Image: "postgres:14-alpine"},
+	"mysql":    {Module: "mysql", Container: "MySQLContainer", Bean: "mysqlContainer", Image: "mysql:8.0"},
+}
+
+// ProjectDatabase returns the database a project uses, from its configuration or,
+// for projects created before it was recorded, from the JDBC driver in pom.xml
+func ProjectDatabase(cfg *config.Config, projectDir string) string {
+	if cfg.Project.Database != "" {
+		return cfg.Project.Database
+	}
+
+	pomPath := filepath.Join(projectDir, "pom.xml")
+	switch {
+	case util.HasMavenDependency(pomPath, "org.postgresql", "postgresql"):
+		return "postgres"
+	case util.HasMavenDependency(pomPath, "com.mysql", "mysql-connector-j"),
+		util.HasMavenDependency(pomPath, "mysql", "mysql-connector-java"):
+		return "mysql"
+	}
+	return "h2"
+}
+
+// AddTestcontainers adds the Testcontainers test dependencies for a database and a
+// TestcontainersConfiguration that connects Spring Boot to it with @ServiceConnection
+func AddTestcontainers(cfg *config.Config, projectDir, db string) error {
+	database, ok := testcontainersDatabases[db]
+	if !ok {
+		return fmt.Errorf("Testcontainers is not supported for database %q, use postgres or mysql", db)
+	}
+
+	pomPath := filepath.Join(projectDir, "pom.xml")
+	if _, err := os.Stat(pomPath); err != nil {
+		util.PrintWarning("No pom.xml found, add spring-boot-testcontainers and org.testcontainers:%s as test dependencies manually", database.Module)
+	} else {
+		dependencies := []util.MavenDependency{
+			{GroupID: "org.springframework.boot", ArtifactID: "spring-boot-testcontainers", Scope: "test"},
+			{GroupID: "org.testcontainers", ArtifactID: "junit-jupiter", Scope: "test"},
+			{GroupID: "org.testcontainers", ArtifactID: database.Module, Scope: "test"},
+		}
+		for _, dep := range dependencies {
+			added, err := util.AddMavenDependency(pomPath, dep)
+			if err != nil {
+				return err
+			}
+			if added {
+				util.PrintInfo("Added %s:%s test dependency to pom.xml", dep.GroupID, dep.ArtifactID)
+			}
+		}
+	}
+
+	configPath := filepath.Join(projectDir, "src/test/java", strings.ReplaceAll(cfg.Project.Package, ".", "/"), "TestcontainersConfiguration.java")
+	if content, err := os.ReadFile(configPath); err == nil {
+		// Spring Initializr declares the configuration package-private, but integration
+		// tests live in their own package
+		source := string(content)
+		if !strings.Contains(source, "public class TestcontainersConfiguration") {
+			source = strings.Replace(source, "class TestcontainersConfiguration", "public class TestcontainersConfiguration", 1)
+			return util.WriteFile(configPath, source)
+		}
+		return nil
+	}
+
+	return renderTemplate(cfg, projectDir, "test/testcontainers_configuration.tmpl", configPath, map[string]interface{}{
+		"package":   cfg.Project.Package,
+		"module":    database.Module,
+		"container": database.Container,
+		"bean":      database.Bean,
+		"image":     database.Image,
+	})
+}
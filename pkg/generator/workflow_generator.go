@@ -0,0 +1,437 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/springwell/cli/pkg/config"
+	"github.com/springwell/cli/pkg/util"
+)
+
+// Version of the Temporal Java SDK added to generated projects
+const temporalVersion = "1.25.1"
+
+// Template of workflow implementations, with and without a saga
+const workflowImplTemplate = "workflow/workflow_impl.tmpl"
+
+// WorkflowGenerator generates Temporal workflows, their activities and worker registration
+type WorkflowGenerator struct {
+	Config     *config.Config
+	ProjectDir string
+}
+
+// NewWorkflowGenerator creates a new WorkflowGenerator
+func NewWorkflowGenerator(config *config.Config, projectDir string) *WorkflowGenerator {
+	return &WorkflowGenerator{
+		Config:     config,
+		ProjectDir: projectDir,
+	}
+}
+
+// WorkflowMethod is an activity, signal or query of a workflow with its Java type.
+// The type is the return type of activities and queries, and the payload of signals.
+type WorkflowMethod struct {
+	Name string
+	Type string
+}
+
+// WorkflowOptions controls what GenerateWorkflow produces
+type WorkflowOptions struct {
+	Activities           []WorkflowMethod
+	Signals              []WorkflowMethod
+	Queries              []WorkflowMethod
+	TaskQueue            string        // Task queue the worker polls (default: derived from name)
+	StartToCloseTimeout  time.Duration // Maximum time of a single activity attempt
+	MaximumAttempts      int           // Attempts before an activity fails, 0 for unlimited
+	InitialInterval      time.Duration // Delay before the first retry
+	MaximumInterval      time.Duration // Upper bound of the delay between retries
+	BackoffCoefficient   float64       // Growth of the delay between retries
+	DoNotRetry           []string      // Exception types that are never retried
+	Saga                 bool          // Compensate completed activities when a later one fails
+	ParallelCompensation bool          // Run compensations in parallel rather than in reverse order
+	ContinueWithError    bool          // Keep compensating when a compensation fails
+	Tests                bool          // Generate a unit test running the workflow with mocked activities
+}
+
+// ParseWorkflowMethods parses a comma-separated list of "name[:Type]" definitions,
+// using defaultType for entries without a type
+func ParseWorkflowMethods(definitions, defaultType string) ([]WorkflowMethod, error) {
+	var methods []WorkflowMethod
+	for _, definition := range strings.Split(definitions, ",") {
+		definition = strings.TrimSpace(definition)
+		if definition == "" {
+			continue
+		}
+		parts := strings.SplitN(definition, ":", 2)
+		name := util.ToJavaVariableName(parts[0])
+		if name == "" {
+			return nil, fmt.Errorf("invalid method definition: %s, expected name[:Type]", definition)
+		}
+		javaType := defaultType
+		if len(parts) == 2 && parts[1] != "" {
+			javaType = parts[1]
+		}
+		methods = append(methods, WorkflowMethod{Name: name, Type: javaType})
+	}
+	return methods, nil
+}
+
+// GenerateWorkflow generates a workflow interface and implementation, an activity
+// interface and stub implementation, and registers both with a Temporal worker
+func (g *WorkflowGenerator) GenerateWorkflow(name string, opts WorkflowOptions) error {
+	name = util.ToJavaClassName(name)
+	if len(opts.Activities) == 0 {
+		return fmt.Errorf("workflow %s needs at least one activity", name)
+	}
+	taskQueue := opts.TaskQueue
+	if taskQueue == "" {
+		taskQueue = strings.ReplaceAll(util.ToDatabaseTableName(name), "_", "-") + "-queue"
+	}
+
+	data := map[string]interface{}{
+		"name":                 name,
+		"nameCamel":            util.ToJavaVariableName(name),
+		"package":              g.Config.Project.Package,
+		"taskQueue":            taskQueue,
+		"taskQueueConstant":    strings.ToUpper(util.ToDatabaseTableName(name)) + "_TASK_QUEUE",
+		"activities":           workflowMethodData(opts.Activities),
+		"signals":              workflowMethodData(opts.Signals),
+		"queries":              workflowQueryData(opts.Queries),
+		"startToCloseTimeout":  javaDuration(opts.StartToCloseTimeout),
+		"maximumAttempts":      opts.MaximumAttempts,
+		"initialInterval":      javaDuration(opts.InitialInterval),
+		"maximumInterval":      javaDuration(opts.MaximumInterval),
+		"backoffCoefficient":   strconv.FormatFloat(opts.BackoffCoefficient, 'f', -1, 64),
+		"doNotRetry":           opts.DoNotRetry,
+		"saga":                 opts.Saga,
+		"parallelCompensation": opts.ParallelCompensation,
+		"continueWithError":    opts.ContinueWithError,
+	}
+	data["cancelCondition"] = cancelCondition(opts.Signals)
+	if !strings.Contains(data["backoffCoefficient"].(string), ".") {
+		data["backoffCoefficient"] = data["backoffCoefficient"].(string) + ".0"
+	}
+
+	temporalDir := filepath.Join(g.ProjectDir, "src/main/java", strings.ReplaceAll(g.Config.Project.Package, ".", "/"), "temporal")
+	files := []struct{ template, path string }{
+		{"workflow/workflow.tmpl", filepath.Join(temporalDir, "workflow", name+"Workflow.java")},
+		{workflowImplTemplate, filepath.Join(temporalDir, "workflow/impl", name+"WorkflowImpl.java")},
+		{"workflow/activity.tmpl", filepath.Join(temporalDir, "activity", name+"Activity.java")},
+		{"workflow/activity_impl.tmpl", filepath.Join(temporalDir, "activity/impl", name+"ActivityImpl.java")},
+	}
+	for _, file := range files {
+		if err := renderTemplate(g.Config, g.ProjectDir, file.template, file.path, data); err != nil {
+			return err
+		}
+	}
+
+	if err := g.ensureTemporalSetup(data); err != nil {
+		return err
+	}
+	if opts.Tests {
+		if err := g.generateWorkflowTest(data); err != nil {
+			return err
+		}
+	}
+	return g.registerWorker(filepath.Join(temporalDir, "worker", "TemporalWorkerRegistrar.java"), data)
+}
+
+// ensureTemporalSetup adds the Temporal SDK, client configuration and worker startup
+// to projects that were not created with Temporal support
+func (g *WorkflowGenerator) ensureTemporalSetup(data map[string]interface{}) error {
+	pomPath := filepath.Join(g.ProjectDir, "pom.xml")
+	if _, err := os.Stat(pomPath); err != nil {
+		util.PrintWarning("No pom.xml found, add io.temporal:temporal-sdk:%s to your build manually", temporalVersion)
+	} else {
+		added, err := util.AddMavenDependency(pomPath, util.MavenDependency{
+			GroupID:    "io.temporal",
+			ArtifactID: "temporal-sdk",
+			Version:    temporalVersion,
+		})
+		if err != nil {
+			return err
+		}
+		if added {
+			util.PrintInfo("Added Temporal SDK dependency to pom.xml")
+		}
+	}
+
+	javaDir := filepath.Join(g.ProjectDir, "src/main/java", strings.ReplaceAll(g.Config.Project.Package, ".", "/"))
+	configPath := filepath.Join(javaDir, "config", "TemporalConfig.java")
+	if !fileExists(configPath) {
+		if err := renderTemplate(g.Config, g.ProjectDir, "workflow/temporal_config.tmpl", configPath, data); err != nil {
+			return err
+		}
+	}
+
+	// A new registrar also needs a service that starts the workers it registers
+	registrarPath := filepath.Join(javaDir, "temporal/worker", "TemporalWorkerRegistrar.java")
+	if !fileExists(registrarPath) {
+		if err := renderTemplate(g.Config, g.ProjectDir, "workflow/worker_registrar.tmpl", registrarPath, data); err != nil {
+			return err
+		}
+		servicePath := filepath.Join(javaDir, "temporal/worker", "TemporalWorkerService.java")
+		if !fileExists(servicePath) {
+			if err := renderTemplate(g.Config, g.ProjectDir, "workflow/worker_service.tmpl", servicePath, data); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// generateWorkflowTest generates a JUnit 5 test that runs the workflow in a
+// TestWorkflowEnvironment against mocked activities
+func (g *WorkflowGenerator) generateWorkflowTest(data map[string]interface{}) error {
+	pomPath := filepath.Join(g.ProjectDir, "pom.xml")
+	if _, err := os.Stat(pomPath); err == nil {
+		added, err := util.AddMavenDependency(pomPath, util.MavenDependency{
+			GroupID:    "io.temporal",
+			ArtifactID: "temporal-testing",
+			Version:    temporalVersion,
+			Scope:      "test",
+		})
+		if err != nil {
+			return err
+		}
+		if added {
+			util.PrintInfo("Added Temporal testing dependency to pom.xml")
+		}
+	}
+
+	activities := data["activities"].([]map[string]string)
+	maximumAttempts := data["maximumAttempts"].(int)
+	data["retries"] = maximumAttempts != 1
+	data["exhausts"] = maximumAttempts > 0
+	data["firstActivity"] = activities[0]
+	data["lastActivity"] = activities[len(activities)-1]
+
+	// Steps started before the last one fails, itself included, in the order they
+	// are compensated
+	compensated := make([]map[string]string, 0, len(activities))
+	for i := len(activities) - 1; i >= 0; i-- {
+		compensated = append(compensated, activities[i])
+	}
+	data["compensatedActivities"] = compensated
+	// A cancel signal sent during the first step stops the workflow before the second
+	data["cancels"] = data["cancelCondition"] != "" && len(activities) > 1
+	if data["cancels"].(bool) {
+		data["secondActivity"] = activities[1]
+	}
+	data["testStaticImports"] = workflowTestStaticImports(data)
+
+	testPath := filepath.Join(g.ProjectDir, "src/test/java", strings.ReplaceAll(g.Config.Project.Package, ".", "/"),
+		"temporal/workflow", data["name"].(string)+"WorkflowTest.java")
+	return renderTemplate(g.Config, g.ProjectDir, "workflow/workflow_test.tmpl", testPath, data)
+}
+
+// workflowTestStaticImports returns the AssertJ and Mockito static imports used by a workflow test
+func workflowTestStaticImports(data map[string]interface{}) []string {
+	activities := data["activities"].([]map[string]string)
+	signals := len(data["signals"].([]map[string]string)) > 0
+	retries, exhausts, saga := data["retries"].(bool), data["exhausts"].(bool), data["saga"].(bool)
+	firstVoid := data["firstActivity"].(map[string]string)["type"] == "void"
+	lastVoid := data["lastActivity"].(map[string]string)["type"] == "void"
+
+	imports := []string{"org.assertj.core.api.Assertions.assertThat"}
+	if exhausts || saga {
+		imports = append(imports, "org.assertj.core.api.Assertions.assertThatThrownBy")
+	}
+	if signals {
+		imports = append(imports, "org.mockito.Mockito.doAnswer")
+	}
+	if firstVoid && retries {
+		imports = append(imports, "org.mockito.Mockito.doNothing")
+	}
+	if (firstVoid && (retries || exhausts)) || (lastVoid && saga) {
+		imports = append(imports, "org.mockito.Mockito.doThrow")
+	}
+	imports = append(imports, "org.mockito.Mockito.inOrder", "org.mockito.Mockito.mock")
+	if data["cancels"].(bool) {
+		imports = append(imports, "org.mockito.Mockito.never")
+	}
+	if retries || exhausts {
+		imports = append(imports, "org.mockito.Mockito.times")
+	}
+	imports = append(imports, "org.mockito.Mockito.verify")
+	if exhausts {
+		imports = append(imports, "org.mockito.Mockito.verifyNoMoreInteractions")
+	}
+	for _, activity := range activities {
+		if activity["type"] != "void" {
+			imports = append(imports, "org.mockito.Mockito.when")
+			break
+		}
+	}
+	return append(imports, "org.mockito.Mockito.withSettings")
+}
+
+// registerWorker adds a worker for the workflow's task queue to TemporalWorkerRegistrar,
+// registering the workflow implementation and its activities
+func (g *WorkflowGenerator) registerWorker(registrarPath string, data map[string]interface{}) error {
+	content, err := os.ReadFile(registrarPath)
+	if err != nil {
+		return err
+	}
+	source := string(content)
+
+	name := data["name"].(string)
+	pkg := data["package"].(string)
+	constant := data["taskQueueConstant"].(string)
+	activityField := data["nameCamel"].(string) + "Activity"
+	registerMethod := "register" + name + "Worker"
+	if strings.Contains(source, registerMethod+"(") {
+		return nil
+	}
+
+	// Imports of the implementations
+	imports := "import " + pkg + ".temporal.activity.impl." + name + "ActivityImpl;\n" +
+		"import " + pkg + ".temporal.workflow.impl." + name + "WorkflowImpl;\n"
+	if strings.Contains(source, "import "+pkg+".") {
+		source = insertAfterLast(source, "import "+pkg+".", imports)
+	} else {
+		source = insertAfterLast(source, "package ", "\n"+strings.TrimSuffix(imports, "\n"))
+	}
+
+	// Task queue constant
+	queue := "    public static final String " + constant + " = \"" + data["taskQueue"].(string) + "\";\n"
+	source = insertAfterAny(source, queue, "_TASK_QUEUE = ", "// The names of the task queues", "public class TemporalWorkerRegistrar")
+
+	// Activity implementation injected by the constructor
+	field := "    private final " + name + "ActivityImpl " + activityField + ";\n"
+	source = insertAfterAny(source, field, "ActivityImpl ", "// Activity implementations")
+
+	// Call from registerWorkers
+	call := "        " + registerMethod + "(workerFactory, defaultOptions);\n"
+	source = insertAfterAny(source, call, "Worker(workerFactory, defaultOptions);", "public void registerWorkers(")
+
+	// Registration method, after the existing ones
+	method := "    /**\n" +
+		"     * Registers the worker for " + name + " workflows and activities.\n" +
+		"     *\n" +
+		"     * @param workerFactory The factory used to create the worker\n" +
+		"     * @param defaultOptions The default options to apply to the worker\n" +
+		"     */\n" +
+		"    private void " + registerMethod + "(WorkerFactory workerFactory, WorkerOptions defaultOptions) {\n" +
+		"        log.info(\"Registering " + name + " worker for task queue: {}\", " + constant + ");\n" +
+		"\n" +
+		"        Worker worker = workerFactory.newWorker(" + constant + ", defaultOptions);\n" +
+		"        worker.registerWorkflowImplementationTypes(" + name + "WorkflowImpl.class);\n" +
+		"        worker.registerActivitiesImplementations(" + activityField + ");\n" +
+		"    }\n"
+	if idx := strings.Index(source, "    /**\n     * You can add more worker registration methods"); idx >= 0 {
+		source = source[:idx] + method + "\n" + source[idx:]
+	} else {
+		end := strings.LastIndex(source, "}")
+		if end < 0 {
+			return fmt.Errorf("invalid worker registrar: %s", registrarPath)
+		}
+		source = strings.TrimRight(source[:end], " \t\n") + "\n\n" + method + "}\n"
+	}
+
+	util.PrintInfo("Registered %s with TemporalWorkerRegistrar on task queue %s", name, data["taskQueue"])
+	return util.WriteFile(registrarPath, source)
+}
+
+// insertAfterAny inserts text after the line holding the last occurrence of the
+// first marker found in source
+func insertAfterAny(source, text string, markers ...string) string {
+	for _, marker := range markers {
+		if strings.Contains(source, marker) {
+			return insertAfterLast(source, marker, text)
+		}
+	}
+	return source
+}
+
+// insertAfterLast inserts text after the line holding the last occurrence of marker
+func insertAfterLast(source, marker, text string) string {
+	idx := strings.LastIndex(source, marker)
+	if idx < 0 {
+		return source
+	}
+	lineEnd := strings.Index(source[idx:], "\n")
+	if lineEnd < 0 {
+		return source + "\n" + text
+	}
+	at := idx + lineEnd + 1
+	return source[:at] + text + source[at:]
+}
+
+// cancelCondition returns the Java condition under which a workflow declaring a
+// cancel signal stops before its next step, or "" without such a signal
+func cancelCondition(signals []WorkflowMethod) string {
+	for _, signal := range signals {
+		if signal.Name != "cancel" {
+			continue
+		}
+		if signal.Type == "boolean" || signal.Type == "Boolean" {
+			return "Boolean.TRUE.equals(cancelValue)"
+		}
+		return "cancelValue != null"
+	}
+	return ""
+}
+
+// workflowMethodData converts workflow methods to template data
+func workflowMethodData(methods []WorkflowMethod) []map[string]string {
+	data := make([]map[string]string, 0, len(methods))
+	for _, method := range methods {
+		data = append(data, map[string]string{
+			"name":         method.Name,
+			"type":         method.Type,
+			"capitalized":  capitalize(method.Name),
+			"constant":     strings.ToUpper(util.ToDatabaseTableName(method.Name)),
+			"defaultValue": javaDefaultValue(method.Type),
+			"compensation": "compensate" + capitalize(method.Name),
+			"sample":       sampleValue(map[string]string{"name": method.Name, "type": method.Type}, 1),
+		})
+	}
+	return data
+}
+
+// workflowQueryData converts queries to template data, naming the workflow field
+// each query reads, e.g. getStatus -> status
+func workflowQueryData(queries []WorkflowMethod) []map[string]string {
+	data := workflowMethodData(queries)
+	for _, query := range data {
+		field := query["name"]
+		if strings.HasPrefix(field, "get") && len(field) > 3 {
+			field = util.ToJavaVariableName(field[3:])
+		}
+		query["field"] = field
+	}
+	return data
+}
+
+// javaDefaultValue returns the Java expression an unimplemented method returns
+func javaDefaultValue(javaType string) string {
+	switch javaType {
+	case "void":
+		return ""
+	case "boolean":
+		return "false"
+	case "byte", "short", "int", "long", "float", "double":
+		return "0"
+	case "char":
+		return "'\\0'"
+	}
+	return "null"
+}
+
+// javaDuration renders a duration as a java.time.Duration factory call in the largest exact unit
+func javaDuration(d time.Duration) string {
+	switch {
+	case d%time.Hour == 0:
+		return fmt.Sprintf("Duration.ofHours(%d)", d/time.Hour)
+	case d%time.Minute == 0:
+		return fmt.Sprintf("Duration.ofMinutes(%d)", d/time.Minute)
+	case d%time.Second == 0:
+		return fmt.Sprintf("Duration.ofSeconds(%d)", d/time.Second)
+	}
+	return fmt.Sprintf("Duration.ofMillis(%d)", d/time.Millisecond)
+}
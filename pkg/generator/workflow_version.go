@@ -0,0 +1,251 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/springwell/cli/pkg/util"
+	"gopkg.in/yaml.v3"
+)
+
+// File under .springwell/ recording the change IDs of versioned workflows
+const workflowVersionsFile = "workflow-versions.yml"
+
+var (
+	workflowGatePattern     = regexp.MustCompile(`Workflow\.getVersion\(\s*"([^"]+)"`)
+	localDeclarationPattern = regexp.MustCompile(`^\s*(?:final\s+)?[\w.]+(?:<[\w.,\s<>?]+>)?(?:\[\])*\s+\w+\s*(?:=|;)`)
+	workflowChangeIDPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9._-]*$`)
+	statementKeywords       = map[string]bool{"return": true, "throw": true, "else": true, "new": true}
+)
+
+// WorkflowVersion records a change to a workflow gated with Workflow.getVersion
+type WorkflowVersion struct {
+	ChangeID   string `yaml:"changeId"`
+	BaseCommit string `yaml:"baseCommit"`
+	RecordedAt string `yaml:"recordedAt"`
+}
+
+// workflowVersions is the content of .springwell/workflow-versions.yml
+type workflowVersions struct {
+	Workflows map[string][]WorkflowVersion `yaml:"workflows"`
+}
+
+// VersionWorkflow wraps the region of a workflow implementation changed since the last
+// commit in a Workflow.getVersion branch, so executions started before the change keep
+// replaying the original code, and records the change ID in .springwell/
+func (g *WorkflowGenerator) VersionWorkflow(name, changeID string) error {
+	name = strings.TrimSuffix(strings.TrimSuffix(util.ToJavaClassName(name), "WorkflowImpl"), "Workflow")
+	if !workflowChangeIDPattern.MatchString(changeID) {
+		return fmt.Errorf("invalid change ID: %s, start with a letter and use letters, digits, '.', '_' and '-'", changeID)
+	}
+
+	relPath := filepath.Join("src/main/java", strings.ReplaceAll(g.Config.Project.Package, ".", "/"),
+		"temporal/workflow/impl", name+"WorkflowImpl.java")
+	content, err := os.ReadFile(filepath.Join(g.ProjectDir, relPath))
+	if err != nil {
+		return fmt.Errorf("workflow %s not found at %s", name, relPath)
+	}
+	source := string(content)
+	for _, match := range workflowGatePattern.FindAllStringSubmatch(source, -1) {
+		if match[1] == changeID {
+			return fmt.Errorf("change ID %s is already used by %sWorkflowImpl", changeID, name)
+		}
+	}
+
+	head, err := util.GitShowHead(g.ProjectDir, relPath)
+	if err != nil {
+		return fmt.Errorf("%v; workflows that were never committed have no running executions to protect", err)
+	}
+	commit, err := util.GitHeadCommit(g.ProjectDir)
+	if err != nil {
+		return err
+	}
+
+	versioned, err := gateChangedRegion(head, source, changeID)
+	if err != nil {
+		return fmt.Errorf("%sWorkflowImpl: %v", name, err)
+	}
+	if err := util.WriteFile(filepath.Join(g.ProjectDir, relPath), versioned); err != nil {
+		return err
+	}
+	util.PrintInfo("Wrapped the changes to %sWorkflowImpl since %s in Workflow.getVersion(\"%s\")", name, commit, changeID)
+
+	return g.recordWorkflowVersion(name, WorkflowVersion{
+		ChangeID:   changeID,
+		BaseCommit: commit,
+		RecordedAt: time.Now().Format("2006-01-02"),
+	})
+}
+
+// CheckWorkflowVersions reports the workflow implementations that changed since the last
+// commit without adding a Workflow.getVersion gate. Workflows that are not committed yet,
+// or projects outside a git repository, are not checked.
+func (g *WorkflowGenerator) CheckWorkflowVersions() ([]string, error) {
+	implDir := filepath.Join("src/main/java", strings.ReplaceAll(g.Config.Project.Package, ".", "/"), "temporal/workflow/impl")
+	paths, err := filepath.Glob(filepath.Join(g.ProjectDir, implDir, "*.java"))
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []string
+	for _, path := range paths {
+		relPath := filepath.Join(implDir, filepath.Base(path))
+		head, err := util.GitShowHead(g.ProjectDir, relPath)
+		if err != nil {
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		source := string(content)
+		if source == head {
+			continue
+		}
+		if len(workflowGatePattern.FindAllString(source, -1)) > len(workflowGatePattern.FindAllString(head, -1)) {
+			continue
+		}
+
+		name := strings.TrimSuffix(filepath.Base(path), "WorkflowImpl.java")
+		issues = append(issues, fmt.Sprintf(
+			"%s changed since the last commit without a Workflow.getVersion gate, run `springwell workflow version %s --change-id <id>`",
+			filepath.Base(path), name))
+	}
+	return issues, nil
+}
+
+// gateChangedRegion finds the lines that differ between the committed and current
+// source and wraps them in a Workflow.getVersion branch, keeping the committed lines
+// for executions that started before the change
+func gateChangedRegion(head, source, changeID string) (string, error) {
+	oldLines := strings.Split(head, "\n")
+	newLines := strings.Split(source, "\n")
+
+	prefix := 0
+	for prefix < len(oldLines) && prefix < len(newLines) && oldLines[prefix] == newLines[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(oldLines)-prefix && suffix < len(newLines)-prefix &&
+		oldLines[len(oldLines)-1-suffix] == newLines[len(newLines)-1-suffix] {
+		suffix++
+	}
+	oldRegion := oldLines[prefix : len(oldLines)-suffix]
+	newRegion := newLines[prefix : len(newLines)-suffix]
+	if len(oldRegion) == 0 && len(newRegion) == 0 {
+		return "", fmt.Errorf("no changes since the last commit")
+	}
+	if !balancedBlock(oldRegion) || !balancedBlock(newRegion) {
+		return "", fmt.Errorf("the changes span more than one block, wrap them in Workflow.getVersion manually")
+	}
+	if strings.Contains(strings.Join(newRegion, "\n"), "Workflow.getVersion(") {
+		return "", fmt.Errorf("the changes are already gated with Workflow.getVersion")
+	}
+
+	indent := regionIndent(newRegion)
+	if indent == "" {
+		indent = regionIndent(oldRegion)
+	}
+	if indent == "" {
+		return "", fmt.Errorf("the changes are outside of a method, only workflow code can be versioned")
+	}
+	for _, line := range append(append([]string{}, oldRegion...), newRegion...) {
+		if localDeclarationPattern.MatchString(line) && !statementKeywords[strings.Fields(line)[0]] {
+			util.PrintWarning("The changes declare local variables, which are no longer visible after the version branch: %s",
+				strings.TrimSpace(line))
+			break
+		}
+	}
+
+	variable := util.ToJavaVariableName(strings.ReplaceAll(changeID, ".", "-")) + "Version"
+	gate := []string{indent + fmt.Sprintf("int %s = Workflow.getVersion(\"%s\", Workflow.DEFAULT_VERSION, 1);", variable, changeID)}
+	switch {
+	case len(oldRegion) == 0:
+		gate = append(gate, indent+fmt.Sprintf("if (%s != Workflow.DEFAULT_VERSION) {", variable))
+		gate = append(gate, indentLines(newRegion)...)
+	case len(newRegion) == 0:
+		gate = append(gate, indent+fmt.Sprintf("if (%s == Workflow.DEFAULT_VERSION) {", variable))
+		gate = append(gate, indentLines(oldRegion)...)
+	default:
+		gate = append(gate, indent+fmt.Sprintf("if (%s == Workflow.DEFAULT_VERSION) {", variable))
+		gate = append(gate, indentLines(oldRegion)...)
+		gate = append(gate, indent+"} else {")
+		gate = append(gate, indentLines(newRegion)...)
+	}
+	gate = append(gate, indent+"}")
+
+	lines := append(append(append([]string{}, newLines[:prefix]...), gate...), newLines[len(newLines)-suffix:]...)
+	return strings.Join(lines, "\n"), nil
+}
+
+// balancedBlock reports whether lines open and close the same number of braces,
+// never closing a block they did not open
+func balancedBlock(lines []string) bool {
+	depth := 0
+	for _, line := range lines {
+		for _, c := range line {
+			switch c {
+			case '{':
+				depth++
+			case '}':
+				depth--
+				if depth < 0 {
+					return false
+				}
+			}
+		}
+	}
+	return depth == 0
+}
+
+// regionIndent returns the indentation of the first non-blank line
+func regionIndent(lines []string) string {
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		}
+	}
+	return ""
+}
+
+// indentLines indents non-blank lines by one level
+func indentLines(lines []string) []string {
+	indented := make([]string, len(lines))
+	for i, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			indented[i] = "    " + line
+		}
+	}
+	return indented
+}
+
+// recordWorkflowVersion appends a change to .springwell/workflow-versions.yml
+func (g *WorkflowGenerator) recordWorkflowVersion(name string, version WorkflowVersion) error {
+	path := filepath.Join(g.ProjectDir, ".springwell", workflowVersionsFile)
+	versions := workflowVersions{Workflows: map[string][]WorkflowVersion{}}
+	if content, err := os.ReadFile(path); err == nil {
+		if err := yaml.Unmarshal(content, &versions); err != nil {
+			return fmt.Errorf("invalid %s: %v", path, err)
+		}
+		if versions.Workflows == nil {
+			versions.Workflows = map[string][]WorkflowVersion{}
+		}
+	}
+	versions.Workflows[name] = append(versions.Workflows[name], version)
+
+	var out bytes.Buffer
+	out.WriteString("# Change IDs passed to Workflow.getVersion, recorded by `springwell workflow version`\n")
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(versions); err != nil {
+		return err
+	}
+
+	util.PrintInfo("Recorded change ID %s for %s in .springwell/%s", version.ChangeID, name, workflowVersionsFile)
+	return util.WriteFile(path, out.String())
+}
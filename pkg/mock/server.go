@@ -0,0 +1,525 @@
+// Package mock serves an OpenAPI document as a fake implementation of its API, answering
+// every operation with its examples or values built from its schemas
+package mock
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/springwell/cli/pkg/openapi"
+)
+
+// ControlPath prefixes the endpoints of the server itself, such as the recorded calls
+const ControlPath = "/__mock"
+
+// maxCalls is the number of calls the server keeps for the calls endpoint
+const maxCalls = 1000
+
+// maxBodySize is the largest request body the server reads
+const maxBodySize = 10 << 20
+
+var templateParamPattern = regexp.MustCompile(`\{([^}]*)\}`)
+
+// Options controls how the server answers
+type Options struct {
+	Latency     time.Duration // Delay of every response
+	Jitter      time.Duration // Upper bound of a random delay added to Latency
+	ErrorRate   float64       // Fraction of the requests answered with ErrorStatus, from 0 to 1
+	ErrorStatus int           // Status of the injected errors, 500 when 0
+	NoValidate  bool          // Whether to answer requests that do not match the document
+	Record      io.Writer     // Where every call is written as a JSON line, or nil
+	OnCall      func(Call)    // Called after every call, or nil
+}
+
+// Call is a request the server answered
+type Call struct {
+	Time       time.Time         `json:"time"`
+	Method     string            `json:"method"`
+	Path       string            `json:"path"`
+	Query      string            `json:"query,omitempty"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Body       interface{}       `json:"body,omitempty"` // Decoded JSON, or the raw body
+	Operation  string            `json:"operation,omitempty"`
+	Status     int               `json:"status"`
+	Injected   bool              `json:"injected,omitempty"` // Whether the status was injected
+	Violations []Violation       `json:"violations,omitempty"`
+	DurationMs int64             `json:"durationMs"`
+}
+
+// Operation is an operation the server answers
+type Operation struct {
+	Method string
+	Path   string // Path with the base path of the document
+	ID     string
+}
+
+// Server is an http.Handler answering the operations of an OpenAPI document
+type Server struct {
+	doc      *openapi.Document
+	opts     Options
+	basePath string
+	routes   []*route
+
+	mu       sync.Mutex
+	calls    []Call
+	patterns map[string]*regexp.Regexp
+}
+
+// route is an operation and the pattern matching the paths of its requests
+type route struct {
+	method  string
+	path    string
+	pattern *regexp.Regexp
+	params  []string // Names of the path parameters, in order
+	item    *openapi.PathItem
+	op      *openapi.Operation
+}
+
+// NewServer creates a server answering the operations of a document
+func NewServer(doc *openapi.Document, opts Options) (*Server, error) {
+	if opts.ErrorRate < 0 || opts.ErrorRate > 1 {
+		return nil, fmt.Errorf("error rate must be between 0 and 1, got %g", opts.ErrorRate)
+	}
+	if opts.ErrorStatus == 0 {
+		opts.ErrorStatus = http.StatusInternalServerError
+	}
+	if opts.ErrorStatus < 400 || opts.ErrorStatus > 599 {
+		return nil, fmt.Errorf("error status must be a 4xx or 5xx status, got %d", opts.ErrorStatus)
+	}
+	s := &Server{
+		doc:      doc,
+		opts:     opts,
+		basePath: basePath(doc),
+		patterns: map[string]*regexp.Regexp{},
+	}
+
+	for _, path := range doc.Paths.Keys {
+		item := doc.Paths.Values[path]
+		var pattern strings.Builder
+		pattern.WriteString("^")
+		for i, literal := range templateParamPattern.Split(path, -1) {
+			if i > 0 {
+				pattern.WriteString("([^/]+)")
+			}
+			pattern.WriteString(regexp.QuoteMeta(literal))
+		}
+		pattern.WriteString("/?$")
+		compiled, err := regexp.Compile(pattern.String())
+		if err != nil {
+			return nil, fmt.Errorf("invalid path %s: %v", path, err)
+		}
+		var params []string
+		for _, match := range templateParamPattern.FindAllStringSubmatch(path, -1) {
+			params = append(params, match[1])
+		}
+		for _, method := range openapi.Methods {
+			if op := item.Operation(method); op != nil {
+				s.routes = append(s.routes, &route{
+					method:  strings.ToUpper(method),
+					path:    path,
+					pattern: compiled,
+					params:  params,
+					item:    item,
+					op:      op,
+				})
+			}
+		}
+	}
+	if len(s.routes) == 0 {
+		return nil, fmt.Errorf("the document has no operations")
+	}
+	// Literal paths win over templated ones, such as /products/search over /products/{id}
+	sort.SliceStable(s.routes, func(i, j int) bool {
+		return len(s.routes[i].params) < len(s.routes[j].params)
+	})
+	return s, nil
+}
+
+// BasePath returns the path of the first server URL of the document, which prefixes every
+// operation
+func (s *Server) BasePath() string {
+	return s.basePath
+}
+
+// Operations returns the operations the server answers, in the order of the document
+func (s *Server) Operations() []Operation {
+	var operations []Operation
+	for _, path := range s.doc.Paths.Keys {
+		for _, method := range openapi.Methods {
+			if op := s.doc.Paths.Values[path].Operation(method); op != nil {
+				operations = append(operations, Operation{
+					Method: strings.ToUpper(method),
+					Path:   s.basePath + path,
+					ID:     op.OperationID,
+				})
+			}
+		}
+	}
+	return operations
+}
+
+// Calls returns the last calls the server answered, oldest first
+func (s *Server) Calls() []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Call{}, s.calls...)
+}
+
+// ServeHTTP answers a request with a response of the matching operation
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Browser clients of the mock run on other origins
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Expose-Headers", "*")
+	if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, HEAD, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "*")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if strings.HasPrefix(r.URL.Path, ControlPath+"/") {
+		s.serveControl(w, r)
+		return
+	}
+
+	start := time.Now()
+	call := Call{
+		Time:    start,
+		Method:  r.Method,
+		Path:    r.URL.Path,
+		Query:   r.URL.RawQuery,
+		Headers: map[string]string{},
+	}
+	for name := range r.Header {
+		call.Headers[name] = r.Header.Get(name)
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize))
+	if err != nil {
+		call.Status = http.StatusBadRequest
+		writeProblem(w, call.Status, "The request body could not be read: "+err.Error(), nil)
+		s.record(call, start)
+		return
+	}
+	if len(body) > 0 {
+		var decoded interface{}
+		if json.Unmarshal(body, &decoded) == nil {
+			call.Body = decoded
+		} else {
+			call.Body = string(body)
+		}
+	}
+
+	call.Status, call.Injected, call.Violations = s.answer(w, r, body, &call.Operation)
+	s.record(call, start)
+}
+
+// answer writes the response of a request and returns its status, whether it was injected
+// and the violations of the document the request made
+func (s *Server) answer(w http.ResponseWriter, r *http.Request, body []byte, operationID *string) (int, bool, []Violation) {
+	path := r.URL.Path
+	if s.basePath != "" {
+		if path != s.basePath && !strings.HasPrefix(path, s.basePath+"/") {
+			writeProblem(w, http.StatusNotFound, fmt.Sprintf("No operation matches %s, the operations are under %s", path, s.basePath), nil)
+			return http.StatusNotFound, false, nil
+		}
+		path = strings.TrimPrefix(path, s.basePath)
+	}
+
+	rt, values, allowed := s.match(r.Method, path)
+	if rt == nil {
+		if len(allowed) > 0 {
+			w.Header().Set("Allow", strings.Join(allowed, ", "))
+			writeProblem(w, http.StatusMethodNotAllowed, fmt.Sprintf("%s does not support %s", r.URL.Path, r.Method), nil)
+			return http.StatusMethodNotAllowed, false, nil
+		}
+		writeProblem(w, http.StatusNotFound, fmt.Sprintf("No operation matches %s %s", r.Method, r.URL.Path), nil)
+		return http.StatusNotFound, false, nil
+	}
+	*operationID = rt.op.OperationID
+	if *operationID == "" {
+		*operationID = rt.method + " " + rt.path
+	}
+
+	if !s.opts.NoValidate {
+		if !s.authorized(r, rt.op) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeProblem(w, http.StatusUnauthorized, "The operation requires credentials the request does not have", nil)
+			return http.StatusUnauthorized, false, nil
+		}
+		if violations := s.validateRequest(r, rt, values, body); len(violations) > 0 {
+			writeProblem(w, http.StatusBadRequest, "The request does not match the OpenAPI document", violations)
+			return http.StatusBadRequest, false, violations
+		}
+	}
+
+	prefer := parsePrefer(r.Header.Get("Prefer"))
+	code, injected := prefer["code"], false
+	if code == "" && s.opts.ErrorRate > 0 && rand.Float64() < s.opts.ErrorRate {
+		code, injected = strconv.Itoa(s.opts.ErrorStatus), true
+	}
+	s.delay(r)
+
+	status, err := s.respond(w, rt.op, code, prefer["example"])
+	if err != nil {
+		writeProblem(w, http.StatusInternalServerError, err.Error(), nil)
+		return http.StatusInternalServerError, injected, nil
+	}
+	return status, injected, nil
+}
+
+// match returns the route of a request and the values of its path parameters, or the
+// methods of the path if it has no operation of the request method
+func (s *Server) match(method, path string) (*route, map[string]string, []string) {
+	var allowed []string
+	for _, rt := range s.routes {
+		values := rt.pattern.FindStringSubmatch(path)
+		if values == nil {
+			continue
+		}
+		if rt.method != method && !(method == http.MethodHead && rt.method == http.MethodGet && rt.item.Head == nil) {
+			allowed = append(allowed, rt.method)
+			continue
+		}
+		params := map[string]string{}
+		for i, name := range rt.params {
+			value, err := url.PathUnescape(values[i+1])
+			if err != nil {
+				value = values[i+1]
+			}
+			params[name] = value
+		}
+		return rt, params, nil
+	}
+	return nil, nil, allowed
+}
+
+// delay waits for the configured latency, or until the client goes away
+func (s *Server) delay(r *http.Request) {
+	latency := s.opts.Latency
+	if s.opts.Jitter > 0 {
+		latency += time.Duration(rand.Int63n(int64(s.opts.Jitter) + 1))
+	}
+	if latency <= 0 {
+		return
+	}
+	timer := time.NewTimer(latency)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-r.Context().Done():
+	}
+}
+
+// respond writes the documented response of a status code, the success response when
+// code is empty. The body is the named example, the first example of the media type or a
+// value built from its schema.
+func (s *Server) respond(w http.ResponseWriter, op *openapi.Operation, code, exampleName string) (int, error) {
+	var key string
+	var response *openapi.Response
+	var err error
+	if code == "" {
+		key, response, err = s.doc.SuccessResponse(op)
+		if err != nil {
+			return 0, err
+		}
+		code = key
+		if key == "" || key == "default" {
+			code = "200"
+		}
+	} else {
+		key, response, err = s.documentedResponse(op, code)
+		if err != nil {
+			return 0, err
+		}
+	}
+	status, err := strconv.Atoi(code)
+	if err != nil || status < 100 || status > 599 {
+		return 0, fmt.Errorf("invalid status code %q", code)
+	}
+	if response == nil {
+		if status >= 400 {
+			writeProblem(w, status, fmt.Sprintf("The operation documents no %d response", status), nil)
+			return status, nil
+		}
+		w.WriteHeader(status)
+		return status, nil
+	}
+
+	contentType, media := openapi.JSONContent(response.Content)
+	if media == nil && len(response.Content.Keys) > 0 {
+		contentType = response.Content.Keys[0]
+		media = response.Content.Values[contentType]
+	}
+	if media == nil || status == http.StatusNoContent {
+		w.WriteHeader(status)
+		return status, nil
+	}
+	if contentType == "*/*" {
+		contentType = "application/json"
+	}
+
+	value, err := s.exampleValue(media, exampleName)
+	if err != nil {
+		return 0, err
+	}
+	if text, ok := value.(string); ok && !isJSON(contentType) {
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(status)
+		io.WriteString(w, text)
+		return status, nil
+	}
+	content, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	w.Write(append(content, '\n'))
+	return status, nil
+}
+
+// documentedResponse returns the response of a status code, its range such as 4XX or the
+// default response, and the key it is documented under; the response is nil if the code
+// is not documented
+func (s *Server) documentedResponse(op *openapi.Operation, code string) (string, *openapi.Response, error) {
+	for _, key := range []string{code, code[:1] + "XX", code[:1] + "xx", "default"} {
+		if response, ok := op.Responses.Get(key); ok {
+			resolved, err := s.doc.ResolveResponse(response)
+			return key, resolved, err
+		}
+	}
+	return "", nil, nil
+}
+
+// exampleValue returns the named example of a media type, its first example or a value
+// built from its schema
+func (s *Server) exampleValue(media *openapi.MediaType, name string) (interface{}, error) {
+	if name != "" {
+		if example, ok := media.Examples[name]; ok && example != nil {
+			return example.Value, nil
+		}
+	}
+	if media.Example != nil {
+		return media.Example, nil
+	}
+	if len(media.Examples) > 0 {
+		names := make([]string, 0, len(media.Examples))
+		for name := range media.Examples {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		if example := media.Examples[names[0]]; example != nil {
+			return example.Value, nil
+		}
+	}
+	return s.doc.SampleValue(media.Schema)
+}
+
+// serveControl answers the endpoints of the server itself: GET /__mock/calls returns the
+// recorded calls and DELETE /__mock/calls forgets them
+func (s *Server) serveControl(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != ControlPath+"/calls" {
+		writeProblem(w, http.StatusNotFound, "The mock server only has "+ControlPath+"/calls", nil)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		calls := s.Calls()
+		if operation := r.URL.Query().Get("operation"); operation != "" {
+			var filtered []Call
+			for _, call := range calls {
+				if call.Operation == operation {
+					filtered = append(filtered, call)
+				}
+			}
+			calls = filtered
+		}
+		if calls == nil {
+			calls = []Call{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(calls)
+	case http.MethodDelete:
+		s.mu.Lock()
+		s.calls = nil
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, DELETE")
+		writeProblem(w, http.StatusMethodNotAllowed, ControlPath+"/calls supports GET and DELETE", nil)
+	}
+}
+
+// record keeps a call, writes it to the record and reports it
+func (s *Server) record(call Call, start time.Time) {
+	call.DurationMs = time.Since(start).Milliseconds()
+	s.mu.Lock()
+	s.calls = append(s.calls, call)
+	if len(s.calls) > maxCalls {
+		s.calls = s.calls[len(s.calls)-maxCalls:]
+	}
+	if s.opts.Record != nil {
+		if line, err := json.Marshal(call); err == nil {
+			s.opts.Record.Write(append(line, '\n'))
+		}
+	}
+	s.mu.Unlock()
+	if s.opts.OnCall != nil {
+		s.opts.OnCall(call)
+	}
+}
+
+// writeProblem writes an RFC 9457 problem detail
+func writeProblem(w http.ResponseWriter, status int, detail string, violations []Violation) {
+	problem := map[string]interface{}{
+		"type":   "about:blank",
+		"title":  http.StatusText(status),
+		"status": status,
+		"detail": detail,
+	}
+	if len(violations) > 0 {
+		problem["violations"] = violations
+	}
+	content, _ := json.MarshalIndent(problem, "", "  ")
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	w.Write(append(content, '\n'))
+}
+
+// parsePrefer returns the preferences of a Prefer header, such as code=404 or example=empty
+func parsePrefer(header string) map[string]string {
+	preferences := map[string]string{}
+	for _, part := range strings.FieldsFunc(header, func(r rune) bool { return r == ',' || r == ';' }) {
+		name, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		preferences[strings.ToLower(name)] = strings.Trim(value, `"`)
+	}
+	return preferences
+}
+
+// basePath returns the path of the first server URL of a document
+func basePath(doc *openapi.Document) string {
+	if len(doc.Servers) == 0 {
+		return ""
+	}
+	u, err := url.Parse(doc.Servers[0].URL)
+	if err != nil || strings.Contains(u.Path, "{") {
+		return ""
+	}
+	return strings.TrimSuffix(u.Path, "/")
+}
+
+// isJSON reports whether a content type holds JSON
+func isJSON(contentType string) bool {
+	return strings.HasPrefix(contentType, "application/json") || strings.Contains(contentType, "+json")
+}
@@ -0,0 +1,445 @@
+package mock
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"mime"
+	"net/http"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/springwell/cli/pkg/openapi"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// Violation is a part of a request that does not match the document
+type Violation struct {
+	Location string `json:"location"` // Such as "query.page" or "body.items[0].price"
+	Message  string `json:"message"`
+}
+
+// validateRequest returns the violations of the parameters and body of a request
+func (s *Server) validateRequest(r *http.Request, rt *route, pathValues map[string]string, body []byte) []Violation {
+	var violations []Violation
+	params, err := s.doc.OperationParameters(rt.item, rt.op)
+	if err != nil {
+		return []Violation{{Location: "operation", Message: err.Error()}}
+	}
+	query := r.URL.Query()
+	for _, param := range params {
+		var values []string
+		switch param.In {
+		case "path":
+			if value, ok := pathValues[param.Name]; ok {
+				values = []string{value}
+			}
+		case "query":
+			values = query[param.Name]
+		case "header":
+			// Content negotiation and credentials are described by other parts of the document
+			switch strings.ToLower(param.Name) {
+			case "accept", "content-type", "authorization":
+				continue
+			}
+			values = r.Header.Values(param.Name)
+		case "cookie":
+			if cookie, err := r.Cookie(param.Name); err == nil {
+				values = []string{cookie.Value}
+			}
+		default:
+			continue
+		}
+		location := param.In + "." + param.Name
+		if len(values) == 0 {
+			if param.Required || param.In == "path" {
+				violations = append(violations, Violation{location, "is required"})
+			}
+			continue
+		}
+		value, violation := s.parameterValue(param.Schema, values)
+		if violation != "" {
+			violations = append(violations, Violation{location, violation})
+			continue
+		}
+		violations = append(violations, s.validateValue(param.Schema, value, location, 0)...)
+	}
+
+	requestBody, err := s.doc.ResolveRequestBody(rt.op.RequestBody)
+	if err != nil {
+		return append(violations, Violation{"body", err.Error()})
+	}
+	if requestBody == nil || requestBody.Content.Len() == 0 {
+		return violations
+	}
+	if len(body) == 0 {
+		if requestBody.Required {
+			violations = append(violations, Violation{"body", "is required"})
+		}
+		return violations
+	}
+	contentType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return append(violations, Violation{"header.Content-Type", "is required with a body"})
+	}
+	media, ok := requestBody.Content.Get(contentType)
+	if !ok {
+		for _, key := range requestBody.Content.Keys {
+			if key == "*/*" || strings.HasSuffix(key, "/*") && strings.HasPrefix(contentType, strings.TrimSuffix(key, "*")) {
+				media, ok = requestBody.Content.Values[key], true
+				break
+			}
+		}
+	}
+	if !ok {
+		return append(violations, Violation{"header.Content-Type", fmt.Sprintf("%s is not one of %s", contentType, strings.Join(requestBody.Content.Keys, ", "))})
+	}
+	if !isJSON(contentType) || media == nil || media.Schema == nil {
+		return violations
+	}
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return append(violations, Violation{"body", "is not valid JSON: " + err.Error()})
+	}
+	return append(violations, s.validateValue(media.Schema, value, "body", 0)...)
+}
+
+// parameterValue converts the values of a parameter to the type of its schema, or returns
+// why they cannot be converted
+func (s *Server) parameterValue(schema *openapi.Schema, values []string) (interface{}, string) {
+	resolved, err := s.doc.ResolveSchema(schema)
+	if err != nil || resolved == nil {
+		return values[0], ""
+	}
+	if resolved.TypeName() == "array" {
+		items := []interface{}{}
+		for _, value := range values {
+			for _, part := range strings.Split(value, ",") {
+				item, violation := s.parameterValue(resolved.Items, []string{part})
+				if violation != "" {
+					return nil, violation
+				}
+				items = append(items, item)
+			}
+		}
+		return items, ""
+	}
+	value := values[0]
+	switch resolved.TypeName() {
+	case "integer", "number":
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Sprintf("must be %s, got %q", article(resolved.TypeName()), value)
+		}
+		return number, ""
+	case "boolean":
+		flag, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Sprintf("must be true or false, got %q", value)
+		}
+		return flag, ""
+	}
+	return value, ""
+}
+
+// validateValue returns the violations of a decoded JSON value against a schema of a
+// request. oneOf is checked like anyOf.
+func (s *Server) validateValue(schema *openapi.Schema, value interface{}, location string, depth int) []Violation {
+	schema, err := s.doc.ResolveSchema(schema)
+	if err != nil {
+		return []Violation{{location, err.Error()}}
+	}
+	if schema == nil || depth > 64 {
+		return nil
+	}
+	if schema.Boolean != nil {
+		if !*schema.Boolean {
+			return []Violation{{location, "is not allowed"}}
+		}
+		return nil
+	}
+	if value == nil {
+		if schema.IsNullable() || len(schema.Type) == 0 && len(schema.AllOf)+len(schema.OneOf)+len(schema.AnyOf) == 0 {
+			return nil
+		}
+		return []Violation{{location, "must not be null"}}
+	}
+
+	var violations []Violation
+	for _, part := range schema.AllOf {
+		violations = append(violations, s.validateValue(part, value, location, depth+1)...)
+	}
+	if options := append(append([]*openapi.Schema{}, schema.OneOf...), schema.AnyOf...); len(options) > 0 {
+		matched := false
+		for _, option := range options {
+			if len(s.validateValue(option, value, location, depth+1)) == 0 {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			violations = append(violations, Violation{location, "does not match any of the allowed schemas"})
+		}
+	}
+	if len(schema.Enum) > 0 && !enumContains(schema.Enum, value) {
+		allowed := make([]string, len(schema.Enum))
+		for i, constant := range schema.Enum {
+			allowed[i] = fmt.Sprint(constant)
+		}
+		violations = append(violations, Violation{location, "must be one of " + strings.Join(allowed, ", ")})
+	}
+
+	typeName := schema.TypeName()
+	if typeName == "" {
+		switch {
+		case schema.Properties.Len() > 0 || schema.AdditionalProperties != nil:
+			if _, ok := value.(map[string]interface{}); ok {
+				typeName = "object"
+			}
+		case schema.Items != nil:
+			if _, ok := value.([]interface{}); ok {
+				typeName = "array"
+			}
+		}
+	}
+	switch typeName {
+	case "string":
+		text, ok := value.(string)
+		if !ok {
+			return append(violations, Violation{location, "must be a string"})
+		}
+		violations = append(violations, s.validateString(schema, text, location)...)
+	case "integer", "number":
+		number, ok := value.(float64)
+		if !ok {
+			return append(violations, Violation{location, "must be " + article(typeName)})
+		}
+		if typeName == "integer" && number != math.Trunc(number) {
+			return append(violations, Violation{location, "must be an integer"})
+		}
+		violations = append(violations, validateNumber(schema, number, location)...)
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return append(violations, Violation{location, "must be true or false"})
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return append(violations, Violation{location, "must be an array"})
+		}
+		if schema.MinItems != nil && len(items) < *schema.MinItems {
+			violations = append(violations, Violation{location, fmt.Sprintf("must have at least %d item(s)", *schema.MinItems)})
+		}
+		if schema.MaxItems != nil && len(items) > *schema.MaxItems {
+			violations = append(violations, Violation{location, fmt.Sprintf("must have at most %d item(s)", *schema.MaxItems)})
+		}
+		if schema.UniqueItems {
+			for i := range items {
+				for j := 0; j < i; j++ {
+					if reflect.DeepEqual(items[i], items[j]) {
+						violations = append(violations, Violation{fmt.Sprintf("%s[%d]", location, i), "duplicates an earlier item"})
+					}
+				}
+			}
+		}
+		for i, item := range items {
+			violations = append(violations, s.validateValue(schema.Items, item, fmt.Sprintf("%s[%d]", location, i), depth+1)...)
+		}
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return append(violations, Violation{location, "must be an object"})
+		}
+		for _, name := range schema.Required {
+			if _, ok := object[name]; ok {
+				continue
+			}
+			// Read-only properties are set by the server and never sent
+			if property, ok := schema.Properties.Get(name); ok {
+				if resolved, err := s.doc.ResolveSchema(property); err == nil && resolved != nil && resolved.ReadOnly {
+					continue
+				}
+			}
+			violations = append(violations, Violation{location + "." + name, "is required"})
+		}
+		for _, name := range schema.Properties.Keys {
+			if property, ok := object[name]; ok {
+				violations = append(violations, s.validateValue(schema.Properties.Values[name], property, location+"."+name, depth+1)...)
+			}
+		}
+		for name, property := range object {
+			if _, ok := schema.Properties.Get(name); ok || schema.AdditionalProperties == nil {
+				continue
+			}
+			if additional := schema.AdditionalProperties; additional.Boolean != nil {
+				if !*additional.Boolean {
+					violations = append(violations, Violation{location + "." + name, "is not a known property"})
+				}
+				continue
+			}
+			violations = append(violations, s.validateValue(schema.AdditionalProperties, property, location+"."+name, depth+1)...)
+		}
+	}
+	return violations
+}
+
+// validateString returns the violations of the length, pattern and format of a string
+func (s *Server) validateString(schema *openapi.Schema, text, location string) []Violation {
+	var violations []Violation
+	length := len([]rune(text))
+	if schema.MinLength != nil && length < *schema.MinLength {
+		violations = append(violations, Violation{location, fmt.Sprintf("must have at least %d character(s)", *schema.MinLength)})
+	}
+	if schema.MaxLength != nil && length > *schema.MaxLength {
+		violations = append(violations, Violation{location, fmt.Sprintf("must have at most %d character(s)", *schema.MaxLength)})
+	}
+	if schema.Pattern != "" {
+		if pattern := s.pattern(schema.Pattern); pattern != nil && !pattern.MatchString(text) {
+			violations = append(violations, Violation{location, "must match " + schema.Pattern})
+		}
+	}
+	valid := true
+	switch schema.Format {
+	case "date":
+		_, err := time.Parse("2006-01-02", text)
+		valid = err == nil
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, text)
+		valid = err == nil
+	case "uuid":
+		valid = uuidPattern.MatchString(text)
+	case "email":
+		address, err := mail.ParseAddress(text)
+		valid = err == nil && address.Address == text
+	}
+	if !valid {
+		violations = append(violations, Violation{location, "must be a valid " + schema.Format})
+	}
+	return violations
+}
+
+// validateNumber returns the violations of the bounds of a number
+func validateNumber(schema *openapi.Schema, number float64, location string) []Violation {
+	var violations []Violation
+	if minimum, exclusive := schema.MinimumBound(); minimum != nil {
+		if exclusive && number <= *minimum {
+			violations = append(violations, Violation{location, fmt.Sprintf("must be greater than %g", *minimum)})
+		} else if number < *minimum {
+			violations = append(violations, Violation{location, fmt.Sprintf("must be at least %g", *minimum)})
+		}
+	}
+	if maximum, exclusive := schema.MaximumBound(); maximum != nil {
+		if exclusive && number >= *maximum {
+			violations = append(violations, Violation{location, fmt.Sprintf("must be less than %g", *maximum)})
+		} else if number > *maximum {
+			violations = append(violations, Violation{location, fmt.Sprintf("must be at most %g", *maximum)})
+		}
+	}
+	return violations
+}
+
+// pattern returns the compiled pattern of a schema, or nil if Go cannot compile it
+func (s *Server) pattern(expression string) *regexp.Regexp {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pattern, ok := s.patterns[expression]
+	if !ok {
+		pattern, _ = regexp.Compile(expression)
+		s.patterns[expression] = pattern
+	}
+	return pattern
+}
+
+// authorized reports whether a request has the credentials of one of the security
+// requirements of an operation; only their presence is checked
+func (s *Server) authorized(r *http.Request, op *openapi.Operation) bool {
+	requirements := s.doc.Security
+	if op.Security != nil {
+		requirements = op.Security
+	}
+	if len(requirements) == 0 {
+		return true
+	}
+	for _, requirement := range requirements {
+		satisfied := true
+		for name := range requirement {
+			scheme := s.doc.Components.SecuritySchemes[name]
+			if scheme != nil && !hasCredentials(r, scheme) {
+				satisfied = false
+			}
+		}
+		if satisfied {
+			return true
+		}
+	}
+	return false
+}
+
+// hasCredentials reports whether a request carries the credentials of a security scheme
+func hasCredentials(r *http.Request, scheme *openapi.SecurityScheme) bool {
+	switch scheme.Type {
+	case "apiKey":
+		switch scheme.In {
+		case "query":
+			return r.URL.Query().Get(scheme.Name) != ""
+		case "cookie":
+			_, err := r.Cookie(scheme.Name)
+			return err == nil
+		}
+		return r.Header.Get(scheme.Name) != ""
+	case "http":
+		authorization := r.Header.Get("Authorization")
+		return strings.HasPrefix(strings.ToLower(authorization), strings.ToLower(scheme.Scheme)+" ")
+	case "oauth2", "openIdConnect":
+		return strings.HasPrefix(strings.ToLower(r.Header.Get("Authorization")), "bearer ")
+	}
+	return true
+}
+
+// enumContains reports whether the constants of an enum hold a decoded JSON value
+func enumContains(constants []interface{}, value interface{}) bool {
+	for _, constant := range constants {
+		if reflect.DeepEqual(normalize(constant), value) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalize converts a value decoded from YAML to the types decoded from JSON
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case []interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = normalize(item)
+		}
+		return items
+	case map[string]interface{}:
+		object := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			object[key] = normalize(item)
+		}
+		return object
+	}
+	return value
+}
+
+// article returns a type name with its indefinite article, such as "an integer"
+func article(typeName string) string {
+	if strings.IndexByte("aeiou", typeName[0]) >= 0 {
+		return "an " + typeName
+	}
+	return "a " + typeName
+}
@@ -0,0 +1,649 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var pathParamPattern = regexp.MustCompile(`\{[^}]*\}`)
+
+// Change is a difference between two versions of a document
+type Change struct {
+	Breaking  bool   `json:"breaking"`
+	Operation string `json:"operation"` // Method and path, such as "GET /products/{id}"
+	Location  string `json:"location"`  // Part of the operation that changed, such as "request body price", or ""
+	Message   string `json:"message"`
+}
+
+// String describes the change on one line
+func (c Change) String() string {
+	var parts []string
+	for _, part := range []string{c.Operation, c.Location} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) == 0 {
+		return c.Message
+	}
+	return strings.Join(parts, " ") + ": " + c.Message
+}
+
+// direction tells whether a schema describes data clients send or data they receive: a
+// request schema may only accept more, a response schema may only promise more
+type direction int
+
+const (
+	request direction = iota
+	response
+)
+
+// differ compares the operations of two documents
+type differ struct {
+	old, new  *Document
+	operation string
+	changes   []Change
+	visited   map[string]bool
+}
+
+// Diff compares two versions of a document and returns what changed in their operations,
+// breaking changes being those that can fail clients written against the old version
+func Diff(old, new *Document) []Change {
+	d := &differ{old: old, new: new, visited: map[string]bool{}}
+
+	// Paths match regardless of the names of their parameters
+	newPaths := map[string]string{}
+	for _, path := range new.Paths.Keys {
+		newPaths[pathParamPattern.ReplaceAllString(path, "{}")] = path
+	}
+	oldPaths := map[string]bool{}
+	for _, oldPath := range old.Paths.Keys {
+		key := pathParamPattern.ReplaceAllString(oldPath, "{}")
+		oldPaths[key] = true
+		oldItem := old.Paths.Values[oldPath]
+		newPath, ok := newPaths[key]
+		if !ok {
+			for _, method := range Methods {
+				if oldItem.Operation(method) != nil {
+					d.operation = strings.ToUpper(method) + " " + oldPath
+					d.add(true, "", "path removed")
+				}
+			}
+			continue
+		}
+		newItem := new.Paths.Values[newPath]
+		for _, method := range Methods {
+			oldOp, newOp := oldItem.Operation(method), newItem.Operation(method)
+			d.operation = strings.ToUpper(method) + " " + newPath
+			switch {
+			case oldOp == nil && newOp == nil:
+			case newOp == nil:
+				d.operation = strings.ToUpper(method) + " " + oldPath
+				d.add(true, "", "operation removed")
+			case oldOp == nil:
+				d.add(false, "", "operation added")
+			default:
+				d.compareOperation(oldItem, oldOp, newItem, newOp)
+			}
+		}
+	}
+	for _, newPath := range new.Paths.Keys {
+		if oldPaths[pathParamPattern.ReplaceAllString(newPath, "{}")] {
+			continue
+		}
+		for _, method := range Methods {
+			if new.Paths.Values[newPath].Operation(method) != nil {
+				d.operation = strings.ToUpper(method) + " " + newPath
+				d.add(false, "", "operation added")
+			}
+		}
+	}
+	return d.changes
+}
+
+// Breaking returns the number of breaking changes
+func Breaking(changes []Change) int {
+	count := 0
+	for _, change := range changes {
+		if change.Breaking {
+			count++
+		}
+	}
+	return count
+}
+
+// FormatChanges renders changes as text, json or markdown, breaking changes first
+func FormatChanges(changes []Change, format string) (string, error) {
+	var breaking, other []Change
+	for _, change := range changes {
+		if change.Breaking {
+			breaking = append(breaking, change)
+		} else {
+			other = append(other, change)
+		}
+	}
+	var b strings.Builder
+	switch format {
+	case "text":
+		if len(changes) == 0 {
+			return "No changes\n", nil
+		}
+		for _, group := range []struct {
+			title   string
+			changes []Change
+		}{{"Breaking changes", breaking}, {"Non-breaking changes", other}} {
+			if len(group.changes) == 0 {
+				continue
+			}
+			fmt.Fprintf(&b, "%s (%d):\n", group.title, len(group.changes))
+			for _, change := range group.changes {
+				fmt.Fprintf(&b, "  %s\n", change)
+			}
+		}
+	case "json":
+		content, err := json.MarshalIndent(map[string]interface{}{
+			"breaking": len(breaking),
+			"changes":  append(append([]Change{}, breaking...), other...),
+		}, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		b.Write(content)
+		b.WriteString("\n")
+	case "markdown":
+		b.WriteString("## API changes\n\n")
+		if len(changes) == 0 {
+			b.WriteString("No changes to the API.\n")
+			return b.String(), nil
+		}
+		if len(breaking) > 0 {
+			fmt.Fprintf(&b, "**%d breaking change(s)** can fail existing clients.\n\n", len(breaking))
+		} else {
+			b.WriteString("No breaking changes.\n\n")
+		}
+		b.WriteString("| | Operation | Location | Change |\n|---|---|---|---|\n")
+		for _, change := range append(breaking, other...) {
+			kind := "Non-breaking"
+			if change.Breaking {
+				kind = ":warning: Breaking"
+			}
+			fmt.Fprintf(&b, "| %s | `%s` | %s | %s |\n", kind, change.Operation, markdownCell(change.Location), markdownCell(change.Message))
+		}
+	default:
+		return "", fmt.Errorf("unsupported format %s, use text, json or markdown", format)
+	}
+	return b.String(), nil
+}
+
+// add records a change of the current operation
+func (d *differ) add(breaking bool, location, format string, args ...interface{}) {
+	d.changes = append(d.changes, Change{
+		Breaking:  breaking,
+		Operation: d.operation,
+		Location:  location,
+		Message:   fmt.Sprintf(format, args...),
+	})
+}
+
+// compareOperation compares the parameters, request body and responses of an operation
+func (d *differ) compareOperation(oldItem *PathItem, oldOp *Operation, newItem *PathItem, newOp *Operation) {
+	if newOp.Deprecated && !oldOp.Deprecated {
+		d.add(false, "", "operation deprecated")
+	}
+
+	oldParams, err := d.old.OperationParameters(oldItem, oldOp)
+	if err != nil {
+		d.add(false, "", "cannot read the old parameters: %v", err)
+		return
+	}
+	newParams, err := d.new.OperationParameters(newItem, newOp)
+	if err != nil {
+		d.add(true, "", "cannot read the parameters: %v", err)
+		return
+	}
+	d.compareParameters(oldParams, newParams)
+	d.compareRequestBody(oldOp.RequestBody, newOp.RequestBody)
+	d.compareResponses(oldOp, newOp)
+}
+
+// compareParameters compares the parameters of an operation, path parameters matching by
+// position since renaming them does not change requests
+func (d *differ) compareParameters(oldParams, newParams []*Parameter) {
+	key := func(p *Parameter, index map[string]int) string {
+		if p.In == "path" {
+			index["path"]++
+			return fmt.Sprintf("path:%d", index["path"])
+		}
+		return p.In + ":" + p.Name
+	}
+	oldIndex, newIndex := map[string]int{}, map[string]int{}
+	oldByKey := map[string]*Parameter{}
+	for _, p := range oldParams {
+		oldByKey[key(p, oldIndex)] = p
+	}
+	seen := map[string]bool{}
+	for _, p := range newParams {
+		k := key(p, newIndex)
+		seen[k] = true
+		location := p.In + " parameter " + p.Name
+		old, ok := oldByKey[k]
+		if !ok {
+			if p.Required {
+				d.add(true, location, "required parameter added")
+			} else {
+				d.add(false, location, "optional parameter added")
+			}
+			continue
+		}
+		if p.Required && !old.Required {
+			d.add(true, location, "parameter became required")
+		} else if !p.Required && old.Required {
+			d.add(false, location, "parameter became optional")
+		}
+		if p.Deprecated && !old.Deprecated {
+			d.add(false, location, "parameter deprecated")
+		}
+		d.compareSchema(location, "", old.Schema, p.Schema, request)
+	}
+	for _, p := range oldParams {
+		if p.In != "path" && !seen[p.In+":"+p.Name] {
+			d.add(true, p.In+" parameter "+p.Name, "parameter removed")
+		}
+	}
+}
+
+// compareRequestBody compares the request bodies of an operation
+func (d *differ) compareRequestBody(oldBody, newBody *RequestBody) {
+	oldBody, errOld := d.old.ResolveRequestBody(oldBody)
+	newBody, errNew := d.new.ResolveRequestBody(newBody)
+	if errOld != nil || errNew != nil {
+		d.add(true, "request body", "cannot resolve the request body")
+		return
+	}
+	switch {
+	case oldBody == nil && newBody == nil:
+		return
+	case oldBody == nil:
+		if newBody.Required {
+			d.add(true, "request body", "required request body added")
+		} else {
+			d.add(false, "request body", "optional request body added")
+		}
+		return
+	case newBody == nil:
+		d.add(true, "request body", "request body removed")
+		return
+	}
+	if newBody.Required && !oldBody.Required {
+		d.add(true, "request body", "request body became required")
+	}
+	for _, contentType := range oldBody.Content.Keys {
+		newMedia, ok := newBody.Content.Get(contentType)
+		if !ok {
+			d.add(true, "request body", "media type %s removed", contentType)
+			continue
+		}
+		d.compareSchema("request body", "", oldBody.Content.Values[contentType].Schema, newMedia.Schema, request)
+	}
+	for _, contentType := range newBody.Content.Keys {
+		if _, ok := oldBody.Content.Get(contentType); !ok {
+			d.add(false, "request body", "media type %s added", contentType)
+		}
+	}
+}
+
+// compareResponses compares the responses of an operation
+func (d *differ) compareResponses(oldOp, newOp *Operation) {
+	for _, code := range oldOp.Responses.Keys {
+		location := "response " + code
+		oldResponse, errOld := d.old.ResolveResponse(oldOp.Responses.Values[code])
+		newResponse, ok := newOp.Responses.Get(code)
+		if !ok {
+			// Clients handle the successes they were told about, errors they handle anyway
+			d.add(strings.HasPrefix(code, "2"), location, "response removed")
+			continue
+		}
+		newResponse, errNew := d.new.ResolveResponse(newResponse)
+		if errOld != nil || errNew != nil {
+			d.add(true, location, "cannot resolve the response")
+			continue
+		}
+		for _, contentType := range oldResponse.Content.Keys {
+			newMedia, ok := newResponse.Content.Get(contentType)
+			if !ok {
+				d.add(true, location, "media type %s removed", contentType)
+				continue
+			}
+			d.compareSchema(location, "", oldResponse.Content.Values[contentType].Schema, newMedia.Schema, response)
+		}
+		for _, contentType := range newResponse.Content.Keys {
+			if _, ok := oldResponse.Content.Get(contentType); !ok {
+				d.add(false, location, "media type %s added", contentType)
+			}
+		}
+	}
+	for _, code := range newOp.Responses.Keys {
+		if _, ok := oldOp.Responses.Get(code); !ok {
+			d.add(false, "response "+code, "response added")
+		}
+	}
+}
+
+// compareSchema compares two versions of a schema describing data sent in a direction. The
+// path locates the schema in the parameter or body, such as ".customer.name" or "[]"
+func (d *differ) compareSchema(base, path string, oldSchema, newSchema *Schema, dir direction) {
+	location := base
+	if path != "" {
+		location += " " + strings.TrimPrefix(path, ".")
+	}
+	// A change is breaking when it narrows a request or widens a response
+	narrowing := func(narrowed bool) bool {
+		return narrowed == (dir == request)
+	}
+	switch {
+	case oldSchema == nil && newSchema == nil:
+		return
+	case oldSchema == nil:
+		d.add(narrowing(true), location, "schema added")
+		return
+	case newSchema == nil:
+		d.add(narrowing(false), location, "schema removed")
+		return
+	}
+	// Compare every pair of referenced schemas once per direction, which also ends recursion
+	if oldSchema.Ref != "" || newSchema.Ref != "" {
+		key := fmt.Sprintf("%s|%s|%d", oldSchema.Ref, newSchema.Ref, dir)
+		if d.visited[key] {
+			return
+		}
+		d.visited[key] = true
+	}
+	old, errOld := d.old.flatten(oldSchema)
+	new, errNew := d.new.flatten(newSchema)
+	if errOld != nil || errNew != nil {
+		d.add(true, location, "cannot resolve the schema")
+		return
+	}
+	if old.Boolean != nil || new.Boolean != nil {
+		if old.Boolean != nil && new.Boolean != nil && *old.Boolean != *new.Boolean {
+			d.add((*new.Boolean) == (dir == response), location, "schema changed from %v to %v", *old.Boolean, *new.Boolean)
+		}
+		return
+	}
+	oldType, newType := old.TypeName(), new.TypeName()
+	switch {
+	case oldType == newType:
+	case oldType == "":
+		d.add(narrowing(true), location, "type restricted to %s", newType)
+	case newType == "":
+		d.add(narrowing(false), location, "type %s no longer restricted", oldType)
+	case oldType == "integer" && newType == "number":
+		d.add(narrowing(false), location, "type widened from integer to number")
+	case oldType == "number" && newType == "integer":
+		d.add(narrowing(true), location, "type narrowed from number to integer")
+	default:
+		d.add(true, location, "type changed from %s to %s", oldType, newType)
+		return
+	}
+	d.compareFormat(location, old.Format, new.Format, narrowing)
+
+	if old.IsNullable() && !new.IsNullable() {
+		d.add(narrowing(true), location, "no longer nullable")
+	} else if !old.IsNullable() && new.IsNullable() {
+		d.add(narrowing(false), location, "became nullable")
+	}
+	d.compareEnum(location, old.Enum, new.Enum, narrowing)
+
+	d.compareLimit(location, "minLength", intLimit(old.MinLength), intLimit(new.MinLength), true, narrowing)
+	d.compareLimit(location, "maxLength", intLimit(old.MaxLength), intLimit(new.MaxLength), false, narrowing)
+	d.compareLimit(location, "minItems", intLimit(old.MinItems), intLimit(new.MinItems), true, narrowing)
+	d.compareLimit(location, "maxItems", intLimit(old.MaxItems), intLimit(new.MaxItems), false, narrowing)
+	oldMin, _ := old.MinimumBound()
+	newMin, _ := new.MinimumBound()
+	d.compareLimit(location, "minimum", oldMin, newMin, true, narrowing)
+	oldMax, _ := old.MaximumBound()
+	newMax, _ := new.MaximumBound()
+	d.compareLimit(location, "maximum", oldMax, newMax, false, narrowing)
+	if old.Pattern != new.Pattern {
+		switch {
+		case old.Pattern == "":
+			d.add(narrowing(true), location, "pattern %s added", new.Pattern)
+		case new.Pattern == "":
+			d.add(narrowing(false), location, "pattern %s removed", old.Pattern)
+		default:
+			d.add(true, location, "pattern changed from %s to %s", old.Pattern, new.Pattern)
+		}
+	}
+	if !old.UniqueItems && new.UniqueItems {
+		d.add(narrowing(true), location, "items must be unique")
+	}
+
+	d.compareProperties(base, path, old, new, dir)
+	if old.Items != nil || new.Items != nil {
+		d.compareSchema(base, path+"[]", old.Items, new.Items, dir)
+	}
+	if old.AdditionalProperties != nil && new.AdditionalProperties != nil {
+		d.compareSchema(base, path+"{}", old.AdditionalProperties, new.AdditionalProperties, dir)
+	}
+	d.compareVariants(location, "oneOf", old.OneOf, new.OneOf, narrowing)
+	d.compareVariants(location, "anyOf", old.AnyOf, new.AnyOf, narrowing)
+}
+
+// compareProperties compares the properties of two object schemas
+func (d *differ) compareProperties(base, path string, old, new *Schema, dir direction) {
+	visible := func(s *Schema) bool {
+		if dir == request {
+			return !s.ReadOnly
+		}
+		return !s.WriteOnly
+	}
+	for _, name := range old.Properties.Keys {
+		property := base + " " + strings.TrimPrefix(path+"."+name, ".")
+		oldProperty := old.Properties.Values[name]
+		newProperty, ok := new.Properties.Get(name)
+		if !ok {
+			if visible(oldProperty) {
+				// Requests with the property are still accepted, as unknown properties are ignored
+				d.add(dir == response, property, "property removed")
+			}
+			continue
+		}
+		if !visible(newProperty) {
+			continue
+		}
+		switch {
+		case new.IsRequired(name) && !old.IsRequired(name) && dir == request:
+			d.add(true, property, "property became required")
+		case !new.IsRequired(name) && old.IsRequired(name) && dir == response:
+			d.add(true, property, "property is no longer always present")
+		case new.IsRequired(name) != old.IsRequired(name):
+			d.add(false, property, "property %s", map[bool]string{true: "became required", false: "became optional"}[new.IsRequired(name)])
+		}
+		d.compareSchema(base, path+"."+name, oldProperty, newProperty, dir)
+	}
+	for _, name := range new.Properties.Keys {
+		if _, ok := old.Properties.Get(name); ok || !visible(new.Properties.Values[name]) {
+			continue
+		}
+		property := base + " " + strings.TrimPrefix(path+"."+name, ".")
+		if dir == request && new.IsRequired(name) {
+			d.add(true, property, "required property added")
+		} else {
+			d.add(false, property, "property added")
+		}
+	}
+}
+
+// compareFormat compares the formats of two schemas, integer and number formats being
+// ordered by width
+func (d *differ) compareFormat(location, old, new string, narrowing func(bool) bool) {
+	if old == new {
+		return
+	}
+	widths := map[string]int{"int32": 1, "int64": 2, "float": 1, "double": 2}
+	switch {
+	case old == "":
+		d.add(narrowing(true), location, "format %s added", new)
+	case new == "":
+		d.add(narrowing(false), location, "format %s removed", old)
+	case widths[old] > 0 && widths[new] > 0:
+		d.add(narrowing(widths[new] < widths[old]), location, "format changed from %s to %s", old, new)
+	default:
+		d.add(true, location, "format changed from %s to %s", old, new)
+	}
+}
+
+// compareEnum compares the allowed values of two schemas
+func (d *differ) compareEnum(location string, old, new []interface{}, narrowing func(bool) bool) {
+	if len(old) == 0 && len(new) == 0 {
+		return
+	}
+	if len(old) == 0 {
+		d.add(narrowing(true), location, "values restricted to %s", enumList(new))
+		return
+	}
+	if len(new) == 0 {
+		d.add(narrowing(false), location, "values no longer restricted")
+		return
+	}
+	if removed := enumDifference(old, new); len(removed) > 0 {
+		d.add(narrowing(true), location, "enum values removed: %s", enumList(removed))
+	}
+	if added := enumDifference(new, old); len(added) > 0 {
+		d.add(narrowing(false), location, "enum values added: %s", enumList(added))
+	}
+}
+
+// compareLimit compares a lower or upper limit of two schemas
+func (d *differ) compareLimit(location, keyword string, old, new *float64, lower bool, narrowing func(bool) bool) {
+	switch {
+	case old == nil && new == nil:
+	case old == nil:
+		d.add(narrowing(true), location, "%s %v added", keyword, *new)
+	case new == nil:
+		d.add(narrowing(false), location, "%s %v removed", keyword, *old)
+	case *old != *new:
+		tightened := (*new > *old) == lower
+		verb := map[bool]string{true: "increased", false: "decreased"}[*new > *old]
+		d.add(narrowing(tightened), location, "%s %s from %v to %v", keyword, verb, *old, *new)
+	}
+}
+
+// compareVariants compares the referenced alternatives of oneOf or anyOf
+func (d *differ) compareVariants(location, keyword string, old, new []*Schema, narrowing func(bool) bool) {
+	names := func(schemas []*Schema) map[string]bool {
+		set := map[string]bool{}
+		for _, s := range schemas {
+			if name := SchemaName(s); name != "" {
+				set[name] = true
+			}
+		}
+		return set
+	}
+	oldNames, newNames := names(old), names(new)
+	for _, name := range sortedKeys(oldNames) {
+		if !newNames[name] {
+			d.add(narrowing(true), location, "%s alternative %s removed", keyword, name)
+		}
+	}
+	for _, name := range sortedKeys(newNames) {
+		if !oldNames[name] {
+			d.add(narrowing(false), location, "%s alternative %s added", keyword, name)
+		}
+	}
+}
+
+// flatten resolves a schema and merges the properties and constraints of its allOf parts
+func (d *Document) flatten(s *Schema) (*Schema, error) {
+	resolved, err := d.ResolveSchema(s)
+	if err != nil || len(resolved.AllOf) == 0 {
+		return resolved, err
+	}
+	merged := *resolved
+	merged.AllOf = nil
+	merged.Properties = OrderedMap[*Schema]{Keys: append([]string(nil), resolved.Properties.Keys...), Values: map[string]*Schema{}}
+	for name, property := range resolved.Properties.Values {
+		merged.Properties.Values[name] = property
+	}
+	merged.Required = append([]string(nil), resolved.Required...)
+	for _, part := range resolved.AllOf {
+		flat, err := d.flatten(part)
+		if err != nil {
+			return nil, err
+		}
+		if len(merged.Type) == 0 {
+			merged.Type = flat.Type
+		}
+		if merged.Format == "" {
+			merged.Format = flat.Format
+		}
+		if len(merged.Enum) == 0 {
+			merged.Enum = flat.Enum
+		}
+		if merged.Items == nil {
+			merged.Items = flat.Items
+		}
+		for _, name := range flat.Properties.Keys {
+			if _, ok := merged.Properties.Values[name]; !ok {
+				merged.Properties.Keys = append(merged.Properties.Keys, name)
+				merged.Properties.Values[name] = flat.Properties.Values[name]
+			}
+		}
+		merged.Required = append(merged.Required, flat.Required...)
+	}
+	return &merged, nil
+}
+
+// markdownCell escapes text for a Markdown table cell
+func markdownCell(text string) string {
+	return strings.ReplaceAll(text, "|", "\\|")
+}
+
+// intLimit converts an integer limit for comparison with numeric ones
+func intLimit(limit *int) *float64 {
+	if limit == nil {
+		return nil
+	}
+	value := float64(*limit)
+	return &value
+}
+
+// enumDifference returns the values of a that are not in b
+func enumDifference(a, b []interface{}) []interface{} {
+	var difference []interface{}
+	for _, value := range a {
+		found := false
+		for _, other := range b {
+			if fmt.Sprint(value) == fmt.Sprint(other) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			difference = append(difference, value)
+		}
+	}
+	return difference
+}
+
+// enumList formats enum values for a message
+func enumList(values []interface{}) string {
+	var parts []string
+	for _, value := range values {
+		parts = append(parts, fmt.Sprint(value))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// sortedKeys returns the keys of a set in order
+func sortedKeys(set map[string]bool) []string {
+	var keys []string
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
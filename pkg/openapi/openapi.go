@@ -0,0 +1,512 @@
+package openapi
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Methods lists the HTTP methods of path items in the order operations are listed
+var Methods = []string{"get", "post", "put", "patch", "delete", "head", "options"}
+
+// Document is an OpenAPI 3.0 or 3.1 document
+type Document struct {
+	OpenAPI    string                `yaml:"openapi"`
+	Info       Info                  `yaml:"info"`
+	Servers    []Server              `yaml:"servers"`
+	Tags       []Tag                 `yaml:"tags"`
+	Paths      OrderedMap[*PathItem] `yaml:"paths"`
+	Components Components            `yaml:"components"`
+	Security   []SecurityRequirement `yaml:"security"`
+}
+
+// Info describes the API
+type Info struct {
+	Title       string `yaml:"title"`
+	Description string `yaml:"description"`
+	Version     string `yaml:"version"`
+}
+
+// Server is a base URL of the API
+type Server struct {
+	URL         string `yaml:"url"`
+	Description string `yaml:"description"`
+}
+
+// Tag groups operations
+type Tag struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+}
+
+// SecurityRequirement maps security scheme names to the scopes an operation needs
+type SecurityRequirement map[string][]string
+
+// Components holds the reusable objects of a document
+type Components struct {
+	Schemas         OrderedMap[*Schema]        `yaml:"schemas"`
+	Parameters      map[string]*Parameter      `yaml:"parameters"`
+	RequestBodies   map[string]*RequestBody    `yaml:"requestBodies"`
+	Responses       map[string]*Response       `yaml:"responses"`
+	SecuritySchemes map[string]*SecurityScheme `yaml:"securitySchemes"`
+}
+
+// PathItem holds the operations on a path
+type PathItem struct {
+	Summary     string       `yaml:"summary"`
+	Description string       `yaml:"description"`
+	Parameters  []*Parameter `yaml:"parameters"`
+	Get         *Operation   `yaml:"get"`
+	Put         *Operation   `yaml:"put"`
+	Post        *Operation   `yaml:"post"`
+	Delete      *Operation   `yaml:"delete"`
+	Options     *Operation   `yaml:"options"`
+	Head        *Operation   `yaml:"head"`
+	Patch       *Operation   `yaml:"patch"`
+}
+
+// Operation returns the operation of an HTTP method, or nil
+func (p *PathItem) Operation(method string) *Operation {
+	switch strings.ToLower(method) {
+	case "get":
+		return p.Get
+	case "put":
+		return p.Put
+	case "post":
+		return p.Post
+	case "delete":
+		return p.Delete
+	case "options":
+		return p.Options
+	case "head":
+		return p.Head
+	case "patch":
+		return p.Patch
+	}
+	return nil
+}
+
+// Operation is an HTTP method on a path
+type Operation struct {
+	OperationID string                `yaml:"operationId"`
+	Summary     string                `yaml:"summary"`
+	Description string                `yaml:"description"`
+	Tags        []string              `yaml:"tags"`
+	Parameters  []*Parameter          `yaml:"parameters"`
+	RequestBody *RequestBody          `yaml:"requestBody"`
+	Responses   OrderedMap[*Response] `yaml:"responses"`
+	Deprecated  bool                  `yaml:"deprecated"`
+	Security    []SecurityRequirement `yaml:"security"`
+}
+
+// Parameter is a path, query, header or cookie parameter
+type Parameter struct {
+	Ref         string      `yaml:"$ref"`
+	Name        string      `yaml:"name"`
+	In          string      `yaml:"in"`
+	Description string      `yaml:"description"`
+	Required    bool        `yaml:"required"`
+	Deprecated  bool        `yaml:"deprecated"`
+	Schema      *Schema     `yaml:"schema"`
+	Example     interface{} `yaml:"example"`
+}
+
+// RequestBody is the body of a request
+type RequestBody struct {
+	Ref         string                 `yaml:"$ref"`
+	Description string                 `yaml:"description"`
+	Required    bool                   `yaml:"required"`
+	Content     OrderedMap[*MediaType] `yaml:"content"`
+}
+
+// Response is a response of an operation
+type Response struct {
+	Ref         string                 `yaml:"$ref"`
+	Description string                 `yaml:"description"`
+	Content     OrderedMap[*MediaType] `yaml:"content"`
+}
+
+// MediaType is the schema and examples of a request or response body in a content type
+type MediaType struct {
+	Schema   *Schema             `yaml:"schema"`
+	Example  interface{}         `yaml:"example"`
+	Examples map[string]*Example `yaml:"examples"`
+}
+
+// Example is a named example of a body
+type Example struct {
+	Summary string      `yaml:"summary"`
+	Value   interface{} `yaml:"value"`
+}
+
+// SecurityScheme describes how clients authenticate
+type SecurityScheme struct {
+	Type             string `yaml:"type"`
+	Description      string `yaml:"description"`
+	Name             string `yaml:"name"`
+	In               string `yaml:"in"`
+	Scheme           string `yaml:"scheme"`
+	BearerFormat     string `yaml:"bearerFormat"`
+	OpenIDConnectURL string `yaml:"openIdConnectUrl"`
+}
+
+// Schema is a JSON Schema as used by OpenAPI 3.0 and 3.1
+type Schema struct {
+	Ref                  string              `yaml:"$ref"`
+	Type                 SchemaType          `yaml:"type"`
+	Format               string              `yaml:"format"`
+	Title                string              `yaml:"title"`
+	Description          string              `yaml:"description"`
+	Properties           OrderedMap[*Schema] `yaml:"properties"`
+	Required             []string            `yaml:"required"`
+	Items                *Schema             `yaml:"items"`
+	AdditionalProperties *Schema             `yaml:"additionalProperties"`
+	AllOf                []*Schema           `yaml:"allOf"`
+	OneOf                []*Schema           `yaml:"oneOf"`
+	AnyOf                []*Schema           `yaml:"anyOf"`
+	Enum                 []interface{}       `yaml:"enum"`
+	Default              interface{}         `yaml:"default"`
+	Example              interface{}         `yaml:"example"`
+	Examples             []interface{}       `yaml:"examples"`
+	Nullable             bool                `yaml:"nullable"`
+	ReadOnly             bool                `yaml:"readOnly"`
+	WriteOnly            bool                `yaml:"writeOnly"`
+	Deprecated           bool                `yaml:"deprecated"`
+	MinLength            *int                `yaml:"minLength"`
+	MaxLength            *int                `yaml:"maxLength"`
+	Pattern              string              `yaml:"pattern"`
+	Minimum              *float64            `yaml:"minimum"`
+	Maximum              *float64            `yaml:"maximum"`
+	ExclusiveMinimum     Bound               `yaml:"exclusiveMinimum"`
+	ExclusiveMaximum     Bound               `yaml:"exclusiveMaximum"`
+	MinItems             *int                `yaml:"minItems"`
+	MaxItems             *int                `yaml:"maxItems"`
+	UniqueItems          bool                `yaml:"uniqueItems"`
+
+	// Boolean is set for the schemas true and false, as allowed for additionalProperties
+	Boolean *bool `yaml:"-"`
+}
+
+// UnmarshalYAML decodes a schema, accepting the boolean schemas true and false
+func (s *Schema) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode && node.ShortTag() == "!!bool" {
+		value := node.Value == "true"
+		*s = Schema{Boolean: &value}
+		return nil
+	}
+	type plain Schema
+	return node.Decode((*plain)(s))
+}
+
+// TypeName returns the type of the schema other than null, or "" if it has none
+func (s *Schema) TypeName() string {
+	for _, t := range s.Type {
+		if t != "null" {
+			return t
+		}
+	}
+	return ""
+}
+
+// IsNullable reports whether the schema allows null, with the 3.0 nullable keyword or
+// the 3.1 null type
+func (s *Schema) IsNullable() bool {
+	if s.Nullable {
+		return true
+	}
+	for _, t := range s.Type {
+		if t == "null" {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRequired reports whether a property of an object schema is required
+func (s *Schema) IsRequired(property string) bool {
+	for _, name := range s.Required {
+		if name == property {
+			return true
+		}
+	}
+	return false
+}
+
+// ExampleValue returns the example of the schema, from example or the first of examples
+func (s *Schema) ExampleValue() interface{} {
+	if s.Example != nil {
+		return s.Example
+	}
+	if len(s.Examples) > 0 {
+		return s.Examples[0]
+	}
+	return nil
+}
+
+// SchemaType is the type of a schema: a single name in 3.0, a name or a list of names in 3.1
+type SchemaType []string
+
+// UnmarshalYAML decodes a type name or a list of type names
+func (t *SchemaType) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*t = SchemaType{node.Value}
+		return nil
+	}
+	var names []string
+	if err := node.Decode(&names); err != nil {
+		return err
+	}
+	*t = names
+	return nil
+}
+
+// Bound is an exclusive minimum or maximum: a flag on minimum or maximum in 3.0, a
+// number in 3.1
+type Bound struct {
+	Set   bool
+	Value *float64
+}
+
+// UnmarshalYAML decodes a boolean or numeric bound
+func (b *Bound) UnmarshalYAML(node *yaml.Node) error {
+	if node.ShortTag() == "!!bool" {
+		b.Set = node.Value == "true"
+		return nil
+	}
+	value, err := strconv.ParseFloat(node.Value, 64)
+	if err != nil {
+		return fmt.Errorf("invalid exclusive bound %q", node.Value)
+	}
+	b.Set = true
+	b.Value = &value
+	return nil
+}
+
+// MinimumBound returns the minimum of the schema and whether it is exclusive
+func (s *Schema) MinimumBound() (*float64, bool) {
+	if s.ExclusiveMinimum.Value != nil {
+		return s.ExclusiveMinimum.Value, true
+	}
+	return s.Minimum, s.ExclusiveMinimum.Set
+}
+
+// MaximumBound returns the maximum of the schema and whether it is exclusive
+func (s *Schema) MaximumBound() (*float64, bool) {
+	if s.ExclusiveMaximum.Value != nil {
+		return s.ExclusiveMaximum.Value, true
+	}
+	return s.Maximum, s.ExclusiveMaximum.Set
+}
+
+// OrderedMap is a YAML mapping that keeps the order of its keys
+type OrderedMap[T any] struct {
+	Keys   []string
+	Values map[string]T
+}
+
+// UnmarshalYAML decodes a mapping, recording the order of its keys
+func (m *OrderedMap[T]) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: expected a mapping", node.Line)
+	}
+	m.Keys = nil
+	m.Values = map[string]T{}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i].Value
+		var value T
+		if err := node.Content[i+1].Decode(&value); err != nil {
+			return err
+		}
+		if _, ok := m.Values[key]; !ok {
+			m.Keys = append(m.Keys, key)
+		}
+		m.Values[key] = value
+	}
+	return nil
+}
+
+// Get returns the value of a key
+func (m OrderedMap[T]) Get(key string) (T, bool) {
+	value, ok := m.Values[key]
+	return value, ok
+}
+
+// Len returns the number of keys
+func (m OrderedMap[T]) Len() int {
+	return len(m.Keys)
+}
+
+// Load reads an OpenAPI document from a YAML or JSON file
+func Load(path string) (*Document, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI document %s: %v", path, err)
+	}
+	return doc, nil
+}
+
+// Parse decodes an OpenAPI document from YAML or JSON
+func Parse(content []byte) (*Document, error) {
+	var doc Document
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		if doc.OpenAPI == "" {
+			return nil, fmt.Errorf("missing openapi version, only OpenAPI 3 is supported")
+		}
+		return nil, fmt.Errorf("unsupported OpenAPI version %s, only OpenAPI 3 is supported", doc.OpenAPI)
+	}
+	return &doc, nil
+}
+
+// SchemaName returns the component name a schema reference points to, or "" if the
+// schema is not a reference
+func SchemaName(s *Schema) string {
+	if s == nil {
+		return ""
+	}
+	return strings.TrimPrefix(s.Ref, "#/components/schemas/")
+}
+
+// ResolveSchema follows component references until it reaches a schema that is not one
+func (d *Document) ResolveSchema(s *Schema) (*Schema, error) {
+	for depth := 0; s != nil && s.Ref != ""; depth++ {
+		if depth > 32 {
+			return nil, fmt.Errorf("circular reference %s", s.Ref)
+		}
+		name, err := localRef(s.Ref, "schemas")
+		if err != nil {
+			return nil, err
+		}
+		resolved, ok := d.Components.Schemas.Get(name)
+		if !ok {
+			return nil, fmt.Errorf("schema %s not found", s.Ref)
+		}
+		s = resolved
+	}
+	return s, nil
+}
+
+// ResolveParameter follows a parameter reference
+func (d *Document) ResolveParameter(p *Parameter) (*Parameter, error) {
+	if p.Ref == "" {
+		return p, nil
+	}
+	name, err := localRef(p.Ref, "parameters")
+	if err != nil {
+		return nil, err
+	}
+	resolved, ok := d.Components.Parameters[name]
+	if !ok {
+		return nil, fmt.Errorf("parameter %s not found", p.Ref)
+	}
+	return d.ResolveParameter(resolved)
+}
+
+// ResolveRequestBody follows a request body reference
+func (d *Document) ResolveRequestBody(b *RequestBody) (*RequestBody, error) {
+	if b == nil || b.Ref == "" {
+		return b, nil
+	}
+	name, err := localRef(b.Ref, "requestBodies")
+	if err != nil {
+		return nil, err
+	}
+	resolved, ok := d.Components.RequestBodies[name]
+	if !ok {
+		return nil, fmt.Errorf("request body %s not found", b.Ref)
+	}
+	return d.ResolveRequestBody(resolved)
+}
+
+// ResolveResponse follows a response reference
+func (d *Document) ResolveResponse(r *Response) (*Response, error) {
+	if r == nil || r.Ref == "" {
+		return r, nil
+	}
+	name, err := localRef(r.Ref, "responses")
+	if err != nil {
+		return nil, err
+	}
+	resolved, ok := d.Components.Responses[name]
+	if !ok {
+		return nil, fmt.Errorf("response %s not found", r.Ref)
+	}
+	return d.ResolveResponse(resolved)
+}
+
+// OperationParameters returns the resolved parameters of an operation, including those
+// declared on its path; operation parameters override path parameters of the same name
+func (d *Document) OperationParameters(item *PathItem, op *Operation) ([]*Parameter, error) {
+	var params []*Parameter
+	index := map[string]int{}
+	for _, list := range [][]*Parameter{item.Parameters, op.Parameters} {
+		for _, p := range list {
+			resolved, err := d.ResolveParameter(p)
+			if err != nil {
+				return nil, err
+			}
+			key := resolved.In + ":" + resolved.Name
+			if i, ok := index[key]; ok {
+				params[i] = resolved
+				continue
+			}
+			index[key] = len(params)
+			params = append(params, resolved)
+		}
+	}
+	return params, nil
+}
+
+// JSONContent returns the JSON media type of a content map: application/json, another
+// +json type or a wildcard, in that order
+func JSONContent(content OrderedMap[*MediaType]) (string, *MediaType) {
+	for _, contentType := range content.Keys {
+		if strings.HasPrefix(contentType, "application/json") {
+			return contentType, content.Values[contentType]
+		}
+	}
+	for _, contentType := range content.Keys {
+		if strings.Contains(contentType, "+json") || contentType == "*/*" {
+			return contentType, content.Values[contentType]
+		}
+	}
+	return "", nil
+}
+
+// SuccessResponse returns the status code and response of the first 2xx response of an
+// operation, falling back to the default response
+func (d *Document) SuccessResponse(op *Operation) (string, *Response, error) {
+	codes := append([]string(nil), op.Responses.Keys...)
+	sort.Strings(codes)
+	for _, code := range codes {
+		if strings.HasPrefix(code, "2") {
+			response, err := d.ResolveResponse(op.Responses.Values[code])
+			return code, response, err
+		}
+	}
+	if response, ok := op.Responses.Get("default"); ok {
+		resolved, err := d.ResolveResponse(response)
+		return "default", resolved, err
+	}
+	return "", nil, nil
+}
+
+// localRef returns the component name of a reference into the given section of the
+// document's components
+func localRef(ref, section string) (string, error) {
+	prefix := "#/components/" + section + "/"
+	if !strings.HasPrefix(ref, prefix) {
+		return "", fmt.Errorf("unsupported reference %s, only references to #/components/%s in the same document are supported", ref, section)
+	}
+	return strings.TrimPrefix(ref, prefix), nil
+}
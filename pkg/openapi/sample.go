@@ -0,0 +1,250 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strings"
+)
+
+// maxSampleDepth bounds the nesting of the values built from schemas
+const maxSampleDepth = 8
+
+// sampleObject is a JSON object that keeps the order of the properties of its schema
+type sampleObject struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// set adds or replaces a property
+func (o *sampleObject) set(key string, value interface{}) {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+// MarshalJSON encodes the properties in order
+func (o *sampleObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(o.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// SampleValue returns a value of a schema: its example, default or first enum constant,
+// or a value built from its type, format and bounds. Write-only properties are left out,
+// and objects keep the order of their properties when encoded as JSON.
+func (d *Document) SampleValue(schema *Schema) (interface{}, error) {
+	return (&sampler{doc: d, visiting: map[string]bool{}}).sample(schema, 0)
+}
+
+// sampler builds values from schemas
+type sampler struct {
+	doc      *Document
+	visiting map[string]bool // References being built, to stop recursive schemas
+}
+
+// sample returns a value of a schema, nested depth levels deep
+func (s *sampler) sample(schema *Schema, depth int) (interface{}, error) {
+	if schema == nil || depth > maxSampleDepth {
+		return nil, nil
+	}
+	if ref := schema.Ref; ref != "" {
+		if s.visiting[ref] {
+			return nil, nil
+		}
+		s.visiting[ref] = true
+		defer delete(s.visiting, ref)
+		resolved, err := s.doc.ResolveSchema(schema)
+		if err != nil {
+			return nil, err
+		}
+		schema = resolved
+	}
+	if schema.Boolean != nil {
+		return nil, nil
+	}
+	if example := schema.ExampleValue(); example != nil {
+		return example, nil
+	}
+	if schema.Default != nil {
+		return schema.Default, nil
+	}
+	if len(schema.Enum) > 0 {
+		return schema.Enum[0], nil
+	}
+	if len(schema.AllOf) > 0 {
+		merged := &sampleObject{values: map[string]interface{}{}}
+		for _, part := range schema.AllOf {
+			value, err := s.sample(part, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			if part, ok := value.(*sampleObject); ok {
+				for _, key := range part.keys {
+					merged.set(key, part.values[key])
+				}
+			}
+		}
+		if schema.Properties.Len() > 0 {
+			own, err := s.objectSample(schema, depth)
+			if err != nil {
+				return nil, err
+			}
+			for _, key := range own.keys {
+				merged.set(key, own.values[key])
+			}
+		}
+		return merged, nil
+	}
+	if options := append(append([]*Schema{}, schema.OneOf...), schema.AnyOf...); len(options) > 0 {
+		return s.sample(options[0], depth+1)
+	}
+
+	typeName := schema.TypeName()
+	if typeName == "" {
+		switch {
+		case schema.Properties.Len() > 0 || schema.AdditionalProperties != nil:
+			typeName = "object"
+		case schema.Items != nil:
+			typeName = "array"
+		}
+	}
+	switch typeName {
+	case "string":
+		return stringSample(schema), nil
+	case "integer":
+		return math.Ceil(numberSample(schema, 1)), nil
+	case "number":
+		return numberSample(schema, 1.5), nil
+	case "boolean":
+		return true, nil
+	case "array":
+		count := 1
+		if schema.MinItems != nil && *schema.MinItems > count {
+			count = *schema.MinItems
+		}
+		if schema.MaxItems != nil && *schema.MaxItems < count {
+			count = *schema.MaxItems
+		}
+		items := []interface{}{}
+		for i := 0; i < count; i++ {
+			item, err := s.sample(schema.Items, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			if item == nil && !schema.Items.IsNullable() {
+				break
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	case "object":
+		return s.objectSample(schema, depth)
+	}
+	return nil, nil
+}
+
+// objectSample returns an object with a value of every readable property of a schema
+func (s *sampler) objectSample(schema *Schema, depth int) (*sampleObject, error) {
+	result := &sampleObject{values: map[string]interface{}{}}
+	for _, name := range schema.Properties.Keys {
+		property := schema.Properties.Values[name]
+		resolved, err := s.doc.ResolveSchema(property)
+		if err != nil {
+			return nil, err
+		}
+		if resolved != nil && resolved.WriteOnly {
+			continue
+		}
+		value, err := s.sample(property, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		if value == nil && !schema.IsRequired(name) {
+			continue
+		}
+		result.set(name, value)
+	}
+	if additional := schema.AdditionalProperties; additional != nil && additional.Boolean == nil && schema.Properties.Len() == 0 {
+		value, err := s.sample(additional, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		result.set("key", value)
+	}
+	return result, nil
+}
+
+// stringSample returns a string of the format and length of a schema
+func stringSample(schema *Schema) string {
+	var value string
+	switch schema.Format {
+	case "date":
+		value = "2024-01-15"
+	case "date-time":
+		value = "2024-01-15T09:30:00Z"
+	case "time":
+		value = "09:30:00"
+	case "uuid":
+		value = "3fa85f64-5717-4562-b3fc-2c963f66afa6"
+	case "email":
+		value = "user@example.com"
+	case "uri", "url":
+		value = "https://example.com"
+	case "hostname":
+		value = "example.com"
+	case "ipv4":
+		value = "192.0.2.1"
+	case "ipv6":
+		value = "2001:db8::1"
+	case "byte":
+		value = "c3RyaW5n"
+	case "password":
+		value = "secret"
+	default:
+		value = "string"
+	}
+	if schema.MinLength != nil && len(value) < *schema.MinLength {
+		value += strings.Repeat("x", *schema.MinLength-len(value))
+	}
+	if schema.MaxLength != nil && len(value) > *schema.MaxLength {
+		value = value[:*schema.MaxLength]
+	}
+	return value
+}
+
+// numberSample returns a number within the bounds of a schema, preferring fallback
+func numberSample(schema *Schema, fallback float64) float64 {
+	value := fallback
+	if minimum, exclusive := schema.MinimumBound(); minimum != nil && (value < *minimum || exclusive && value <= *minimum) {
+		value = *minimum
+		if exclusive {
+			value++
+		}
+	}
+	if maximum, exclusive := schema.MaximumBound(); maximum != nil && (value > *maximum || exclusive && value >= *maximum) {
+		value = *maximum
+		if exclusive {
+			value--
+		}
+	}
+	return value
+}
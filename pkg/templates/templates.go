@@ -0,0 +1,10 @@
+package templates
+
+import "embed"
+
+// FS holds the built-in code generation templates. Projects can override any
+// of them by placing a file with the same relative path in their configured
+// templates directory.
+//
+//go:embed api client cloud contract entity errors messaging outbox security test workflow
+var FS embed.FS
@@ -0,0 +1,48 @@
+package util
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// GitShowHead returns the content of a file as of the last commit of the repository holding dir.
+// The path is relative to dir.
+func GitShowHead(dir, path string) (string, error) {
+	cmd := exec.Command("git", "show", "HEAD:./"+filepath.ToSlash(path))
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%s is not in the last commit", path)
+	}
+	return string(out), nil
+}
+
+// GitShow returns the content of a file as of a commit, branch or tag of the repository holding
+// dir, and whether the file exists there. The path is relative to dir.
+func GitShow(dir, ref, path string) (string, bool, error) {
+	verify := exec.Command("git", "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	verify.Dir = dir
+	if err := verify.Run(); err != nil {
+		return "", false, fmt.Errorf("%s is not a commit, branch or tag of the repository", ref)
+	}
+	cmd := exec.Command("git", "show", ref+":./"+filepath.ToSlash(path))
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", false, nil
+	}
+	return string(out), true, nil
+}
+
+// GitHeadCommit returns the abbreviated hash of the last commit of the repository holding dir
+func GitHeadCommit(dir string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--short", "HEAD")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%s is not a git repository with commits", dir)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
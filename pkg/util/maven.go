@@ -0,0 +1,356 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// MavenDependency identifies a Maven artifact to add to a pom.xml
+type MavenDependency struct {
+	GroupID    string
+	ArtifactID string
+	Version    string
+	Scope      string
+}
+
+// AddMavenDependency adds a dependency to the project-level <dependencies> of a pom.xml.
+// It returns false if the dependency was already present.
+func AddMavenDependency(pomPath string, dep MavenDependency) (bool, error) {
+	pom, err := os.ReadFile(pomPath)
+	if err != nil {
+		return false, err
+	}
+	content := string(pom)
+	indent := pomIndent(content)
+
+	start, end := projectDependenciesBlock(content)
+	if start < 0 {
+		// No dependencies yet, add an empty block after the project coordinates
+		closing := strings.LastIndex(content, "</project>")
+		if closing < 0 {
+			return false, fmt.Errorf("invalid pom.xml: %s", pomPath)
+		}
+		content = content[:closing] + indent + "<dependencies>\n" + indent + "</dependencies>\n" + content[closing:]
+		start, end = projectDependenciesBlock(content)
+	}
+
+	if containsArtifact(content[start:end], dep.GroupID, dep.ArtifactID) {
+		return false, nil
+	}
+
+	var b strings.Builder
+	b.WriteString(indent + indent + "<dependency>\n")
+	b.WriteString(indent + indent + indent + "<groupId>" + dep.GroupID + "</groupId>\n")
+	b.WriteString(indent + indent + indent + "<artifactId>" + dep.ArtifactID + "</artifactId>\n")
+	if dep.Version != "" {
+		b.WriteString(indent + indent + indent + "<version>" + dep.Version + "</version>\n")
+	}
+	if dep.Scope != "" {
+		b.WriteString(indent + indent + indent + "<scope>" + dep.Scope + "</scope>\n")
+	}
+	b.WriteString(indent + indent + "</dependency>\n")
+
+	// Insert before the line holding </dependencies>
+	insertAt := strings.LastIndex(content[:end], "\n") + 1
+	content = content[:insertAt] + b.String() + content[insertAt:]
+
+	return true, os.WriteFile(pomPath, []byte(content), 0644)
+}
+
+// HasMavenDependency reports whether a pom.xml declares the given project-level dependency
+func HasMavenDependency(pomPath, groupID, artifactID string) bool {
+	pom, err := os.ReadFile(pomPath)
+	if err != nil {
+		return false
+	}
+	content := string(pom)
+	start, end := projectDependenciesBlock(content)
+	if start < 0 {
+		return false
+	}
+	return containsArtifact(content[start:end], groupID, artifactID)
+}
+
+// AddAnnotationProcessorPath registers an annotation processor with the maven-compiler-plugin,
+// creating the plugin configuration if needed. When the plugin declares annotationProcessorPaths,
+// only the listed processors run, so Lombok is added first if the project depends on it.
+func AddAnnotationProcessorPath(pomPath string, processor MavenDependency) (bool, error) {
+	pom, err := os.ReadFile(pomPath)
+	if err != nil {
+		return false, err
+	}
+	content := string(pom)
+	indent := pomIndent(content)
+
+	// Processors must come after Lombok so that generated accessors are visible to them
+	processors := []MavenDependency{}
+	depStart, depEnd := projectDependenciesBlock(content)
+	if depStart >= 0 && containsArtifact(content[depStart:depEnd], "org.projectlombok", "lombok") {
+		processors = append(processors, MavenDependency{GroupID: "org.projectlombok", ArtifactID: "lombok", Version: "${lombok.version}"})
+	}
+	processors = append(processors, processor)
+
+	pathsIndent := strings.Repeat(indent, 6)
+	renderPaths := func(existing string) string {
+		var b strings.Builder
+		for _, p := range processors {
+			if containsArtifact(existing, p.GroupID, p.ArtifactID) {
+				continue
+			}
+			b.WriteString(pathsIndent + "<path>\n")
+			b.WriteString(pathsIndent + indent + "<groupId>" + p.GroupID + "</groupId>\n")
+			b.WriteString(pathsIndent + indent + "<artifactId>" + p.ArtifactID + "</artifactId>\n")
+			if p.Version != "" {
+				b.WriteString(pathsIndent + indent + "<version>" + p.Version + "</version>\n")
+			}
+			b.WriteString(pathsIndent + "</path>\n")
+		}
+		return b.String()
+	}
+
+	pluginStart, pluginEnd := compilerPluginBlock(content)
+	switch {
+	case pluginStart < 0:
+		// No compiler plugin, add one to the build plugins
+		plugins := strings.Index(content, "</plugins>")
+		if plugins < 0 {
+			return false, fmt.Errorf("no <build><plugins> section found in %s", pomPath)
+		}
+		plugin := strings.Repeat(indent, 3) + "<plugin>\n" +
+			strings.Repeat(indent, 4) + "<groupId>org.apache.maven.plugins</groupId>\n" +
+			strings.Repeat(indent, 4) + "<artifactId>maven-compiler-plugin</artifactId>\n" +
+			strings.Repeat(indent, 4) + "<configuration>\n" +
+			strings.Repeat(indent, 5) + "<annotationProcessorPaths>\n" +
+			renderPaths("") +
+			strings.Repeat(indent, 5) + "</annotationProcessorPaths>\n" +
+			strings.Repeat(indent, 4) + "</configuration>\n" +
+			strings.Repeat(indent, 3) + "</plugin>\n"
+		insertAt := strings.LastIndex(content[:plugins], "\n") + 1
+		content = content[:insertAt] + plugin + content[insertAt:]
+	default:
+		block := content[pluginStart:pluginEnd]
+		if containsArtifact(block, processor.GroupID, processor.ArtifactID) {
+			return false, nil
+		}
+		if idx := strings.Index(block, "</annotationProcessorPaths>"); idx >= 0 {
+			insertAt := pluginStart + strings.LastIndex(block[:idx], "\n") + 1
+			content = content[:insertAt] + renderPaths(block) + content[insertAt:]
+		} else {
+			paths := strings.Repeat(indent, 5) + "<annotationProcessorPaths>\n" +
+				renderPaths(block) +
+				strings.Repeat(indent, 5) + "</annotationProcessorPaths>\n"
+			if idx := strings.Index(block, "<configuration>"); idx >= 0 {
+				insertAt := pluginStart + idx + len("<configuration>\n")
+				content = content[:insertAt] + paths + content[insertAt:]
+			} else {
+				idx := strings.Index(block, "</plugin>")
+				insertAt := pluginStart + strings.LastIndex(block[:idx], "\n") + 1
+				content = content[:insertAt] + strings.Repeat(indent, 4) + "<configuration>\n" + paths +
+					strings.Repeat(indent, 4) + "</configuration>\n" + content[insertAt:]
+			}
+		}
+	}
+
+	return true, os.WriteFile(pomPath, []byte(content), 0644)
+}
+
+// AddMavenPlugin adds a build plugin to a pom.xml unless it is already declared. The
+// body holds the XML after the plugin coordinates, indented with one tab per level
+// below <plugin>; tabs are replaced by the indentation of the pom.
+func AddMavenPlugin(pomPath string, plugin MavenDependency, body string) (bool, error) {
+	pom, err := os.ReadFile(pomPath)
+	if err != nil {
+		return false, err
+	}
+	content := string(pom)
+	indent := pomIndent(content)
+
+	buildStart := strings.Index(content, "<build>")
+	if buildStart >= 0 && containsArtifact(content[buildStart:], plugin.GroupID, plugin.ArtifactID) {
+		return false, nil
+	}
+
+	pluginIndent := strings.Repeat(indent, 3)
+	var b strings.Builder
+	b.WriteString(pluginIndent + "<plugin>\n")
+	b.WriteString(pluginIndent + indent + "<groupId>" + plugin.GroupID + "</groupId>\n")
+	b.WriteString(pluginIndent + indent + "<artifactId>" + plugin.ArtifactID + "</artifactId>\n")
+	if plugin.Version != "" {
+		b.WriteString(pluginIndent + indent + "<version>" + plugin.Version + "</version>\n")
+	}
+	for _, line := range strings.Split(strings.TrimRight(body, "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+		depth := len(line) - len(strings.TrimLeft(line, "\t"))
+		b.WriteString(pluginIndent + strings.Repeat(indent, depth+1) + strings.TrimLeft(line, "\t") + "\n")
+	}
+	b.WriteString(pluginIndent + "</plugin>\n")
+
+	plugins := strings.LastIndex(content, "</plugins>")
+	if plugins < 0 {
+		return false, fmt.Errorf("no <build><plugins> section found in %s", pomPath)
+	}
+	insertAt := strings.LastIndex(content[:plugins], "\n") + 1
+	content = content[:insertAt] + b.String() + content[insertAt:]
+
+	return true, os.WriteFile(pomPath, []byte(content), 0644)
+}
+
+// AddMavenRepository adds a repository to a pom.xml unless one with the same URL is declared
+func AddMavenRepository(pomPath, id, url string) (bool, error) {
+	pom, err := os.ReadFile(pomPath)
+	if err != nil {
+		return false, err
+	}
+	content := string(pom)
+	if strings.Contains(content, "<url>"+url+"</url>") {
+		return false, nil
+	}
+	indent := pomIndent(content)
+
+	repository := strings.Repeat(indent, 2) + "<repository>\n" +
+		strings.Repeat(indent, 3) + "<id>" + id + "</id>\n" +
+		strings.Repeat(indent, 3) + "<url>" + url + "</url>\n" +
+		strings.Repeat(indent, 2) + "</repository>\n"
+	if idx := strings.Index(content, "</repositories>"); idx >= 0 {
+		insertAt := strings.LastIndex(content[:idx], "\n") + 1
+		content = content[:insertAt] + repository + content[insertAt:]
+	} else {
+		// Repositories go after the dependencies, before the build section
+		idx := strings.Index(content, "<build>")
+		if idx < 0 {
+			idx = strings.LastIndex(content, "</project>")
+		}
+		if idx < 0 {
+			return false, fmt.Errorf("invalid pom.xml: %s", pomPath)
+		}
+		insertAt := strings.LastIndex(content[:idx], "\n") + 1
+		content = content[:insertAt] + indent + "<repositories>\n" + repository + indent + "</repositories>\n\n" + content[insertAt:]
+	}
+
+	return true, os.WriteFile(pomPath, []byte(content), 0644)
+}
+
+// MavenProject holds the coordinates and description of a pom.xml
+type MavenProject struct {
+	GroupID     string
+	ArtifactID  string
+	Version     string
+	Name        string
+	Description string
+}
+
+// ReadMavenProject reads the project-level coordinates of a pom.xml, falling back to the
+// parent's groupId and version when the project does not declare them
+func ReadMavenProject(pomPath string) (MavenProject, error) {
+	pom, err := os.ReadFile(pomPath)
+	if err != nil {
+		return MavenProject{}, err
+	}
+	content := string(pom)
+
+	var parent string
+	if s := strings.Index(content, "<parent>"); s >= 0 {
+		if e := strings.Index(content[s:], "</parent>"); e >= 0 {
+			parent = content[s : s+e]
+			content = content[:s] + content[s+e+len("</parent>"):]
+		}
+	}
+	// Only elements before the first nested section belong to the project itself
+	for _, tag := range []string{"<licenses>", "<developers>", "<scm>", "<properties>", "<dependencies>", "<dependencyManagement>", "<build>"} {
+		if idx := strings.Index(content, tag); idx >= 0 {
+			content = content[:idx]
+		}
+	}
+
+	element := func(source, tag string) string {
+		match := regexp.MustCompile(`<` + tag + `>\s*([^<]*?)\s*</` + tag + `>`).FindStringSubmatch(source)
+		if match == nil {
+			return ""
+		}
+		return match[1]
+	}
+	project := MavenProject{
+		GroupID:     element(content, "groupId"),
+		ArtifactID:  element(content, "artifactId"),
+		Version:     element(content, "version"),
+		Name:        element(content, "name"),
+		Description: element(content, "description"),
+	}
+	if project.GroupID == "" {
+		project.GroupID = element(parent, "groupId")
+	}
+	if project.Version == "" {
+		project.Version = element(parent, "version")
+	}
+	return project, nil
+}
+
+// pomIndent returns the indentation unit used by a pom.xml (Spring Initializr uses tabs)
+func pomIndent(content string) string {
+	match := regexp.MustCompile(`\n([ \t]+)<modelVersion>`).FindStringSubmatch(content)
+	if match == nil {
+		return "\t"
+	}
+	return match[1]
+}
+
+// projectDependenciesBlock returns the bounds of the project-level <dependencies> element,
+// skipping those nested in dependencyManagement, build or profiles
+func projectDependenciesBlock(content string) (int, int) {
+	var skip [][2]int
+	for _, tag := range []string{"dependencyManagement", "build", "profiles"} {
+		if s := strings.Index(content, "<"+tag+">"); s >= 0 {
+			if e := strings.Index(content[s:], "</"+tag+">"); e >= 0 {
+				skip = append(skip, [2]int{s, s + e})
+			}
+		}
+	}
+
+	offset := 0
+	for {
+		idx := strings.Index(content[offset:], "<dependencies>")
+		if idx < 0 {
+			return -1, -1
+		}
+		start := offset + idx
+		inside := false
+		for _, r := range skip {
+			if start > r[0] && start < r[1] {
+				inside = true
+				offset = r[1]
+				break
+			}
+		}
+		if !inside {
+			end := strings.Index(content[start:], "</dependencies>")
+			if end < 0 {
+				return -1, -1
+			}
+			return start, start + end
+		}
+	}
+}
+
+// compilerPluginBlock returns the bounds of the maven-compiler-plugin <plugin> element
+func compilerPluginBlock(content string) (int, int) {
+	idx := strings.Index(content, "<artifactId>maven-compiler-plugin</artifactId>")
+	if idx < 0 {
+		return -1, -1
+	}
+	start := strings.LastIndex(content[:idx], "<plugin>")
+	end := strings.Index(content[idx:], "</plugin>")
+	if start < 0 || end < 0 {
+		return -1, -1
+	}
+	return start, idx + end + len("</plugin>")
+}
+
+// containsArtifact reports whether an XML fragment references the given groupId/artifactId pair
+func containsArtifact(fragment, groupID, artifactID string) bool {
+	pattern := `<groupId>\s*` + regexp.QuoteMeta(groupID) + `\s*</groupId>\s*<artifactId>\s*` + regexp.QuoteMeta(artifactID) + `\s*</artifactId>`
+	return regexp.MustCompile(pattern).MatchString(fragment)
+}
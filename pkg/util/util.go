@@ -213,3 +213,53 @@ func ParseRelationships(relations string) ([]map[string]string, error) {
 
 	return result, nil
 }
+
+// ParseAccessRules parses an access matrix mapping operations to the roles allowed to
+// perform them. Format: "operation:ROLE[|ROLE...]" separated by commas, where the
+// operation is read, create, update, delete or write, which stands for the last three
+// unless they have a rule of their own
+func ParseAccessRules(access string) (map[string][]string, error) {
+	result := map[string][]string{}
+	if strings.TrimSpace(access) == "" {
+		return result, nil
+	}
+
+	rolePattern := regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+	var write []string
+	for _, rule := range strings.Split(access, ",") {
+		parts := strings.SplitN(rule, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid access rule format: %s, expected operation:ROLE[|ROLE...]", strings.TrimSpace(rule))
+		}
+
+		operation := strings.TrimSpace(parts[0])
+		var roles []string
+		for _, role := range strings.Split(parts[1], "|") {
+			// hasRole adds the ROLE_ prefix itself
+			role = strings.TrimPrefix(strings.TrimSpace(role), "ROLE_")
+			if !rolePattern.MatchString(role) {
+				return nil, fmt.Errorf("invalid role %q in access rule %s", role, strings.TrimSpace(rule))
+			}
+			roles = append(roles, role)
+		}
+
+		switch operation {
+		case "read", "create", "update", "delete":
+			result[operation] = roles
+		case "write":
+			write = roles
+		default:
+			return nil, fmt.Errorf("invalid access operation: %s, expected read, write, create, update or delete", operation)
+		}
+	}
+
+	if write != nil {
+		for _, operation := range []string{"create", "update", "delete"} {
+			if _, ok := result[operation]; !ok {
+				result[operation] = write
+			}
+		}
+	}
+
+	return result, nil
+}
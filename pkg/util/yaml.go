@@ -0,0 +1,302 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MergeYAML merges a YAML fragment into a YAML file, creating the file if needed.
+// Keys already in the file keep their values, missing keys are added, and sequence
+// items are appended unless an equal item is already present.
+func MergeYAML(path, fragment string) error {
+	var patch yaml.Node
+	if err := yaml.Unmarshal([]byte(fragment), &patch); err != nil {
+		return fmt.Errorf("invalid YAML fragment: %v", err)
+	}
+
+	doc := patch
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(bytes.TrimSpace(content)) > 0 {
+		var existing yaml.Node
+		if err := yaml.Unmarshal(content, &existing); err != nil {
+			return fmt.Errorf("invalid YAML in %s: %v", path, err)
+		}
+		mergeYAMLNodes(existing.Content[0], patch.Content[0])
+		doc = existing
+	}
+
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return err
+	}
+	merged := out.String()
+	if bytes.Contains(content, []byte("\n\n")) {
+		merged = separateTopLevelKeys(merged)
+	}
+	return WriteFile(path, merged)
+}
+
+// separateTopLevelKeys puts a blank line before each top-level key and its comments,
+// which the encoder drops
+func separateTopLevelKeys(source string) string {
+	lines := strings.Split(source, "\n")
+	var b strings.Builder
+	for i, line := range lines {
+		topLevel := line != "" && line[0] != ' ' && line[0] != '-'
+		if i > 0 && topLevel && !strings.HasPrefix(lines[i-1], "#") && lines[i-1] != "" {
+			b.WriteString("\n")
+		}
+		b.WriteString(line)
+		if i < len(lines)-1 {
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// MergeProperties adds the keys of a YAML fragment, flattened to dotted names, to a
+// .properties file. Keys already in the file keep their values.
+func MergeProperties(path, fragment string) error {
+	var patch yaml.Node
+	if err := yaml.Unmarshal([]byte(fragment), &patch); err != nil {
+		return fmt.Errorf("invalid YAML fragment: %v", err)
+	}
+	var properties [][2]string
+	flattenYAMLNode("", patch.Content[0], &properties)
+
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	existing := map[string]bool{}
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+			continue
+		}
+		if end := strings.IndexAny(line, "=:"); end > 0 {
+			existing[strings.TrimSpace(line[:end])] = true
+		}
+	}
+
+	var added []string
+	for _, property := range properties {
+		if !existing[property[0]] {
+			added = append(added, property[0]+"="+property[1])
+		}
+	}
+	if len(added) == 0 {
+		return nil
+	}
+
+	var b strings.Builder
+	b.Write(content)
+	if len(content) > 0 && !bytes.HasSuffix(content, []byte("\n")) {
+		b.WriteString("\n")
+	}
+	for _, line := range added {
+		b.WriteString(line + "\n")
+	}
+	return WriteFile(path, b.String())
+}
+
+// mergeYAMLNodes merges src into dst in place
+func mergeYAMLNodes(dst, src *yaml.Node) {
+	switch {
+	case dst.Kind == yaml.MappingNode && src.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(src.Content); i += 2 {
+			key, value := src.Content[i], src.Content[i+1]
+			found := false
+			for j := 0; j+1 < len(dst.Content); j += 2 {
+				if dst.Content[j].Value == key.Value {
+					mergeYAMLNodes(dst.Content[j+1], value)
+					found = true
+					break
+				}
+			}
+			if !found {
+				dst.Content = append(dst.Content, key, value)
+			}
+		}
+	case dst.Kind == yaml.SequenceNode && src.Kind == yaml.SequenceNode:
+		for _, item := range src.Content {
+			found := false
+			for _, existing := range dst.Content {
+				if yamlNodesEqual(existing, item) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				dst.Content = append(dst.Content, item)
+			}
+		}
+	}
+}
+
+// yamlNodesEqual compares two nodes by their content, ignoring style and comments
+func yamlNodesEqual(a, b *yaml.Node) bool {
+	var va, vb interface{}
+	if a.Decode(&va) != nil || b.Decode(&vb) != nil {
+		return false
+	}
+	return fmt.Sprint(va) == fmt.Sprint(vb)
+}
+
+// flattenYAMLNode collects the scalar values of a node under dotted property names, in order
+func flattenYAMLNode(prefix string, node *yaml.Node, properties *[][2]string) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			flattenYAMLNode(key, node.Content[i+1], properties)
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			flattenYAMLNode(fmt.Sprintf("%s[%d]", prefix, i), item, properties)
+		}
+	case yaml.ScalarNode:
+		*properties = append(*properties, [2]string{prefix, node.Value})
+	}
+}
+
+// yamlTimestampPattern matches the strings YAML 1.1 resolves to timestamps
+var yamlTimestampPattern = regexp.MustCompile(`^\d{4}-\d{1,2}-\d{1,2}([Tt ]|$)`)
+
+// YAMLMap is a YAML mapping that keeps its keys in the order they were set, unlike
+// Go maps, which the encoder sorts
+type YAMLMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// NewYAMLMap creates an empty YAMLMap
+func NewYAMLMap() *YAMLMap {
+	return &YAMLMap{values: map[string]interface{}{}}
+}
+
+// Set sets the value of a key, keeping the position of existing keys
+func (m *YAMLMap) Set(key string, value interface{}) *YAMLMap {
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+	return m
+}
+
+// Get returns the value of a key
+func (m *YAMLMap) Get(key string) (interface{}, bool) {
+	value, ok := m.values[key]
+	return value, ok
+}
+
+// Len returns the number of keys
+func (m *YAMLMap) Len() int {
+	return len(m.keys)
+}
+
+// Keys returns the keys in the order they were set
+func (m *YAMLMap) Keys() []string {
+	return m.keys
+}
+
+// MarshalYAML encodes the mapping with its keys in order
+func (m *YAMLMap) MarshalYAML() (interface{}, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for _, key := range m.keys {
+		var value yaml.Node
+		if err := value.Encode(m.values[key]); err != nil {
+			return nil, err
+		}
+		// YAML 1.1 parsers, such as SnakeYAML, read unquoted date-times as timestamps
+		if value.Kind == yaml.ScalarNode && value.Tag == "!!str" && yamlTimestampPattern.MatchString(value.Value) {
+			value.Style = yaml.DoubleQuotedStyle
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, &value)
+	}
+	return node, nil
+}
+
+// MarshalJSON encodes the mapping as a JSON object with its keys in order
+func (m *YAMLMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(m.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// EncodeYAML encodes a value as YAML indented by two spaces
+func EncodeYAML(value interface{}) (string, error) {
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(value); err != nil {
+		return "", err
+	}
+	return out.String(), encoder.Close()
+}
+
+// ReadProperties reads a .properties, .yml or .yaml file into dotted property names
+func ReadProperties(path string) (map[string]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	properties := map[string]string{}
+	if strings.HasSuffix(path, ".properties") {
+		for _, line := range strings.Split(string(content), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+				continue
+			}
+			if end := strings.IndexAny(line, "=:"); end > 0 {
+				properties[strings.TrimSpace(line[:end])] = strings.TrimSpace(line[end+1:])
+			}
+		}
+		return properties, nil
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("invalid YAML in %s: %v", path, err)
+	}
+	if len(doc.Content) == 0 {
+		return properties, nil
+	}
+	var flattened [][2]string
+	flattenYAMLNode("", doc.Content[0], &flattened)
+	for _, property := range flattened {
+		properties[property[0]] = property[1]
+	}
+	return properties, nil
+}